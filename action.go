@@ -0,0 +1,255 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+	"github.com/quaywin/claude-commit/internal/github"
+)
+
+// actionMarker identifies cc's summary comment, so reruns update it
+// instead of adding another
+const actionMarker = "<!-- claude-commit review -->"
+
+// actionStatusContext names the commit status cc action sets
+const actionStatusContext = "claude-commit/review"
+
+// actionOptions are the flags of cc action
+type actionOptions struct {
+	PR   int    // Pull request number (default from the event)
+	Base string // Base commit (default from the event)
+	Head string // Head commit (default from the event, else HEAD)
+	Post string // comment, review, or none
+}
+
+// pullRequestEvent is the part of a GitHub Actions pull_request event we use
+type pullRequestEvent struct {
+	PullRequest struct {
+		Number int `json:"number"`
+		Base   struct {
+			SHA string `json:"sha"`
+		} `json:"base"`
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	} `json:"pull_request"`
+}
+
+// handleAction reviews a pull request in CI: the diff of base...head goes
+// to Claude, the findings are posted to the pull request, and a commit
+// status fails (as does cc, with exit code 3) when a finding is at or above
+// block_severity
+func handleAction(cfg *config.Config, opts actionOptions) error {
+	threshold, err := claude.ParseSeverity(cfg.BlockSeverity)
+	if err != nil {
+		return fmt.Errorf("invalid block_severity in config: %w", err)
+	}
+	if err := readActionEvent(&opts); err != nil {
+		return err
+	}
+	if opts.Base == "" {
+		return fmt.Errorf("no base commit: run on a pull_request event or pass --base")
+	}
+	head := git.ResolveCommit(runCtx, cmp.Or(opts.Head, "HEAD"))
+	if head == "" {
+		return withExitCode(exitGitError, fmt.Errorf("unknown head commit %q", opts.Head))
+	}
+	if git.ResolveCommit(runCtx, opts.Base) == "" {
+		return withExitCode(exitGitError, fmt.Errorf("base commit %s is not in this clone; check out with fetch-depth: 0", shortSHA(opts.Base)))
+	}
+	base, err := git.MergeBase(runCtx, opts.Base, head)
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("finding the merge base of %s and %s: %w", shortSHA(opts.Base), shortSHA(head), err))
+	}
+
+	files, err := git.GetRangeChangedFiles(runCtx, base, head)
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("getting changed files: %w", err))
+	}
+	if len(files) == 0 {
+		summaryf("✅ The pull request changes no files.\n")
+		exit(exitNoChanges)
+	}
+	diff, mode, err := git.ChooseDiff(len(files), func(summary bool) (string, error) {
+		return git.GetRangeDiff(runCtx, base, head, summary)
+	})
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("getting diff: %w", err))
+	}
+
+	logf("🔍 Reviewing %s...%s (%d files)\n", shortSHA(base), shortSHA(head), len(files))
+	review, err := claude.ReviewChanges(runCtx, diff, modelFor(cfg, reviewTask()), mode == git.SummaryDiff)
+	if err != nil {
+		return withExitCode(exitModelErr, fmt.Errorf("calling Claude: %w", err))
+	}
+	review.Findings = append(review.Findings, testPolicyFindings(cfg, files)...)
+	blocking := claude.Blocking(review.Findings, threshold)
+	printReview(review)
+
+	if opts.Post != "none" {
+		if err := postActionReview(cfg, opts, head, actionComment(review, blocking, threshold), len(blocking) > 0); err != nil {
+			return err
+		}
+	}
+	if len(blocking) > 0 {
+		printf("\n🛑 %d finding(s) at or above %s severity.\n", len(blocking), threshold)
+		exit(exitBlocked)
+	}
+	return nil
+}
+
+// readActionEvent fills in the pull request, base, and head the flags left
+// out from the event GitHub Actions describes in $GITHUB_EVENT_PATH
+func readActionEvent(opts *actionOptions) error {
+	path := os.Getenv("GITHUB_EVENT_PATH")
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading the GitHub event: %w", err)
+	}
+	var event pullRequestEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return fmt.Errorf("reading the GitHub event: %w", err)
+	}
+	if opts.PR == 0 {
+		opts.PR = event.PullRequest.Number
+	}
+	if opts.Base == "" {
+		opts.Base = event.PullRequest.Base.SHA
+	}
+	if opts.Head == "" {
+		opts.Head = event.PullRequest.Head.SHA
+	}
+	return nil
+}
+
+// actionComment formats a review as the Markdown of a pull request comment
+func actionComment(review *claude.Review, blocking []claude.Finding, threshold claude.Severity) string {
+	riskIcon := map[string]string{"low": "🟢", "medium": "🟡", "high": "🔴"}[review.Risk]
+	if riskIcon == "" {
+		riskIcon = "⚪"
+	}
+	var b strings.Builder
+	b.WriteString(actionMarker + "\n")
+	fmt.Fprintf(&b, "### %s Claude review: %s risk\n\n", riskIcon, review.Risk)
+	if review.Summary != "" {
+		b.WriteString(review.Summary + "\n\n")
+	}
+	if len(review.Findings) == 0 {
+		b.WriteString("✅ No issues found.\n")
+	} else {
+		fmt.Fprintf(&b, "**Findings (%d)**\n\n", len(review.Findings))
+		for _, f := range review.Findings {
+			icon := map[claude.Severity]string{claude.SeverityCritical: "🔴", claude.SeverityWarning: "🟡"}[f.Severity]
+			if icon == "" {
+				icon = "🔵"
+			}
+			fmt.Fprintf(&b, "- %s **%s**: %s\n", icon, f.Severity, f.Description)
+			if f.Checklist != "" {
+				fmt.Fprintf(&b, "  - Checklist: %s\n", f.Checklist)
+			}
+		}
+	}
+	if len(review.Suggestions) > 0 {
+		fmt.Fprintf(&b, "\n<details><summary>Suggestions (%d)</summary>\n\n", len(review.Suggestions))
+		for _, suggestion := range review.Suggestions {
+			fmt.Fprintf(&b, "- %s\n", suggestion)
+		}
+		b.WriteString("\n</details>\n")
+	}
+	if len(blocking) > 0 {
+		fmt.Fprintf(&b, "\n🛑 %d finding(s) at or above %s severity block this pull request.\n", len(blocking), threshold)
+	}
+	fmt.Fprintf(&b, "\n<sub>claude-commit %s</sub>\n", VERSION)
+	return b.String()
+}
+
+// postActionReview posts the review comment to the pull request (or as a
+// pull request review with --post review) and sets the commit status of head
+func postActionReview(cfg *config.Config, opts actionOptions, head, body string, blocked bool) error {
+	owner, repo, err := actionRepository()
+	if err != nil {
+		return err
+	}
+	token, err := github.ResolveToken(cfg.PullRequest.GitHubToken)
+	if err != nil {
+		return err
+	}
+	client := github.NewClient(token, "cc-cli/"+VERSION)
+
+	if opts.PR == 0 {
+		logf("ℹ️  No pull request number (not a pull_request event and no --pr); only setting the commit status.\n")
+	} else if opts.Post == "review" {
+		event := "COMMENT"
+		if blocked {
+			event = "REQUEST_CHANGES"
+		}
+		url, err := client.CreateReview(owner, repo, opts.PR, head, body, event)
+		if err != nil {
+			return fmt.Errorf("posting the review: %w", err)
+		}
+		logf("💬 Posted the review: %s\n", url)
+	} else {
+		url, err := upsertActionComment(client, owner, repo, opts.PR, body)
+		if err != nil {
+			return fmt.Errorf("posting the review comment: %w", err)
+		}
+		logf("💬 Posted the review comment: %s\n", url)
+	}
+
+	state, description := "success", "No blocking findings"
+	if blocked {
+		state, description = "failure", "Blocking findings; see the review comment"
+	}
+	if err := client.CreateStatus(owner, repo, head, state, actionStatusContext, description); err != nil {
+		// The job's exit code still fails the check
+		logf("⚠️  Could not set the commit status (the token needs statuses: write): %v\n", err)
+	}
+	return nil
+}
+
+// upsertActionComment updates cc's earlier summary comment on the pull
+// request, or adds one, and returns its URL
+func upsertActionComment(client *github.Client, owner, repo string, number int, body string) (string, error) {
+	comments, err := client.ListComments(owner, repo, number)
+	if err != nil {
+		return "", err
+	}
+	for _, comment := range comments {
+		if strings.Contains(comment.Body, actionMarker) {
+			updated, err := client.UpdateComment(owner, repo, comment.ID, body)
+			if err != nil {
+				return "", err
+			}
+			return updated.HTMLURL, nil
+		}
+	}
+	created, err := client.CreateComment(owner, repo, number, body)
+	if err != nil {
+		return "", err
+	}
+	return created.HTMLURL, nil
+}
+
+// actionRepository returns the owner and name of the repository, from
+// $GITHUB_REPOSITORY or else the origin remote
+func actionRepository() (owner, repo string, err error) {
+	if full := os.Getenv("GITHUB_REPOSITORY"); full != "" {
+		if owner, repo, ok := strings.Cut(full, "/"); ok {
+			return owner, repo, nil
+		}
+	}
+	remoteURL, err := git.GetRemoteURL(runCtx, "origin")
+	if err != nil {
+		return "", "", fmt.Errorf("getting origin remote: %w", err)
+	}
+	return github.ParseRemote(remoteURL)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// handleBranch asks Claude for a branch name, creates the branch, and switches to it
+// Usage: cc branch ["short task description"]
+// Without a description, the name is derived from the uncommitted changes.
+func handleBranch(args []string, cfg *config.Config) {
+	description := strings.TrimSpace(strings.Join(args, " "))
+
+	var diff string
+	if description == "" {
+		changes, err := collectChanges()
+		if err != nil {
+			printf("❌ Error: %v\n", err)
+			exit(exitGitError)
+		}
+		if len(changes.Files) == 0 {
+			printf("❌ No description given and no uncommitted changes to derive a branch name from.\n")
+			printf("Usage: cc branch \"short task description\"\n")
+			exit(exitNoChanges)
+		}
+		diff = changes.Diff
+	}
+
+	stopSpinner := startSpinner("🤖 Claude is naming your branch", "")
+	branchType, name, err := claude.GenerateBranchName(runCtx, description, diff, modelFor(cfg, taskMessage))
+	stopSpinner()
+
+	if err != nil {
+		printf("❌ Error calling Claude: %v\n", err)
+		exit(exitModelErr)
+	}
+
+	branch := formatBranchName(cfg.BranchPattern, branchType, name)
+	printf("🌿 Branch name: %s\n", branch)
+
+	if err := git.CreateBranch(runCtx, branch); err != nil {
+		printf("❌ Error creating branch: %v\n", err)
+		exit(exitGitError)
+	}
+
+	printf("✅ Switched to new branch %s\n", branch)
+}
+
+// formatBranchName fills the {type} and {name} placeholders of pattern
+func formatBranchName(pattern string, branchType string, name string) string {
+	if pattern == "" {
+		pattern = config.DefaultBranchPattern
+	}
+	branch := strings.ReplaceAll(pattern, "{type}", branchType)
+	return strings.ReplaceAll(branch, "{name}", name)
+}
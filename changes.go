@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+	"github.com/quaywin/claude-commit/pkg/gitops"
+)
+
+// workRepo is the repository in the current directory
+var workRepo = &gitops.Repo{}
+
+// changeSet is the state of the working tree that gets sent to Claude
+type changeSet struct {
+	*gitops.Changes
+}
+
+// collectChanges gathers the changed files and their diff, switching to a
+// stat summary for large changesets. Files is empty when there is nothing to commit.
+func collectChanges() (*changeSet, error) {
+	changes, err := workRepo.Changes(runCtx)
+	if err != nil {
+		return nil, err
+	}
+	c := &changeSet{changes}
+	c.logOmitted()
+	return c, nil
+}
+
+// collectStagedChanges is like collectChanges but only looks at the index,
+// for hooks that run inside `git commit`
+func collectStagedChanges() (*changeSet, error) {
+	changes, err := workRepo.StagedChanges(runCtx)
+	if err != nil {
+		return nil, err
+	}
+	c := &changeSet{changes}
+	c.logOmitted()
+	return c, nil
+}
+
+// useSummary replaces a full diff with the stat summary, e.g. when the full
+// diff would cost too much to review
+func (c *changeSet) useSummary() error {
+	return c.Summarize(runCtx)
+}
+
+// logOmitted tells which files are reviewed by name only
+func (c *changeSet) logOmitted() {
+	if len(c.LFSFiles) > 0 {
+		logf("📦 Leaving the content of %d Git LFS file(s) out of the review: %s\n", len(c.LFSFiles), strings.Join(c.LFSFiles, ", "))
+	}
+	if len(c.BinaryFiles) > 0 {
+		logf("🗂️  %d binary file(s) changed: %s\n", len(c.BinaryFiles), strings.Join(c.BinaryFiles, ", "))
+	}
+	if len(c.CollapsedFiles) > 0 {
+		logf("🔒 Summarizing %d lockfile(s) and generated file(s): %s\n", len(c.CollapsedFiles), strings.Join(c.CollapsedFiles, ", "))
+	}
+}
+
+// collapsePatterns applies the collapse setting to git.DefaultCollapse
+func collapsePatterns(cfg *config.Config) ([]string, error) {
+	patterns := slices.Clone(git.DefaultCollapse)
+	for _, entry := range cfg.Collapse {
+		pattern, remove := strings.CutPrefix(strings.TrimSpace(entry), "!")
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return nil, fmt.Errorf("collapse: invalid pattern %q", entry)
+		}
+		switch {
+		case remove && pattern == "*":
+			patterns = nil
+		case remove:
+			patterns = slices.DeleteFunc(patterns, func(p string) bool { return p == pattern })
+		case !slices.Contains(patterns, pattern):
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns, nil
+}
+
+// mode names how much of the diff Claude gets: "full", "hybrid", or "summary"
+func (c *changeSet) mode() string {
+	switch {
+	case c.UseSummaryMode:
+		return "summary"
+	case c.Hybrid:
+		return "hybrid"
+	}
+	return "full"
+}
+
+// spinnerDetail describes the changeset for the progress spinner, e.g. " (12 files, summary mode)"
+func (c *changeSet) spinnerDetail() string {
+	modeText := ""
+	if mode := c.mode(); mode != "full" {
+		modeText = ", " + mode + " mode"
+	}
+	return fmt.Sprintf(" (%d files%s)", len(c.Files), modeText)
+}
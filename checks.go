@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// checkOutcome is the result of a check, as listed in the --json report
+type checkOutcome struct {
+	Name    string  `json:"name"`
+	Passed  bool    `json:"passed"`
+	Seconds float64 `json:"seconds"`
+}
+
+// runChecks runs the checks in order from the repository root, streaming
+// their output, and stops at the first that fails
+func runChecks(checks []config.CheckConfig) ([]checkOutcome, error) {
+	root, err := git.GetRepoRoot(runCtx)
+	if err != nil {
+		return nil, err
+	}
+	logf("\n🧪 Running %d check(s)...\n", len(checks))
+	var results []checkOutcome
+	for _, check := range checks {
+		if err := checkInterrupted(); err != nil {
+			return results, err
+		}
+		logf("\n▶️  %s: %s\n", check.Name, check.Run)
+		start := time.Now()
+		cmd := shellCommand(check.Run)
+		cmd.Dir = root
+		cmd.Stdout = commandOutput()
+		cmd.Stderr = os.Stderr
+		err := cmd.Run()
+		seconds := time.Since(start).Seconds()
+		results = append(results, checkOutcome{Name: check.Name, Passed: err == nil, Seconds: math.Round(seconds*10) / 10})
+		if err != nil {
+			if runCtx.Err() != nil {
+				return results, errInterrupted
+			}
+			logf("❌ %s failed (%.1fs)\n", check.Name, seconds)
+			return results, fmt.Errorf("check %q failed: %w", check.Name, err)
+		}
+		logf("✅ %s passed (%.1fs)\n", check.Name, seconds)
+	}
+	return results, nil
+}
+
+// validateChecks checks that every check has a unique name and a command
+func validateChecks(checks []config.CheckConfig) error {
+	seen := map[string]bool{}
+	for i, check := range checks {
+		if check.Name == "" || check.Run == "" {
+			return fmt.Errorf("checks[%d]: needs a name and a run command", i)
+		}
+		if seen[check.Name] {
+			return fmt.Errorf("checks: %q is listed twice", check.Name)
+		}
+		seen[check.Name] = true
+	}
+	return nil
+}
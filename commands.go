@@ -0,0 +1,979 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/cli"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/debuglog"
+	"github.com/quaywin/claude-commit/internal/git"
+	"github.com/quaywin/claude-commit/internal/i18n"
+	"github.com/quaywin/claude-commit/internal/network"
+)
+
+// setupCommands run the first-run setup wizard when there is no config yet.
+// Hooks, linting, and demo mode never prompt.
+var setupCommands = map[string]bool{
+	"commit":  true,
+	"plan":    true,
+	"review":  true,
+	"pr-desc": true,
+	"branch":  true,
+	"tui":     true,
+}
+
+// newApp wires up the cc subcommands
+func newApp(cfg *config.Config) *cli.App {
+	verbose := false
+	noEmoji := false
+	profile := ""
+	// Timeout flags are applied after the repository config so they win over it
+	timeoutFlags := map[string]string{}
+
+	app := &cli.App{
+		Name:        "cc",
+		Description: "review, commit, and push your changes with Claude",
+		Default:     "commit",
+		GlobalFlags: func(fs *flag.FlagSet) {
+			fs.StringVar(&profile, "profile", "", "use the named profile from the config (default: $CC_PROFILE)")
+			fs.BoolVar(&verbose, "verbose", false, "log git commands, prompt sizes, and raw model output to stderr")
+			fs.BoolVar(&output.Quiet, "quiet", false, "only print findings, the commit message, and the final result")
+			fs.BoolVar(&output.Quiet, "q", false, "shorthand for --quiet")
+			fs.BoolVar(&output.Yes, "yes", false, "answer confirmation prompts with yes")
+			fs.BoolVar(&output.Yes, "y", false, "shorthand for --yes")
+			fs.BoolFunc("ci", "non-interactive mode: --quiet --yes without spinner or ANSI codes", func(string) error {
+				output.Quiet = true
+				output.Yes = true
+				output.NoANSI = true
+				return nil
+			})
+			fs.BoolVar(&noEmoji, "no-emoji", false, "plain output: no emoji, spinner, or ANSI codes (default when NO_COLOR is set or stdout isn't a terminal)")
+			for _, stage := range []string{"diff", "provider", "commit", "push"} {
+				stage := stage
+				fs.Func("timeout-"+stage, "timeout for the "+stage+" stage (e.g. 30s, 2m; 0 disables)", func(value string) error {
+					timeoutFlags[stage] = value
+					return nil
+				})
+			}
+		},
+		Before: func(cmd *cli.Command) error {
+			if demoMode {
+				enableDemoMode()
+			}
+			startTelemetry(cfg, cmd.Name)
+			if noEmoji || os.Getenv("NO_COLOR") != "" || !term.IsTerminal(int(os.Stdout.Fd())) {
+				output.Plain = true
+				output.NoANSI = true
+			}
+			if verbose && !debuglog.Enabled() {
+				debuglog.Enable(os.Stderr)
+			}
+			// First run: ask for the main settings instead of silently using defaults
+			if setupCommands[cmd.Name] && needsSetup() && !demoMode {
+				if err := handleSetup(cfg); err != nil {
+					return err
+				}
+			}
+			if err := applyProfile(cfg, profile, cmd.NeedsRepo); err != nil {
+				return err
+			}
+			trustingRepo = cmd.Name == "trust"
+			if cmd.NeedsRepo {
+				if err := ensureRepo(cfg); err != nil {
+					return err
+				}
+				if err := applyRepoConfig(cfg); err != nil {
+					return err
+				}
+				if err := git.SetBackend(cfg.GitBackend); err != nil {
+					return err
+				}
+			}
+			if err := i18n.SetLanguage(cfg.UILanguage); err != nil {
+				return err
+			}
+			if err := network.Configure(cfg.Network.Proxy, cfg.Network.NoProxy, cfg.Network.CABundle); err != nil {
+				return err
+			}
+			logUsage(cfg, cmd.Name)
+			for stage, value := range timeoutFlags {
+				cfg.Timeouts.Set(stage, value)
+			}
+			return applyTimeouts(cfg)
+		},
+	}
+
+	app.Commands = []*cli.Command{
+		commitCommand(cfg, "commit", "Review changes, generate a message, commit, and push", nil),
+		commitCommand(cfg, "plan", "Like commit, but ask for confirmation before committing", func(opts *commitOptions) {
+			opts.Plan = true
+		}),
+		commitCommand(cfg, "demo", "Run the whole pipeline without writing anything", func(opts *commitOptions) {
+			opts.Demo = true
+		}),
+		reviewCommand(cfg),
+		explainCommand(cfg),
+		describeCommand(cfg),
+		{
+			Name:      "show-review",
+			Usage:     "[<commit>]",
+			Short:     "Show the review Claude did of a commit, kept as a git note",
+			Long:      "cc attaches the findings of each commit it reviews to the commit under\nrefs/notes/cc (turn this off with no_review_notes). The default commit is HEAD.",
+			NeedsRepo: true,
+			Run: func(args []string) error {
+				if len(args) > 1 {
+					return fmt.Errorf("usage: cc show-review [<commit>]")
+				}
+				rev := "HEAD"
+				if len(args) == 1 {
+					rev = args[0]
+				}
+				return handleShowReview(rev)
+			},
+		},
+		prDescCommand(cfg),
+		squashMsgCommand(cfg),
+		mergeCommand(cfg),
+		actionCommand(cfg),
+		{
+			Name:      "tui",
+			Short:     "Stage, review, and commit in a full-screen interface",
+			Long:      "Shows the changed files, the selected file's diff, Claude's findings, and an editable\ncommit message. Only staged files are reviewed and committed.",
+			NeedsRepo: true,
+			Run: func(args []string) error {
+				if err := noArgs(args); err != nil {
+					return err
+				}
+				return handleTUI(cfg)
+			},
+		},
+		{
+			Name:      "wip",
+			Short:     "Commit everything as a quick work-in-progress checkpoint (no review, no push)",
+			Long:      "The one-line description comes from wip_model (default haiku), based on the\ndiff --stat summary only. Combine the checkpoints later with 'cc squash-wip'.",
+			NeedsRepo: true,
+			Run: func(args []string) error {
+				if err := noArgs(args); err != nil {
+					return err
+				}
+				return handleWIP(cfg)
+			},
+		},
+		{
+			Name:      "squash-wip",
+			Short:     "Squash the WIP commits at the tip of the branch into one reviewed commit",
+			Long:      "Finds the unpushed commits made by 'cc wip' (or with a \"wip\" subject) at the tip of\nthe current branch, and replaces them with one commit whose message Claude writes\nfrom their combined diff. Asks before rewriting; use --yes without a terminal.",
+			NeedsRepo: true,
+			Run: func(args []string) error {
+				if err := noArgs(args); err != nil {
+					return err
+				}
+				return handleSquashWIP(cfg)
+			},
+		},
+		{
+			Name:      "stash",
+			Short:     "Stash all changes under a message Claude writes from them",
+			Long:      "Runs 'git stash push --include-untracked' with a one-line description of the\nchanges from wip_model (default haiku), so 'git stash list' says what each entry holds.",
+			NeedsRepo: true,
+			Run: func(args []string) error {
+				if err := noArgs(args); err != nil {
+					return err
+				}
+				return handleStash(cfg)
+			},
+		},
+		{
+			Name:      "fixup",
+			Short:     "Commit the changes as a fixup for the earlier commit they belong to",
+			Long:      "Claude matches the staged changes (or all changes, when nothing is staged) to the\nunpushed commits on the branch. When they clearly belong to one, cc offers to\ncommit them with 'git commit --fixup' for 'git rebase -i --autosquash'.",
+			NeedsRepo: true,
+			Run: func(args []string) error {
+				if err := noArgs(args); err != nil {
+					return err
+				}
+				return handleFixup(cfg)
+			},
+		},
+		{
+			Name:      "undo",
+			Short:     "Take back the last commit cc made",
+			Long:      "Before the commit is pushed, it is soft-reset and the index is restored to what\nwas staged before cc ran. Once pushed, cc offers a revert commit instead.",
+			NeedsRepo: true,
+			Run: func(args []string) error {
+				if err := noArgs(args); err != nil {
+					return err
+				}
+				return handleUndo()
+			},
+		},
+		{
+			Name:      "trust",
+			Short:     "Allow the commands and settings of .claude-commit.json",
+			Long:      "Shows the commands, checks, and restricted settings of the repository's\n.claude-commit.json that aren't trusted yet, and remembers them in .git/config\nuntil they change. Runs without a terminal (or with --yes) only use what was\ntrusted this way or in an earlier terminal run.",
+			NeedsRepo: true,
+			Run: func(args []string) error {
+				if err := noArgs(args); err != nil {
+					return err
+				}
+				return handleTrust(cfg)
+			},
+		},
+		watchCommand(cfg),
+		historyCommand(),
+		statsCommand(),
+		doctorCommand(cfg),
+		hookCommand(cfg),
+		lintMsgCommand(cfg),
+		serveCommand(cfg),
+		{
+			Name:      "branch",
+			Usage:     `["short task description"]`,
+			Short:     "Create and switch to a branch named by Claude",
+			Long:      "Without a description, the branch name is derived from the uncommitted changes.",
+			NeedsRepo: true,
+			Run: func(args []string) error {
+				handleBranch(args, cfg)
+				return nil
+			},
+		},
+		{
+			Name:  "models",
+			Short: "Show or change the Claude model",
+			Run: func(args []string) error {
+				if err := noArgs(args); err != nil {
+					return err
+				}
+				handleModels(cfg)
+				return nil
+			},
+		},
+		{
+			Name:  "setup",
+			Short: "Choose the model, push behavior, and commit style interactively",
+			Run: func(args []string) error {
+				if err := noArgs(args); err != nil {
+					return err
+				}
+				return handleSetup(cfg)
+			},
+		},
+		{
+			Name:  "config",
+			Usage: "[list | get <key> | set <key> <value> | unset <key> | path]",
+			Short: "List, read, or change settings",
+			Long: `Keys use dotted paths, e.g. "model" or "pull_request.draft".
+Lists are comma-separated: cc config set pull_request.reviewers alice,bob`,
+			Run: func(args []string) error {
+				return handleConfig(cfg, args)
+			},
+		},
+		updateCommand(cfg),
+		installCommand(),
+		{
+			Name:  "telemetry",
+			Usage: "[on | off | status]",
+			Short: "Opt in to or out of anonymous usage telemetry",
+			Long: `Telemetry is off unless you turn it on. Each run then reports the command,
+its duration, the model, and whether it succeeded; never file names, diffs,
+or messages. DO_NOT_TRACK=1 or CC_TELEMETRY=off turn it off regardless.`,
+			Run: func(args []string) error {
+				return handleTelemetry(cfg, args)
+			},
+		},
+		{
+			Name:    "version",
+			Aliases: []string{"--version", "-v"},
+			Short:   "Print the cc version",
+			Run: func(args []string) error {
+				printf("cc version %s\n", VERSION)
+				return nil
+			},
+		},
+	}
+
+	return app
+}
+
+func reviewCommand(cfg *config.Config) *cli.Command {
+	security, fullDiff, summaryThreshold, fileContext := false, false, "", ""
+
+	return &cli.Command{
+		Name:      "review",
+		Usage:     "[--security] [--full-diff] [--file-context N]",
+		Short:     "Review changes and print findings without committing",
+		Long:      "With --security, only security problems are reported, and cc exits with 3 when a\nfinding is at or above block_severity.",
+		NeedsRepo: true,
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&security, "security", false, "review for security problems only (injection, authz, crypto, dependencies)")
+			fs.StringVar(&summaryThreshold, "summary-threshold", "", "review a stat summary instead of the full diff from this many changed files, however small (default from summary_threshold)")
+			fs.BoolVar(&fullDiff, "full-diff", false, "always review the full diff, however large")
+			fs.StringVar(&fileContext, "file-context", "", "send modified files of up to this many lines whole, next to the diff (default from file_context.lines)")
+		},
+		Run: func(args []string) error {
+			if err := noArgs(args); err != nil {
+				return err
+			}
+			if err := setFileContext(cfg, fileContext); err != nil {
+				return err
+			}
+			if err := setSummaryThreshold(cfg, summaryThreshold, fullDiff); err != nil {
+				return err
+			}
+			claude.SecurityReview = security
+			handleReview(cfg)
+			return nil
+		},
+	}
+}
+
+// commitCommand builds the commit command (or a shortcut for it, such as
+// plan) with preset adjusting the default options
+func commitCommand(cfg *config.Config, name string, short string, preset func(*commitOptions)) *cli.Command {
+	opts := commitOptions{}
+	if preset != nil {
+		preset(&opts)
+	}
+	// Resolved in Run, once the repository config has been merged
+	var noPush, openPR, edit, pull, ignoreWhitespace, skipWhitespace optionalBool
+	remote, pushTo, context, summaryThreshold, fileContext := "", "", "", "", ""
+	fullDiff := false
+
+	return &cli.Command{
+		Name:      name,
+		Usage:     "[flags] [--] [path...]",
+		Short:     short,
+		Long:      "With paths, only the changes under them are reviewed, staged, and committed;\nother changes (staged or not) are left alone.",
+		NeedsRepo: true,
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&opts.Plan, "plan", opts.Plan, "ask for confirmation before committing")
+			fs.BoolVar(&demoMode, "demo", opts.Demo, "run without staging, committing, pushing, or writing config")
+			fs.StringVar(&opts.Message, "message", "", "commit with this message instead of generating one")
+			fs.StringVar(&opts.Message, "m", "", "shorthand for --message")
+			fs.StringVar(&issueFlag, "issue", "", "the issue the change works on, for context and a closing reference (#123, ENG-42, or its URL)")
+			fs.StringVar(&opts.Type, "type", "", "use this Conventional Commits type for the message (e.g. fix, feat, chore)")
+			fs.BoolVar(&claude.SecurityReview, "security", false, "review for security problems only; blocking findings stop the commit unless --force")
+			fs.BoolVar(&opts.Review, "review", false, "with --message, still review the changes and block on findings")
+			fs.Var(&edit, "edit", "open the message in your editor before committing (default from edit)")
+			fs.StringVar(&context, "context", "", "lines of context around each change in the reviewed diff (default from diff_context, else 3)")
+			fs.StringVar(&fileContext, "file-context", "", "send modified files of up to this many lines whole, next to the diff (default from file_context.lines)")
+			fs.StringVar(&summaryThreshold, "summary-threshold", "", "review a stat summary instead of the full diff from this many changed files, however small (default from summary_threshold)")
+			fs.BoolVar(&fullDiff, "full-diff", false, "always review the full diff, however large")
+			fs.Var(&ignoreWhitespace, "ignore-whitespace", "leave whitespace changes out of the review (default from ignore_whitespace)")
+			fs.Var(&skipWhitespace, "skip-whitespace-only", "don't commit files whose changes are all whitespace; implies --ignore-whitespace (default from skip_whitespace_only)")
+			fs.BoolVar(&opts.PerPackage, "per-package", false, "make one commit per workspace package (go.work, package.json workspaces, or packages)")
+			fs.BoolVar(&opts.NoChecks, "no-checks", false, "skip the configured checks")
+			fs.BoolVar(&opts.DryRun, "dry-run", false, "stop after printing the findings and message")
+			fs.BoolVar(&opts.Copy, "copy", false, "copy the message to the clipboard instead of committing (implies --dry-run)")
+			fs.BoolFunc("copy-review", "like --copy, but include the findings", func(string) error {
+				opts.Copy = true
+				opts.CopyReview = true
+				return nil
+			})
+			fs.BoolVar(&opts.Force, "force", false, "commit even if Claude reports blocking findings")
+			fs.BoolVar(&opts.Force, "f", false, "shorthand for --force")
+			fs.Var(&noPush, "no-push", "commit without pushing (default from no_push)")
+			fs.StringVar(&remote, "remote", "", "push to this remote instead of the upstream's (default from push_remote)")
+			fs.StringVar(&pushTo, "push-to", "", "push to this branch on the remote (default from push_to)")
+			fs.Var(&pull, "pull", "fetch and rebase onto the remote branch before pushing (default from pull_before_push)")
+			fs.BoolVar(&opts.ForcePush, "force-push", false, "push with --force-with-lease, e.g. after rewriting commits (refused for protected_branches)")
+			fs.Var(&openPR, "pr", "open a pull request after pushing (default from pull_request.enabled)")
+			fs.BoolFunc("draft", "open the pull request as a draft (implies --pr)", func(string) error {
+				openPR = optionalBool{set: true, value: true}
+				opts.DraftPR = true
+				return nil
+			})
+			fs.BoolVar(&output.JSON, "json", false, "print a single JSON report instead of progress logs")
+		},
+		Run: func(args []string) error {
+			if err := setPaths(cfg, args); err != nil {
+				return err
+			}
+			opts.Paths = args
+			opts.Demo = demoMode
+			if output.JSON && opts.Plan && !output.Yes {
+				return fmt.Errorf("--json cannot be combined with plan mode (add --yes to confirm automatically)")
+			}
+			if opts.Review && opts.Message == "" {
+				return fmt.Errorf("--review only applies together with --message")
+			}
+			if err := checkType(cfg, opts); err != nil {
+				return err
+			}
+			if edit.set && edit.value && output.JSON {
+				return fmt.Errorf("--edit cannot be combined with --json")
+			}
+			if issueFlag != "" {
+				if _, _, err := parseIssueRef(issueFlag); err != nil {
+					return err
+				}
+			}
+			if opts.Copy {
+				opts.DryRun = true
+			}
+			// The config default only applies when someone is at the terminal
+			opts.Edit = edit.or(cfg.Edit && interactive())
+			opts.NoPush = noPush.or(cfg.NoPush)
+			if context != "" {
+				cfg.DiffContext = context
+				lines, err := cfg.ContextLines()
+				if err != nil {
+					return err
+				}
+				git.ContextLines = lines
+			}
+			if err := setFileContext(cfg, fileContext); err != nil {
+				return err
+			}
+			if err := setSummaryThreshold(cfg, summaryThreshold, fullDiff); err != nil {
+				return err
+			}
+			opts.SkipWhitespaceOnly = skipWhitespace.or(cfg.SkipWhitespaceOnly)
+			git.IgnoreWhitespace = opts.SkipWhitespaceOnly || ignoreWhitespace.or(cfg.IgnoreWhitespace)
+			cfg.PullBeforePush = pull.or(cfg.PullBeforePush)
+			if remote != "" {
+				cfg.PushRemote = remote
+			}
+			if pushTo != "" {
+				cfg.PushTo = pushTo
+			}
+			opts.OpenPR = openPR.or(cfg.PullRequest.Enabled)
+			if opts.PerPackage {
+				handlePerPackage(cfg, opts)
+				return nil
+			}
+			handleCommit(cfg, opts)
+			return nil
+		},
+	}
+}
+
+func explainCommand(cfg *config.Config) *cli.Command {
+	return &cli.Command{
+		Name:      "explain",
+		Usage:     "[<commit> | <from>..<to>] [--json]",
+		Short:     "Explain changes in plain language: what, likely why, and risk areas",
+		Long:      "Without arguments, the uncommitted changes are explained. Nothing is staged,\ncommitted, or written.",
+		NeedsRepo: true,
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&output.JSON, "json", false, "print the explanation as JSON")
+		},
+		Run: func(args []string) error {
+			if len(args) > 1 {
+				return fmt.Errorf("usage: cc explain [<commit> | <from>..<to>]")
+			}
+			rev := ""
+			if len(args) == 1 {
+				rev = args[0]
+			}
+			return handleExplain(cfg, rev)
+		},
+	}
+}
+
+func describeCommand(cfg *config.Config) *cli.Command {
+	amend, copyToClipboard := false, false
+
+	return &cli.Command{
+		Name:      "describe",
+		Usage:     "<commit> [--amend] [--copy]",
+		Short:     "Write a new message for an existing commit from its diff",
+		Long:      "Prints the message; --amend replaces the message of HEAD with it after\nconfirmation. Older commits can be reworded with 'git rebase -i'.",
+		NeedsRepo: true,
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&amend, "amend", false, "replace the message of the commit (HEAD only)")
+			fs.BoolVar(&copyToClipboard, "copy", false, "copy the message to the clipboard")
+		},
+		Run: func(args []string) error {
+			if len(args) != 1 || strings.Contains(args[0], "..") {
+				return fmt.Errorf("usage: cc describe <commit> [--amend] [--copy]")
+			}
+			return handleDescribe(cfg, args[0], amend, copyToClipboard)
+		},
+	}
+}
+
+func actionCommand(cfg *config.Config) *cli.Command {
+	opts := actionOptions{}
+	security := false
+
+	return &cli.Command{
+		Name:      "action",
+		Usage:     "[--post comment|review|none] [--security] [--pr N --base <sha> --head <sha>]",
+		Short:     "Review a pull request in CI and post the findings to GitHub",
+		Long:      "Meant for GitHub Actions: the pull request, base, and head come from the\npull_request event. The findings are posted as a comment (updated on reruns) or a\nreview, and cc sets a failing commit status and exits with 3 when a finding is at\nor above block_severity. Needs GITHUB_TOKEN with pull-requests: write.",
+		NeedsRepo: true,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&opts.Post, "post", "comment", "how to post the findings: comment, review, or none")
+			fs.BoolVar(&security, "security", false, "review for security problems only")
+			fs.IntVar(&opts.PR, "pr", 0, "pull request number (default from the event)")
+			fs.StringVar(&opts.Base, "base", "", "base commit of the pull request (default from the event)")
+			fs.StringVar(&opts.Head, "head", "", "head commit of the pull request (default from the event, else HEAD)")
+		},
+		Run: func(args []string) error {
+			if err := noArgs(args); err != nil {
+				return err
+			}
+			switch opts.Post {
+			case "comment", "review", "none":
+			default:
+				return fmt.Errorf("--post: unknown value %q (use comment, review, or none)", opts.Post)
+			}
+			claude.SecurityReview = security
+			return handleAction(cfg, opts)
+		},
+	}
+}
+
+func prDescCommand(cfg *config.Config) *cli.Command {
+	base := ""
+	copyToClipboard := false
+
+	return &cli.Command{
+		Name:      "pr-desc",
+		Usage:     "[--base main] [--copy]",
+		Short:     "Generate a pull request description for the current branch",
+		NeedsRepo: true,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&base, "base", "", "base branch to compare against (default: the remote's default branch)")
+			fs.BoolVar(&copyToClipboard, "copy", false, "copy the description to the clipboard instead of printing it")
+		},
+		Run: func(args []string) error {
+			if err := noArgs(args); err != nil {
+				return err
+			}
+			handlePRDesc(cfg, base, copyToClipboard)
+			return nil
+		},
+	}
+}
+
+func squashMsgCommand(cfg *config.Config) *cli.Command {
+	base := ""
+	copyToClipboard := false
+
+	return &cli.Command{
+		Name:      "squash-msg",
+		Usage:     "[--base main] [--copy]",
+		Short:     "Write one commit message for squash merging the current branch",
+		Long:      "Claude reads the messages and combined diff of the commits since the branch forked\nfrom base. Use it with 'git commit -F <(cc squash-msg -q)', or let 'cc merge --squash' do the merge.",
+		NeedsRepo: true,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&base, "base", "", "base branch to compare against (default: the remote's default branch)")
+			fs.BoolVar(&copyToClipboard, "copy", false, "copy the message to the clipboard instead of printing it")
+		},
+		Run: func(args []string) error {
+			if err := noArgs(args); err != nil {
+				return err
+			}
+			return handleSquashMsg(cfg, base, copyToClipboard)
+		},
+	}
+}
+
+func mergeCommand(cfg *config.Config) *cli.Command {
+	squash := false
+
+	return &cli.Command{
+		Name:      "merge",
+		Usage:     "--squash <branch>",
+		Short:     "Squash merge a branch with a message Claude writes from its commits",
+		Long:      "Shows the message of 'cc squash-msg' for the branch, and after you confirm runs\n'git merge --squash' and commits. Asks first; use --yes without a terminal.",
+		NeedsRepo: true,
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&squash, "squash", false, "squash the branch into one commit (the only kind of merge cc does)")
+		},
+		Run: func(args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("usage: cc merge --squash <branch>")
+			}
+			if !squash {
+				return fmt.Errorf("cc merge only does squash merges: pass --squash, or use git merge")
+			}
+			return handleMergeSquash(cfg, args[0])
+		},
+	}
+}
+
+func historyCommand() *cli.Command {
+	repo := ""
+	limit := 20
+
+	return &cli.Command{
+		Name:  "history",
+		Usage: "[--repo dir] [--limit n] [--json]",
+		Short: "Show past runs: files, model, findings, message, and commit",
+		Long: `Every commit run (except demo mode) is logged to ~/.claude-commit/history.jsonl
+with the changed files, model, findings, final message, and commit SHA. Turn
+the log off with no_history.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&repo, "repo", "", "only show runs in this repository (e.g. .)")
+			fs.IntVar(&limit, "limit", limit, "show at most this many runs (0 for all)")
+			fs.BoolVar(&output.JSON, "json", false, "print the entries as a JSON array")
+		},
+		Run: func(args []string) error {
+			if err := noArgs(args); err != nil {
+				return err
+			}
+			return handleHistory(repo, limit)
+		},
+	}
+}
+
+func installCommand() *cli.Command {
+	dir := ""
+
+	return &cli.Command{
+		Name:  "install",
+		Usage: "[--dir <directory>]",
+		Short: "Copy this cc binary to ~/.local/bin or /usr/local/bin",
+		Long: `Installs the running binary into ~/.local/bin if it exists, otherwise
+/usr/local/bin (with sudo when it isn't writable), then checks that 'cc' on
+your PATH runs it. Download a release binary and run './cc-<os>-<arch> install'.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&dir, "dir", "", "install into this directory instead")
+		},
+		Run: func(args []string) error {
+			if err := noArgs(args); err != nil {
+				return err
+			}
+			return handleInstall(dir)
+		},
+	}
+}
+
+func updateCommand(cfg *config.Config) *cli.Command {
+	channel := ""
+	to := ""
+	rollback := false
+	allowUnsigned := false
+
+	return &cli.Command{
+		Name:  "update",
+		Usage: "[--channel stable|beta] [--to <version>] | --rollback",
+		Short: "Update cc to the latest release, or install a given version",
+		Long: `The beta channel includes pre-releases. Set it for good with
+'cc config set update_channel beta'. --to installs exactly that version,
+e.g. 'cc update --to v1.0.8' to roll back to a known-good release.
+
+Each update keeps the binary it replaced next to cc with an .old suffix.
+--rollback swaps it back in (run it again to undo), or re-downloads the
+release before this one when there is none.
+
+Downloads are checked against the release's checksums.txt, whose minisign
+signature is verified with the key built into cc.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&channel, "channel", "", "release channel: stable or beta (default: update_channel, then stable)")
+			fs.StringVar(&to, "to", "", "install this version instead of the newest, e.g. v1.0.8")
+			fs.BoolVar(&rollback, "rollback", false, "go back to the version the last update replaced")
+			fs.BoolVar(&allowUnsigned, "allow-unsigned", false, "install a release without a signature (checked against its checksum only)")
+		},
+		Run: func(args []string) error {
+			if err := noArgs(args); err != nil {
+				return err
+			}
+			if rollback {
+				if to != "" || channel != "" {
+					return fmt.Errorf("--rollback can't be combined with --to or --channel")
+				}
+				handleRollback(allowUnsigned)
+				return nil
+			}
+			if channel == "" {
+				channel = cfg.UpdateChannel
+			}
+			switch channel {
+			case "":
+				channel = "stable"
+			case "stable", "beta":
+			default:
+				return fmt.Errorf("unknown channel %q (use stable or beta)", channel)
+			}
+			handleUpdate(channel, to, allowUnsigned)
+			return nil
+		},
+	}
+}
+
+func doctorCommand(cfg *config.Config) *cli.Command {
+	offline := false
+
+	return &cli.Command{
+		Name:  "doctor",
+		Usage: "[--offline]",
+		Short: "Check git, the claude CLI and its login, the config, the remote, and hooks",
+		Long: `Prints a pass or fail line for each part of the environment cc depends on,
+with a hint on how to fix each problem. Exits non-zero if any check fails.
+The claude check sends the configured model a tiny prompt.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&offline, "offline", false, "skip the checks that contact Claude or the remote")
+		},
+		Run: func(args []string) error {
+			if err := noArgs(args); err != nil {
+				return err
+			}
+			return handleDoctor(cfg, offline)
+		},
+	}
+}
+
+func statsCommand() *cli.Command {
+	days := 30
+
+	return &cli.Command{
+		Name:  "stats",
+		Usage: "[--days n] [--json]",
+		Short: "Show token usage and estimated spend per day and model",
+		Long: `Every claude call's token counts (and cost, when the claude CLI reports it) are
+logged to ~/.claude-commit/usage.jsonl. Costs the CLI doesn't report are
+estimated from list prices and marked with ~.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.IntVar(&days, "days", days, "only count the last n days (0 for all time)")
+			fs.BoolVar(&output.JSON, "json", false, "print the totals as JSON")
+		},
+		Run: func(args []string) error {
+			if err := noArgs(args); err != nil {
+				return err
+			}
+			return handleStats(days)
+		},
+	}
+}
+
+func watchCommand(cfg *config.Config) *cli.Command {
+	quietPeriod, branch := "", ""
+
+	return &cli.Command{
+		Name:  "watch",
+		Usage: "[--quiet-period 30s] [--branch name]",
+		Short: "Commit AI-described checkpoints to a side branch while you work",
+		Long: `Snapshots the working tree every few seconds. Once it has stayed unchanged for
+the quiet period, the snapshot is committed to a side branch (by default
+cc/checkpoints/<current branch>) with a message from Claude. The current
+branch, the index, and the working tree are left alone.`,
+		NeedsRepo: true,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&quietPeriod, "quiet-period", "", "how long the tree must stay unchanged before a checkpoint (default from watch.quiet_period, 30s)")
+			fs.StringVar(&branch, "branch", "", "side branch for the checkpoints; {branch} is the current branch (default from watch.branch)")
+		},
+		Run: func(args []string) error {
+			if err := noArgs(args); err != nil {
+				return err
+			}
+			if quietPeriod != "" {
+				cfg.Watch.QuietPeriod = quietPeriod
+			}
+			if branch != "" {
+				cfg.Watch.Branch = branch
+			}
+			return handleWatch(cfg)
+		},
+	}
+}
+
+func hookCommand(cfg *config.Config) *cli.Command {
+	force := false
+	prePush := false
+	commitMsg := false
+
+	return &cli.Command{
+		Name:  "hook",
+		Usage: "install [--pre-push | --commit-msg] [--force] | uninstall [--pre-push | --commit-msg]",
+		Short: "Install git hooks that run cc during normal git commands",
+		Long: `install writes a prepare-commit-msg hook, so a plain 'git commit' opens the
+editor with a message generated from the staged changes. Findings are added
+as comments. The hook never blocks a commit.
+
+With --pre-push, install writes a pre-push hook instead. It reviews the
+commits about to be pushed and blocks the push on findings at or above
+block_severity.
+
+With --commit-msg, install writes a commit-msg hook that rejects messages not
+matching the configured style (see 'cc lint-msg').`,
+		NeedsRepo: true,
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&force, "force", false, "replace a hook that wasn't installed by cc")
+			fs.BoolVar(&prePush, "pre-push", false, "manage the pre-push review hook")
+			fs.BoolVar(&commitMsg, "commit-msg", false, "manage the commit-msg lint hook")
+		},
+		Run: func(args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("usage: cc hook install | uninstall")
+			}
+			name := "prepare-commit-msg"
+			if prePush {
+				name = "pre-push"
+			} else if commitMsg {
+				name = "commit-msg"
+			}
+			switch args[0] {
+			case "install":
+				return handleHookInstall(name, force)
+			case "uninstall":
+				return handleHookUninstall(name)
+			// Called by the installed hooks
+			case "prepare-commit-msg":
+				return runPrepareCommitMsgHook(cfg, args[1:])
+			case "pre-push":
+				return runPrePushHook(cfg, args[1:], os.Stdin)
+			case "commit-msg":
+				if len(args) != 2 {
+					return fmt.Errorf("usage: cc hook commit-msg <message-file>")
+				}
+				return handleLintMsg(cfg, args[1], false)
+			}
+			return fmt.Errorf("unknown hook action %q (use install or uninstall)", args[0])
+		},
+	}
+}
+
+func lintMsgCommand(cfg *config.Config) *cli.Command {
+	fix := false
+
+	return &cli.Command{
+		Name:  "lint-msg",
+		Usage: "[--fix] <message-file>",
+		Short: "Check a hand-written commit message against the configured style",
+		Long: `Checks Conventional Commits format, subject length, the blank line before the
+body, and the message_body and language settings. Exits non-zero on problems,
+so it can run as a commit-msg hook ('cc hook install --commit-msg').`,
+		NeedsRepo: true,
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&fix, "fix", false, "let Claude rewrite a failing message in place")
+		},
+		Run: func(args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("usage: cc lint-msg [--fix] <message-file>")
+			}
+			return handleLintMsg(cfg, args[0], fix)
+		},
+	}
+}
+
+// noArgs rejects unexpected positional arguments
+func noArgs(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unknown command or argument: %s (run 'cc --help' for usage)", strings.Join(args, " "))
+	}
+	return nil
+}
+
+// commitTypePattern matches a Conventional Commits type
+var commitTypePattern = regexp.MustCompile(`^[a-z]+$`)
+
+// checkType validates --type against the form of a type and the types the
+// project's conventions allow
+func checkType(cfg *config.Config, opts commitOptions) error {
+	if opts.Type == "" {
+		return nil
+	}
+	if opts.Message != "" {
+		return fmt.Errorf("--type only applies to generated messages, not --message")
+	}
+	if !commitTypePattern.MatchString(opts.Type) {
+		return fmt.Errorf("invalid --type %q (use a lowercase type such as fix, feat, or chore)", opts.Type)
+	}
+	if found := projectConventions(cfg); found != nil && len(found.Types) > 0 && !slices.Contains(found.Types, opts.Type) {
+		return fmt.Errorf("--type %s is not one of the project's types (%s)", opts.Type, strings.Join(found.Types, ", "))
+	}
+	return nil
+}
+
+// setPaths limits the run to the given pathspecs. A plain path must exist
+// in the working tree or the index, so a mistyped command name isn't taken
+// for an empty path.
+func setPaths(cfg *config.Config, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	if cfg.GitBackend == "go-git" {
+		return fmt.Errorf("paths are not supported with git_backend go-git")
+	}
+	prefix, err := git.GetPrefix(runCtx)
+	if err != nil {
+		return err
+	}
+	// Some git commands run from the root, so paths are made relative to it
+	var specs []string
+	for _, path := range paths {
+		if strings.HasPrefix(path, ":") {
+			specs = append(specs, path) // Pathspec magic is left to git
+			continue
+		}
+		if !strings.ContainsAny(path, "*?[") {
+			if _, err := os.Lstat(path); err != nil && !git.IsKnownPath(runCtx, path) {
+				return fmt.Errorf("unknown command or path: %s (run 'cc --help' for usage)", path)
+			}
+		}
+		top := pathpkg.Clean(prefix + filepath.ToSlash(path))
+		if top == ".." || strings.HasPrefix(top, "../") {
+			return fmt.Errorf("%s is outside the repository", path)
+		}
+		specs = append(specs, ":(top)"+top)
+	}
+	git.Paths = specs
+	return nil
+}
+
+// optionalBool is a boolean flag that remembers whether it was given, so an
+// unset flag can fall back to the config
+type optionalBool struct {
+	set   bool
+	value bool
+}
+
+func (b *optionalBool) String() string   { return strconv.FormatBool(b.value) }
+func (b *optionalBool) IsBoolFlag() bool { return true }
+
+func (b *optionalBool) Set(s string) error {
+	value, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	b.set, b.value = true, value
+	return nil
+}
+
+// or returns the flag's value if it was given, otherwise def
+func (b *optionalBool) or(def bool) bool {
+	if b.set {
+		return b.value
+	}
+	return def
+}
+
+// setSummaryThreshold applies --summary-threshold or --full-diff over the
+// summary_threshold and summary_tokens settings
+func setSummaryThreshold(cfg *config.Config, threshold string, fullDiff bool) error {
+	if fullDiff {
+		if threshold != "" {
+			return fmt.Errorf("--full-diff cannot be combined with --summary-threshold")
+		}
+		cfg.SummaryTokens, threshold = "0", "0"
+	}
+	if threshold == "" {
+		return nil
+	}
+	cfg.SummaryThreshold = threshold
+	return applySummaryThreshold(cfg)
+}
+
+// setFileContext applies --file-context over file_context.lines
+func setFileContext(cfg *config.Config, lines string) error {
+	if lines == "" {
+		return nil
+	}
+	cfg.FileContext.Lines = lines
+	return applyFileContext(cfg)
+}
@@ -0,0 +1,699 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/clipboard"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+	"github.com/quaywin/claude-commit/internal/i18n"
+	"github.com/quaywin/claude-commit/internal/offline"
+)
+
+// commitOptions are the flags of the default review → commit → push command
+type commitOptions struct {
+	Plan    bool   // Ask for confirmation before committing
+	Force   bool   // Commit despite blocking findings
+	NoPush  bool   // Commit without pushing
+	OpenPR  bool   // Open a pull request after pushing
+	DraftPR bool   // Open the pull request as a draft
+	Demo    bool   // Run without writing anything
+	DryRun  bool   // Stop after printing the findings and message
+	Message string // Use this message instead of generating one
+	Review  bool   // With Message, still review the changes
+	Edit    bool   // Open the message in the editor before committing
+	Copy    bool   // Copy the message to the clipboard (with DryRun)
+	// ForcePush pushes with --force-with-lease
+	ForcePush bool
+	// Paths limits the run to these paths, as given on the command line
+	Paths []string
+	// SkipWhitespaceOnly leaves files with only whitespace changes out of the commit
+	SkipWhitespaceOnly bool
+	// CopyReview adds the findings to the copied text
+	CopyReview bool
+	// PerPackage makes one commit per workspace package
+	PerPackage bool
+	// Hint is extra guidance for the message, e.g. the scope of a package
+	Hint string
+	// Type is the Conventional Commits type the message must use
+	Type string
+	// NoChecks skips the configured checks
+	NoChecks bool
+}
+
+// commitReport is the outcome of a run, printed as a single document with --json
+type commitReport struct {
+	Package        string           `json:"package,omitempty"` // Set with --per-package
+	ChangedFiles   []string         `json:"changed_files"`
+	Mode           string           `json:"mode,omitempty"`
+	Model          string           `json:"model"`
+	Checks         []checkOutcome   `json:"checks,omitempty"`
+	Debug          []string         `json:"debug_statements,omitempty"` // "file:line: text"
+	Todos          []string         `json:"todos,omitempty"`            // New TODO/FIXME/HACK comments, "file:line: text"
+	TodosResolved  int              `json:"todos_resolved,omitempty"`
+	Findings       []claude.Finding `json:"findings"`
+	Blocked        bool             `json:"blocked"`
+	CommitMessage  string           `json:"commit_message,omitempty"`
+	CommitSHA      string           `json:"commit_sha,omitempty"`
+	Branch         string           `json:"branch,omitempty"`
+	Worktree       string           `json:"worktree,omitempty"` // Set in linked worktrees
+	Pushed         bool             `json:"pushed"`
+	PullRequestURL string           `json:"pull_request_url,omitempty"`
+	Aborted        bool             `json:"aborted,omitempty"`
+	Interrupted    bool             `json:"interrupted,omitempty"` // Stopped with Ctrl+C
+	DryRun         bool             `json:"dry_run,omitempty"`
+	Copied         bool             `json:"copied,omitempty"`
+	Offline        bool             `json:"offline,omitempty"` // The message is rule-based; nothing was reviewed
+	Timings        []stepTiming     `json:"timings,omitempty"` // Time spent in each step
+	Error          string           `json:"error,omitempty"`
+	ExitCode       int              `json:"exit_code"`
+}
+
+// errBlocked is returned when findings stop the commit; the details have already been printed
+var errBlocked = withExitCode(exitBlocked, errors.New("blocking findings"))
+
+// handleCommit runs the commit pipeline and reports the outcome, exiting
+// with one of the documented exit codes
+func handleCommit(cfg *config.Config, opts commitOptions) {
+	clock := newStepClock()
+	report, err := runCommit(cfg, opts, clock)
+	code := finishRun(cfg, opts, report, err, clock)
+
+	if output.JSON {
+		printJSON(report)
+	}
+	exit(code)
+}
+
+// finishRun fills in the outcome of a run, logs it to the history, and
+// prints the timings and any error (except with --json, where they are part
+// of the report). It returns the exit code.
+func finishRun(cfg *config.Config, opts commitOptions, report *commitReport, err error, clock *stepClock) int {
+	clock.end()
+	report.Timings = clock.steps
+
+	code := exitOK
+	switch {
+	case err != nil:
+		code = exitCodeOf(err)
+		report.Interrupted = code == exitInterrupted
+	case report.Blocked:
+		code = exitBlocked
+	case report.Aborted:
+		code = exitAborted
+	case len(report.ChangedFiles) == 0:
+		code = exitNoChanges
+	}
+	report.ExitCode = code
+	recordRun(cfg, opts, report, err)
+	if len(report.Timings) > 0 {
+		logf("\n⏱️  Timings: %s\n", clock.summary())
+	}
+
+	if output.JSON {
+		if err != nil {
+			report.Error = err.Error()
+		}
+	} else if report.Interrupted && report.CommitSHA != "" {
+		summaryf("\n⏹️  Interrupted after committing %s; nothing was pushed.\n", shortSHA(report.CommitSHA))
+	} else if report.Interrupted {
+		summaryf("\n⏹️  Interrupted. No changes were committed.\n")
+	} else if err != nil && !errors.Is(err, errBlocked) {
+		printf("❌ Error %v\n", err)
+	}
+	return code
+}
+
+// runCommit reviews the changes, generates a message, and stages, commits,
+// and pushes them, timing each step on clock. The report is filled in as far
+// as the run got.
+func runCommit(cfg *config.Config, opts commitOptions, clock *stepClock) (*commitReport, error) {
+	report := &commitReport{Model: cfg.Model, ChangedFiles: []string{}, Findings: []claude.Finding{}}
+
+	if opts.Demo {
+		logln("🎭 Demo mode: running the full pipeline without writing anything (no staging, commit, push, or config changes).")
+	}
+
+	logln("🔍 Checking for changes...")
+
+	if !opts.DryRun {
+		aborted, err := checkRepoState(!opts.Demo)
+		if err != nil {
+			return report, err
+		}
+		if aborted {
+			summaryf("❌ Aborted. No changes were committed.\n")
+			report.Aborted = true
+			return report, nil
+		}
+	}
+
+	// 1. Get changed files, determine mode, and get the appropriate diff
+	clock.begin("diff")
+	changes, err := collectChanges()
+	clock.end()
+	if err != nil {
+		return report, withExitCode(exitGitError, err)
+	}
+
+	var whitespaceOnly []string
+	if git.IgnoreWhitespace {
+		if whitespaceOnly, err = whitespaceOnlyFiles(changes, opts.SkipWhitespaceOnly); err != nil {
+			return report, withExitCode(exitGitError, err)
+		}
+	}
+
+	if len(changes.Files) == 0 || changes.Diff == "" {
+		summaryf("✅ No changes to commit.\n")
+		return report, nil
+	}
+
+	// Local checks of the added lines, before the review. Everything gets
+	// staged, so unresolved conflicts would be committed, even in files the
+	// review leaves out.
+	if markers := findConflictMarkers(committedLines()); len(markers) > 0 {
+		reportConflictMarkers(markers)
+		report.Blocked = true
+		return report, errConflictMarkers
+	}
+	lines := changedLines(fullDiff(changes))
+	if found := findDebugStatements(cfg, lines); len(found) > 0 {
+		warnDebugStatements(found)
+		for _, d := range found {
+			report.Debug = append(report.Debug, d.String())
+		}
+	}
+	todos := findTodoDelta(lines)
+	reportTodoDelta(todos)
+	for _, line := range todos.Added {
+		report.Todos = append(report.Todos, line.String())
+	}
+	report.TodosResolved = len(todos.Resolved)
+	if err := stopOnFailure(opts, report, todoPolicyError(cfg, todos)); err != nil {
+		return report, err
+	}
+
+	report.ChangedFiles = changes.Files
+	report.Mode = changes.mode()
+
+	threshold, err := claude.ParseSeverity(cfg.BlockSeverity)
+	if err != nil {
+		return report, fmt.Errorf("invalid block_severity in config: %w", err)
+	}
+
+	// Refuse a protected force-push before anything is committed
+	if opts.ForcePush && !opts.NoPush {
+		target, err := resolvePushTarget(cfg)
+		if err != nil {
+			return report, withExitCode(exitGitError, err)
+		}
+		if err := checkForcePush(cfg, target); err != nil {
+			return report, err
+		}
+	}
+
+	// Catch large files and build junk before `git add .` puts them in history
+	suspects, err := findSuspectFiles(cfg, changes.Files)
+	if err != nil {
+		return report, err
+	}
+	if len(suspects) > 0 && !opts.DryRun && !opts.Demo {
+		ok, err := confirmSuspectFiles(suspects)
+		if err != nil {
+			return report, err
+		}
+		if !ok {
+			summaryf("❌ Aborted. No changes were committed.\n")
+			report.Aborted = true
+			return report, nil
+		}
+	} else if len(suspects) > 0 {
+		logf("\n⚠️  %d file(s) would need confirmation before staging: %s\n", len(suspects), suspectPaths(suspects))
+	}
+
+	// Catch a push that would be rejected before committing, not after
+	if !opts.Demo && !opts.DryRun {
+		aborted, err := checkBehind(cfg, &opts)
+		if err != nil {
+			return report, err
+		}
+		if aborted {
+			summaryf("❌ Aborted. No changes were committed.\n")
+			report.Aborted = true
+			return report, nil
+		}
+	}
+
+	// Run the user's checks (format, tests, linters) before paying for a review
+	checks := len(cfg.Checks) > 0 && !opts.NoChecks
+	if (checks || len(cfg.Commands.BeforeReview) > 0) && !opts.Demo {
+		trusted, err := trustCommands(cfg)
+		if err != nil {
+			return report, err
+		}
+		if !trusted {
+			logf("\n⚠️  Skipping the commands from %s.\n", config.RepoConfigFileName)
+		} else {
+			if checks {
+				clock.begin("checks")
+				report.Checks, err = runChecks(cfg.Checks)
+				clock.end()
+				if err := stopOnFailure(opts, report, err); err != nil {
+					return report, err
+				}
+			}
+			if len(cfg.Commands.BeforeReview) > 0 {
+				clock.begin("commands")
+				err := runCommands("before_review", cfg.Commands.BeforeReview)
+				clock.end()
+				if err := stopOnFailure(opts, report, err); err != nil {
+					return report, err
+				}
+			}
+		}
+	}
+
+	// Small commits can use a cheaper model (models in the config)
+	cfg = withModel(cfg, modelFor(cfg, commitTask(cfg, len(lines))))
+
+	// Check what the review would cost before sending anything
+	if opts.Message == "" || opts.Review {
+		aborted, err := checkCost(cfg, changes)
+		if err != nil {
+			return report, err
+		}
+		if aborted {
+			summaryf("❌ Aborted. No changes were committed.\n")
+			report.Aborted = true
+			return report, nil
+		}
+		report.Mode = changes.mode()
+	}
+
+	policyFindings := testPolicyFindings(cfg, changes.Files)
+
+	var message string
+	var conversation *claude.Conversation // Questions asked in plan mode
+	hint := opts.Hint
+	if cfg.Todos.Context && len(todos.Added) > 0 {
+		hint = strings.TrimSpace(hint + "\n" + todoHint(todos))
+	}
+	for {
+		// 2. Call Claude for review and commit message
+		clock.begin("review")
+		result, err := reviewAndMessage(cfg, opts, changes, hint)
+		clock.end()
+		if err := checkInterrupted(); err != nil {
+			return report, err
+		}
+		if errors.Is(err, claude.ErrUnavailable) {
+			if offlineFallback(cfg) {
+				result, err = offlineResult(opts, err)
+				report.Offline = true
+			} else if !cfg.NoOfflineFallback {
+				eprintf("\n⚠️  Not falling back to an unreviewed rule-based message without a terminal; set offline_fallback to allow it.\n")
+			}
+		}
+		if err != nil {
+			return report, withExitCode(exitModelErr, fmt.Errorf("calling Claude: %w", err))
+		}
+		report.Model = cfg.Model
+		if model := claude.LastModel(); opts.Message == "" && model != "" && model != cfg.Model {
+			logf("🔁 The review and message are from the fallback model %s\n", model)
+			report.Model = model
+		}
+		if report.Offline {
+			report.Model = "offline"
+		}
+		result.Findings = append(result.Findings, policyFindings...)
+		report.Findings = append([]claude.Finding{}, result.Findings...)
+
+		// 3. Report findings and block on those at or above the configured severity
+		if len(result.Findings) > 0 {
+			summaryf("\n🔎 Claude's findings (%d):\n", len(result.Findings))
+			printFindings(result.Findings)
+		}
+
+		if blocking := result.Blocking(threshold); len(blocking) > 0 {
+			if !opts.Force && opts.DryRun {
+				summaryf("\n⚠️  %d finding(s) at or above %s severity would block this commit.\n", len(blocking), threshold)
+				report.Blocked = true
+			} else if !opts.Force && opts.Demo {
+				logf("\n🎭 [demo] cc would stop here: %d finding(s) at or above %s (or rerun with --force).\n", len(blocking), threshold)
+				report.Blocked = true
+				return report, nil
+			} else if !opts.Force {
+				summaryf("\n⚠️  %d finding(s) at or above %s severity block this commit.\n", len(blocking), threshold)
+				summaryf("Please fix these issues before committing. Use --force or -f to commit anyway.\n")
+				report.Blocked = true
+				return report, errBlocked
+			}
+			logln("\n⚠️  Force mode enabled. Proceeding with commit despite blocking findings.")
+		}
+		ruleBased := false
+		if result.Message == "" && opts.Force {
+			// --force still commits, like it did when the finding came
+			// without a suggested message
+			ruleBased = true
+			if result.Message, err = ruleBasedMessage(); err != nil {
+				return report, err
+			}
+			logln("📝 Claude reported a critical finding instead of a commit message; using a rule-based one.")
+		}
+		if result.Message == "" {
+			if opts.DryRun {
+				summaryf("\n🧪 Dry run: Claude reported a critical finding instead of a commit message.\n")
+				report.DryRun = true
+				return report, nil
+			}
+			return report, withExitCode(exitModelErr, fmt.Errorf("Claude reported a critical finding instead of a commit message; pass one with -m to commit anyway"))
+		}
+
+		generatedBy := report.Model
+		if opts.Message != "" || report.Offline || ruleBased {
+			generatedBy = ""
+		}
+		message = finishMessage(cfg, result.Message, generatedBy)
+		report.CommitMessage = message
+
+		// 4. Show commit message
+		subject, body := claude.SplitMessage(message)
+		if report.Offline {
+			summaryf("\n📝 Commit message (rule-based, not AI): %s\n", subject)
+		} else {
+			summaryf("\n📝 Commit message: %s\n", subject)
+		}
+		if body != "" {
+			summaryf("\n%s\n", body)
+		}
+
+		if opts.DryRun {
+			if opts.Copy {
+				if err := clipboard.Copy(clipboardText(message, result.Findings, opts.CopyReview)); err != nil {
+					return report, fmt.Errorf("copying to clipboard: %w", err)
+				}
+				report.Copied = true
+				summaryf("\n📋 Commit message copied to clipboard.\n")
+			}
+			summaryf("\n🧪 Dry run: nothing was staged, committed, or pushed.\n")
+			report.DryRun = true
+			return report, nil
+		}
+
+		if opts.Edit {
+			// Only the first message goes to the editor automatically; the plan menu can open it again
+			opts.Edit = false
+			edited, err := editMessage(message)
+			if err != nil {
+				return report, err
+			}
+			if edited == "" {
+				summaryf("❌ Aborted: empty commit message. No changes were committed.\n")
+				report.Aborted = true
+				return report, nil
+			}
+			if edited != message {
+				message = edited
+				report.CommitMessage = message
+				summaryf("\n📝 Edited commit message:\n%s\n", message)
+			}
+		}
+
+		// 5. In plan mode, let the user accept, edit, or regenerate the message
+		if !opts.Plan {
+			break
+		}
+		if conversation == nil {
+			conversation = claude.NewConversation(changes.Diff, changes.UseSummaryMode)
+		}
+		action, err := planMenu(cfg, conversation, &message, &hint)
+		if err != nil {
+			return report, err
+		}
+		report.CommitMessage = message
+		if action == planAbort {
+			summaryf("❌ Aborted. No changes were committed.\n")
+			report.Aborted = true
+			return report, nil
+		}
+		if action == planAccept {
+			break
+		}
+		// Regenerate, even if the first message came from --message
+		opts.Message = ""
+	}
+
+	// 6. Stage, Commit, and Push
+	if opts.Demo {
+		showDemoPlan(cfg, changes.Files, message, opts)
+		return report, nil
+	}
+
+	// Ctrl+C from here on stops before the next step, never halfway
+	if err := checkInterrupted(); err != nil {
+		return report, err
+	}
+	if len(opts.Paths) > 0 {
+		logf("🚀 Staging changes (%s)...\n", strings.Join(opts.Paths, " "))
+	} else {
+		logln("🚀 Staging all changes...")
+	}
+	clock.begin("stage")
+	indexTree := indexBeforeStaging()
+	if err := stageChanges(whitespaceOnly); err != nil {
+		if runCtx.Err() != nil {
+			return report, unstageInterrupted(indexTree)
+		}
+		return report, withExitCode(exitGitError, fmt.Errorf("staging changes: %w", err))
+	}
+	if runCtx.Err() != nil {
+		return report, unstageInterrupted(indexTree)
+	}
+
+	logln("💾 Committing...")
+	clock.begin("commit")
+	if err := git.Commit(runCtx, message, cfg.SignOff); err != nil {
+		if runCtx.Err() != nil {
+			return report, unstageInterrupted(indexTree)
+		}
+		return report, withExitCode(exitGitError, fmt.Errorf("committing: %w", err))
+	}
+	clock.end()
+	recordCommit(indexTree)
+	if sha, err := git.GetHeadSHA(runCtx); err == nil {
+		report.CommitSHA = sha
+	}
+	if (opts.Message == "" || opts.Review) && !report.Offline {
+		saveReviewNote(cfg, report, threshold, opts.Force)
+	}
+	if branch, err := git.GetCurrentBranch(runCtx); err == nil {
+		report.Branch = branch
+	}
+	if root, linked := git.GetWorktree(runCtx); linked {
+		report.Worktree = root
+		summaryf("\n🌳 Committed on %s in worktree %s\n", report.Branch, root)
+	}
+
+	if opts.NoPush {
+		summaryf("\n✨ Done! %s\n", doneMessage(report))
+		return report, nil
+	}
+
+	if err := checkInterrupted(); err != nil {
+		return report, err
+	}
+	logln("📤 Pushing...")
+	clock.begin("push")
+	pushed, err := pushBranch(cfg, false, opts.ForcePush)
+	clock.end()
+	if err != nil && runCtx.Err() != nil {
+		return report, errInterrupted
+	}
+	if err != nil {
+		return report, withExitCode(exitPushError, fmt.Errorf("pushing: %w", err))
+	}
+	if !pushed {
+		summaryf("\n✨ Done! %s\n", doneMessage(report))
+		return report, nil
+	}
+	report.Pushed = true
+	pushReviewNotes(cfg)
+	runAfterPush(cfg, report)
+
+	if opts.OpenPR {
+		url, err := openPullRequest(cfg, opts.DraftPR)
+		if err != nil {
+			return report, fmt.Errorf("creating pull request: %w", err)
+		}
+		report.PullRequestURL = url
+	}
+	summaryf("\n✨ Done! %s\n", doneMessage(report))
+	return report, nil
+}
+
+// stopOnFailure turns a failed check or command into the error that stops
+// the run, unless --force lets it continue
+func stopOnFailure(opts commitOptions, report *commitReport, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, errInterrupted):
+		return err
+	case !opts.Force:
+		report.Blocked = true
+		return withExitCode(exitBlocked, fmt.Errorf("%w (use --force or -f to commit anyway)", err))
+	}
+	logf("\n⚠️  %v; continuing because of --force.\n", err)
+	return nil
+}
+
+// reviewAndMessage asks Claude for findings and a commit message, passing
+// along hint (extra guidance typed in the plan menu). With --message, the
+// given message is used as is and Claude is only called when --review asks
+// for the findings.
+func reviewAndMessage(cfg *config.Config, opts commitOptions, changes *changeSet, hint string) (*claude.Result, error) {
+	if opts.Message != "" {
+		result := &claude.Result{Message: opts.Message}
+		if !opts.Review {
+			return result, nil
+		}
+
+		stopSpinner := startSpinner("🤖 Claude is reviewing your changes", changes.spinnerDetail())
+		review, err := claude.ReviewChanges(runCtx, changes.Diff, cfg.Model, changes.UseSummaryMode)
+		stopSpinner()
+		if err != nil {
+			return nil, err
+		}
+		result.Findings = review.Findings
+		return result, nil
+	}
+
+	format := messageFormat(cfg, hint)
+	format.Type = opts.Type
+	stopSpinner := startSpinner("🤖 Claude is reviewing your changes", changes.spinnerDetail())
+	defer stopSpinner()
+
+	return claude.ReviewAndCommitMessage(runCtx, changes.Diff, cfg.Model, changes.UseSummaryMode, format, nil)
+}
+
+// unstageInterrupted puts the index back as it was before staging, so a
+// run stopped with Ctrl+C leaves nothing half-done
+func unstageInterrupted(indexTree string) error {
+	if indexTree == "" {
+		return errInterrupted
+	}
+	// The run is canceled, but the index still has to be put back
+	if err := git.ReadTree(context.WithoutCancel(runCtx), indexTree); err != nil {
+		return fmt.Errorf("restoring the index after Ctrl+C: %w", err)
+	}
+	return errInterrupted
+}
+
+// doneMessage sums up what happened to the changes
+func doneMessage(report *commitReport) string {
+	switch {
+	case report.Offline && report.Pushed:
+		return i18n.T("Your changes have been committed and pushed, without an AI review.")
+	case report.Offline:
+		return i18n.T("Your changes have been committed (not pushed), without an AI review.")
+	case report.Pushed:
+		return i18n.T("Your changes have been reviewed, committed, and pushed.")
+	}
+	return i18n.T("Your changes have been reviewed and committed (not pushed).")
+}
+
+// offlineFallback reports whether cc commits with a rule-based message when
+// Claude can't be reached. Without a terminal nobody sees that the changes
+// went unreviewed, so that takes offline_fallback.
+func offlineFallback(cfg *config.Config) bool {
+	if cfg.NoOfflineFallback {
+		return false
+	}
+	return interactive() || cfg.OfflineFallback
+}
+
+// offlineResult stands in for Claude when it can't be reached: a rule-based
+// message from the changed files (or the --message one), and no findings
+func offlineResult(opts commitOptions, cause error) (*claude.Result, error) {
+	eprintf("\n⚠️  Claude is unavailable: %s\n", firstLine(cause.Error()))
+	eprintf("🧰 Falling back to a rule-based message. These changes were NOT reviewed by AI.\n")
+	if opts.Message != "" {
+		return &claude.Result{Message: opts.Message}, nil
+	}
+	message, err := ruleBasedMessage()
+	if err != nil {
+		return nil, err
+	}
+	return &claude.Result{Message: message}, nil
+}
+
+// ruleBasedMessage writes a Conventional Commits message from the changed
+// files, without Claude
+func ruleBasedMessage() (string, error) {
+	changes, err := git.GetFileChanges(runCtx)
+	if err != nil {
+		return "", withExitCode(exitGitError, fmt.Errorf("listing changes: %w", err))
+	}
+	return offline.Message(changes), nil
+}
+
+// clipboardText is what --copy puts on the clipboard: the message, followed
+// by the findings with --copy-review
+func clipboardText(message string, findings []claude.Finding, withReview bool) string {
+	if !withReview || len(findings) == 0 {
+		return message
+	}
+	var b strings.Builder
+	b.WriteString(message)
+	b.WriteString("\n\nReview findings:\n")
+	for _, f := range findings {
+		fmt.Fprintf(&b, "- [%s] %s\n", f.Severity, f.Description)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// appendTrailers adds the configured trailers below the message, separated by a blank line
+func appendTrailers(message string, trailers []string) string {
+	if len(trailers) == 0 {
+		return message
+	}
+	return message + "\n\n" + strings.Join(trailers, "\n")
+}
+
+// finishMessage adds the closing reference of the issue being worked on
+// and the trailers to a message. model is empty for messages Claude didn't
+// write, which only get the configured trailers.
+func finishMessage(cfg *config.Config, message string, model string) string {
+	if message == "" {
+		return ""
+	}
+	if model != "" {
+		message = withIssueReference(cfg, message)
+	}
+	return appendTrailers(message, commitTrailers(cfg, model))
+}
+
+// attributionKey is the trailer that names the tool and model behind a
+// generated message; deliberately not Co-Authored-By, which the prompt rules out
+const attributionKey = "Commit-Message-Generated-By"
+
+// commitTrailers returns the configured trailers, with the attribution
+// trailer for model when attribution is on. model is empty for messages
+// Claude didn't write.
+func commitTrailers(cfg *config.Config, model string) []string {
+	if !cfg.Attribution || model == "" {
+		return cfg.Trailers
+	}
+	return append(slices.Clone(cfg.Trailers), attributionTrailer(model))
+}
+
+// attributionTrailer is the attribution trailer for model, e.g.
+// "Commit-Message-Generated-By: claude-commit v1.0.10 (sonnet)"
+func attributionTrailer(model string) string {
+	return fmt.Sprintf("%s: claude-commit %s (%s)", attributionKey, VERSION, model)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/i18n"
+	"github.com/quaywin/claude-commit/internal/network"
+)
+
+// handleConfig manages settings from the command line
+// Usage: cc config [list | get <key> | set <key> <value> | unset <key> | path]
+func handleConfig(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	switch args[0] {
+	case "list":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: cc config list")
+		}
+		for _, key := range config.Keys() {
+			value, _ := config.Get(cfg, key)
+			printf("%s = %s\n", key, shownValue(key, value))
+		}
+		return nil
+
+	case "get":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: cc config get <key>")
+		}
+		value, err := config.Get(cfg, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+
+	case "set", "unset":
+		if args[0] == "set" && len(args) != 3 {
+			return fmt.Errorf("usage: cc config set <key> <value>")
+		}
+		if args[0] == "unset" && len(args) != 2 {
+			return fmt.Errorf("usage: cc config unset <key>")
+		}
+
+		// Start from the saved file so flag overrides of this run aren't persisted
+		saved, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		if args[0] == "set" {
+			err = config.Set(saved, args[1], args[2])
+		} else {
+			err = config.Unset(saved, args[1])
+		}
+		if err != nil {
+			return err
+		}
+		if err := validateConfig(saved); err != nil {
+			return err
+		}
+		if err := config.Save(saved); err != nil {
+			return fmt.Errorf("saving config: %w", err)
+		}
+
+		value, _ := config.Get(saved, args[1])
+		printf("✅ %s = %s\n", args[1], shownValue(args[1], value))
+		return nil
+
+	case "path":
+		configDir, err := config.GetConfigDir()
+		if err != nil {
+			return err
+		}
+		fmt.Println(filepath.Join(configDir, config.ConfigFileName))
+		return nil
+	}
+
+	return fmt.Errorf("unknown config action %q (use list, get, set, unset, or path)", args[0])
+}
+
+// validateConfig rejects settings that would fail later in the pipeline
+func validateConfig(cfg *config.Config) error {
+	if cfg.BlockSeverity != "" {
+		if _, err := claude.ParseSeverity(cfg.BlockSeverity); err != nil {
+			return fmt.Errorf("block_severity: %w", err)
+		}
+	}
+	if _, ok := cfg.Profiles[cfg.Profile]; cfg.Profile != "" && !ok {
+		return fmt.Errorf("profile: no profile named %q in the config", cfg.Profile)
+	}
+	if _, _, _, _, err := cfg.Timeouts.Durations(); err != nil {
+		return err
+	}
+	if _, err := cfg.LargeFileBytes(); err != nil {
+		return err
+	}
+	if _, err := cfg.Watch.QuietPeriodDuration(); err != nil {
+		return err
+	}
+	if _, err := cfg.ContextLines(); err != nil {
+		return err
+	}
+	if _, err := cfg.Models.SmallLineCount(); err != nil {
+		return err
+	}
+	if _, err := cfg.SummaryTokenLimit(); err != nil {
+		return err
+	}
+	if _, err := cfg.SummaryFiles(); err != nil {
+		return err
+	}
+	if _, err := cfg.FileContext.LineLimit(); err != nil {
+		return err
+	}
+	if _, err := cfg.FileContext.TokenLimit(); err != nil {
+		return err
+	}
+	if _, err := cfg.Examples.CountValue(); err != nil {
+		return err
+	}
+	if _, err := regexp.Compile(cfg.Examples.Match); err != nil {
+		return fmt.Errorf("examples.match: %w", err)
+	}
+	if _, err := compileRewrites(cfg.Rewrites); err != nil {
+		return err
+	}
+	if err := validateTestPolicy(cfg.TestsRequired); err != nil {
+		return err
+	}
+	if err := validateChecks(cfg.Checks); err != nil {
+		return err
+	}
+	if _, err := debugPatterns(cfg); err != nil {
+		return err
+	}
+	if _, err := collapsePatterns(cfg); err != nil {
+		return err
+	}
+	switch cfg.Issues.Provider {
+	case "", issueGitHub, issueLinear:
+	default:
+		return fmt.Errorf("issues.provider: unknown provider %q (use github or linear)", cfg.Issues.Provider)
+	}
+	switch cfg.Todos.Action {
+	case "", "block":
+	default:
+		return fmt.Errorf("todos.action: unknown action %q (use block, or leave it empty to only list them)", cfg.Todos.Action)
+	}
+	if _, err := cfg.CostLimitUSD(); err != nil {
+		return err
+	}
+	switch cfg.CostLimitAction {
+	case "", "ask", "summary":
+	default:
+		return fmt.Errorf("cost_limit_action: unknown action %q (use ask or summary)", cfg.CostLimitAction)
+	}
+	if cfg.Network.CABundle != "" {
+		if _, err := network.LoadCABundle(cfg.Network.CABundle); err != nil {
+			return err
+		}
+	}
+	if err := i18n.Validate(cfg.UILanguage); err != nil {
+		return err
+	}
+	switch cfg.UpdateChannel {
+	case "", "stable", "beta":
+	default:
+		return fmt.Errorf("update_channel: unknown channel %q (use stable or beta)", cfg.UpdateChannel)
+	}
+	switch cfg.GitBackend {
+	case "", "exec", "go-git":
+	default:
+		return fmt.Errorf("git_backend: unknown backend %q (use exec or go-git)", cfg.GitBackend)
+	}
+	return nil
+}
+
+// shownValue hides the value of secret keys; only an explicit
+// `cc config get` prints a token
+func shownValue(key, value string) string {
+	if value != "" && config.IsSecret(key) {
+		return "<hidden>"
+	}
+	return value
+}
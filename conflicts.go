@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// conflictMarker is a leftover conflict marker in a changed file
+type conflictMarker struct {
+	Path string
+	Line int
+}
+
+// errConflictMarkers stops a commit of unresolved conflicts, even with --force
+var errConflictMarkers = withExitCode(exitBlocked, errors.New("unresolved merge conflicts"))
+
+// findConflictMarkers returns the first conflict marker the changes add to
+// each file. Like `git diff --check`, only added lines count, so files that
+// already contain marker-like lines (docs, test fixtures) don't block.
+func findConflictMarkers(lines []diffLine) []conflictMarker {
+	var markers []conflictMarker
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		if !line.Added || seen[line.Path] || !isConflictMarker(line.Text) {
+			continue
+		}
+		seen[line.Path] = true
+		markers = append(markers, conflictMarker{Path: line.Path, Line: line.Line})
+	}
+	return markers
+}
+
+// isConflictMarker reports whether line is a "<<<<<<<" or ">>>>>>>" marker
+// git writes into a conflicted file
+func isConflictMarker(line string) bool {
+	line = strings.TrimRight(line, "\r")
+	for _, marker := range []string{"<<<<<<<", ">>>>>>>"} {
+		if line == marker || strings.HasPrefix(line, marker+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// reportConflictMarkers lists the files with conflict markers
+func reportConflictMarkers(markers []conflictMarker) {
+	summaryf("\n🛑 Unresolved merge conflicts in %d file(s):\n", len(markers))
+	for _, m := range markers {
+		summaryf("   %s:%d\n", m.Path, m.Line)
+	}
+	summaryf("Resolve them and remove the conflict markers before committing.\n")
+}
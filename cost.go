@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/debuglog"
+)
+
+// Rough sizes for estimating a review before it runs: about four characters
+// per token, plus the instructions around the diff and the answer
+const (
+	charsPerToken        = 4
+	promptOverheadTokens = 400
+	answerTokens         = 500
+)
+
+// estimatePrompt estimates the tokens of a review prompt around diff and the
+// cost of the call with model (zero for models without a known price)
+func estimatePrompt(model string, diff string) (tokens int, cost float64) {
+	tokens = len(diff)/charsPerToken + promptOverheadTokens
+	cost, _ = estimateCost(model, claude.Usage{InputTokens: tokens, OutputTokens: answerTokens})
+	return tokens, cost
+}
+
+// checkCost compares the estimated cost of the review with cost_limit. Above
+// it, cc asks whether to continue, switch to summary mode, or quit; with
+// cost_limit_action "summary", or without anyone to ask, it switches to
+// summary mode right away. It reports whether the user quit.
+func checkCost(cfg *config.Config, changes *changeSet) (bool, error) {
+	limit, err := cfg.CostLimitUSD()
+	if err != nil || limit == 0 {
+		return false, err
+	}
+	tokens, cost := estimatePrompt(cfg.Model, changes.Diff)
+	debuglog.Log("review estimate", "model", cfg.Model, "tokens", tokens, "cost_usd", cost)
+	if cost <= limit {
+		return false, nil
+	}
+
+	eprintf("\n💸 This review would send ~%s tokens to %s (~%s), above cost_limit (%s).\n", formatTokens(tokens), cfg.Model, formatCost(cost, false), formatCost(limit, false))
+	if output.Yes {
+		logf("💸 Continuing anyway (--yes)\n")
+		return false, nil
+	}
+	if cfg.CostLimitAction == "summary" || !interactive() {
+		return false, switchToSummary(cfg, changes)
+	}
+
+	for {
+		if changes.UseSummaryMode {
+			printf("❓ [c]ontinue  [q]uit: ")
+		} else {
+			printf("❓ [c]ontinue  review a [s]ummary instead  [q]uit: ")
+		}
+		choice, err := readLine(stdin)
+		if err != nil {
+			return false, fmt.Errorf("reading input: %w", err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(choice)) {
+		case "c", "continue":
+			return false, nil
+		case "s", "summary":
+			if !changes.UseSummaryMode {
+				return false, switchToSummary(cfg, changes)
+			}
+		case "q", "quit":
+			return true, nil
+		}
+		printLine("Please answer c, s, or q.")
+	}
+}
+
+// switchToSummary has Claude review the stat summary instead of the full diff
+func switchToSummary(cfg *config.Config, changes *changeSet) error {
+	if changes.UseSummaryMode {
+		logf("💸 Already in summary mode; continuing\n")
+		return nil
+	}
+	if err := changes.useSummary(); err != nil {
+		return withExitCode(exitGitError, err)
+	}
+	tokens, cost := estimatePrompt(cfg.Model, changes.Diff)
+	logf("📉 Switched to summary mode: ~%s tokens (~%s)\n", formatTokens(tokens), formatCost(cost, false))
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/config"
+)
+
+// defaultDebugPatterns are the leftover debugging statements looked for in
+// added lines, by file extension
+var defaultDebugPatterns = map[string][]string{
+	"go":   {`\bfmt\.Print(ln|f)?\(`, `^\s*println\(`, `\bspew\.Dump\(`},
+	"js":   jsDebugPatterns,
+	"jsx":  jsDebugPatterns,
+	"mjs":  jsDebugPatterns,
+	"cjs":  jsDebugPatterns,
+	"ts":   jsDebugPatterns,
+	"tsx":  jsDebugPatterns,
+	"vue":  jsDebugPatterns,
+	"py":   {`^\s*breakpoint\(\)`, `\bi?pdb\.set_trace\(\)`, `^\s*import i?pdb\b`},
+	"rb":   {`\bbinding\.(pry|irb)\b`, `^\s*byebug\b`},
+	"php":  {`\b(var_dump|print_r|dd)\(`},
+	"rs":   {`\bdbg!\(`},
+	"java": {`\bSystem\.(out|err)\.print(ln)?\(`, `\.printStackTrace\(\)`},
+	"ex":   {`\bIO\.inspect\b`},
+	"exs":  {`\bIO\.inspect\b`},
+}
+
+var jsDebugPatterns = []string{`\bconsole\.(log|debug|trace|dir)\(`, `^\s*debugger\b`}
+
+// debugPatterns returns the patterns per extension: the built-in ones, with
+// each extension in debug_patterns replacing them (an empty list turns the
+// extension off)
+func debugPatterns(cfg *config.Config) (map[string][]*regexp.Regexp, error) {
+	sources := map[string][]string{}
+	for ext, patterns := range defaultDebugPatterns {
+		sources[ext] = patterns
+	}
+	for ext, patterns := range cfg.DebugPatterns {
+		sources[strings.TrimPrefix(ext, ".")] = patterns
+	}
+
+	compiled := map[string][]*regexp.Regexp{}
+	for ext, patterns := range sources {
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("debug_patterns.%s: %w", ext, err)
+			}
+			compiled[ext] = append(compiled[ext], re)
+		}
+	}
+	return compiled, nil
+}
+
+// findDebugStatements returns the added lines that match the debug
+// patterns of their file's extension
+func findDebugStatements(cfg *config.Config, lines []diffLine) []diffLine {
+	patterns, err := debugPatterns(cfg)
+	if err != nil {
+		logf("⚠️  Skipping the debug statement check: %v\n", err)
+		return nil
+	}
+	var found []diffLine
+	for _, line := range lines {
+		if !line.Added {
+			continue
+		}
+		for _, re := range patterns[strings.TrimPrefix(path.Ext(line.Path), ".")] {
+			if re.MatchString(line.Text) {
+				found = append(found, line)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// warnDebugStatements prints the debugging statements found
+func warnDebugStatements(found []diffLine) {
+	summaryf("\n🐞 %d line(s) look like leftover debugging:\n", len(found))
+	for _, d := range found {
+		summaryf("   %s\n", d)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// demoMode is set by `cc demo` and --demo
+var demoMode bool
+
+// enableDemoMode switches the git and config packages to read-only so that
+// nothing can write, even by mistake. The Before hook calls it before the
+// setup wizard, git init, or a trust prompt could run.
+func enableDemoMode() {
+	git.ReadOnly = true
+	config.ReadOnly = true
+	// The shared rate limit file is left alone too; demo runs back off on
+	// their own
+	claude.RateLimitFile = ""
+}
+
+// showDemoPlan prints what the stage, commit, push, and pull request steps would have done
+func showDemoPlan(cfg *config.Config, changedFiles []string, message string, opts commitOptions) {
+	files := append([]string(nil), changedFiles...)
+	sort.Strings(files)
+
+	logf("\n🚀 [demo] Would stage %d changed files (git add .):\n", len(files))
+	for _, file := range files {
+		logf("   %s\n", file)
+	}
+
+	logln("💾 [demo] Would commit with the message above (git commit -m ...)")
+
+	if opts.NoPush {
+		logln("📤 [demo] Would not push (--no-push)")
+	} else {
+		branch := "HEAD"
+		if t, err := resolvePushTarget(cfg); err == nil {
+			branch = t.Local
+			if cfg.PullBeforePush && !opts.ForcePush {
+				logf("🔄 [demo] Would fetch %s and rebase onto %s\n", t.Remote, t)
+			}
+			switch {
+			case t.Plain:
+				logf("📤 [demo] Would push %s to %s (git push)\n", branch, git.GetUpstream(runCtx))
+			case t.SetUpstream:
+				logf("📤 [demo] Would push %s and set its upstream to %s (git push --set-upstream %s %s)\n", branch, t, t.Remote, branch)
+			default:
+				logf("📤 [demo] Would push %s to %s (git push %s HEAD:%s)\n", branch, t, t.Remote, t.Branch)
+			}
+			if opts.ForcePush {
+				logf("⚠️  [demo] The push would use --force-with-lease\n")
+			}
+		}
+
+		if opts.OpenPR {
+			base := cfg.PullRequest.Base
+			if base == "" {
+				base = git.GetDefaultBranch(runCtx)
+			}
+			logf("🔀 [demo] Would open a pull request from %s into %s\n", branch, base)
+		}
+	}
+
+	logln("\n✨ Demo complete. Nothing was staged, committed, or pushed.")
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/clipboard"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// handleDescribe writes a new message for an existing commit from its diff,
+// and with amend replaces the message of HEAD with it
+func handleDescribe(cfg *config.Config, rev string, amend bool, copyToClipboard bool) error {
+	from, sha, err := resolveRange(rev)
+	if err != nil {
+		return err
+	}
+	if amend && sha != git.ResolveCommit(runCtx, "HEAD") {
+		return fmt.Errorf("--amend only works for the HEAD commit; reword older commits with 'git rebase -i'")
+	}
+	if amend && !interactive() && !output.Yes {
+		return withExitCode(exitAborted, fmt.Errorf("amending rewrites the commit; rerun with --yes to confirm without a terminal"))
+	}
+
+	files, err := git.GetRangeChangedFiles(runCtx, from, sha)
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("getting changed files: %w", err))
+	}
+	if len(files) == 0 {
+		return withExitCode(exitNoChanges, fmt.Errorf("%s changes no files", rev))
+	}
+	diff, mode, err := git.ChooseDiff(len(files), func(summary bool) (string, error) {
+		return git.GetRangeDiff(runCtx, from, sha, summary)
+	})
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("getting diff: %w", err))
+	}
+	original, err := git.GetCommitMessage(runCtx, sha)
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("reading the commit message: %w", err))
+	}
+
+	hint := fmt.Sprintf("this commit already exists; its current message is %q. Keep the details from it that the diff confirms.", original)
+	format := messageFormat(cfg, hint)
+	stopSpinner := startSpinner("🤖 Claude is describing "+shortSHA(sha), fmt.Sprintf(" (%d files)", len(files)))
+	result, err := claude.ReviewAndCommitMessage(runCtx, diff, modelFor(cfg, taskMessage), mode == git.SummaryDiff, format, nil)
+	stopSpinner()
+	if err != nil {
+		return withExitCode(exitModelErr, fmt.Errorf("calling Claude: %w", err))
+	}
+	if result.Message == "" {
+		printFindings(result.Findings)
+		return withExitCode(exitModelErr, fmt.Errorf("Claude reported a critical finding instead of a commit message"))
+	}
+	// Signed-off-by and other trailers still apply to the same change, but
+	// an attribution names whoever wrote the new message
+	trailers := slices.DeleteFunc(messageTrailers(original), func(line string) bool {
+		return strings.HasPrefix(line, attributionKey+":")
+	})
+	if cfg.Attribution {
+		trailers = append(trailers, attributionTrailer(claude.LastModel()))
+	}
+	message := appendTrailers(result.Message, trailers)
+	summaryf("\n📝 Commit message for %s:\n%s\n", shortSHA(sha), message)
+
+	if copyToClipboard {
+		if err := clipboard.Copy(message); err != nil {
+			return fmt.Errorf("copying to clipboard: %w", err)
+		}
+		logf("📋 Message copied to clipboard.\n")
+	}
+	if !amend {
+		return nil
+	}
+
+	if unpushed, err := git.GetUnpushedCommits(runCtx); err == nil && !slices.Contains(unpushed, sha) {
+		logf("\n⚠️  %s is already pushed; after amending, push with --force-push.\n", shortSHA(sha))
+	}
+	ok, err := confirm("Amend HEAD with this message?")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		summaryf("❌ Aborted. The commit was left as it was.\n")
+		exit(exitAborted)
+	}
+	if err := git.AmendMessage(runCtx, message); err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("amending the commit: %w", err))
+	}
+	summaryf("✅ Amended %s.\n", shortSHA(git.ResolveCommit(runCtx, "HEAD")))
+	return nil
+}
+
+// trailerLine matches a git trailer such as "Signed-off-by: Jane <jane@example.com>"
+var trailerLine = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*: \S`)
+
+// messageTrailers returns the trailers in the last paragraph of message
+func messageTrailers(message string) []string {
+	paragraphs := strings.Split(strings.TrimSpace(message), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+	lines := strings.Split(paragraphs[len(paragraphs)-1], "\n")
+	for _, line := range lines {
+		if !trailerLine.MatchString(line) {
+			return nil
+		}
+	}
+	return lines
+}
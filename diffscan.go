@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/debuglog"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// diffLine is a line added or removed by a diff
+type diffLine struct {
+	Path  string
+	Line  int // In the new file for added lines, in the old one for removed lines
+	Text  string
+	Added bool
+}
+
+func (d diffLine) String() string {
+	return fmt.Sprintf("%s:%d: %s", d.Path, d.Line, strings.TrimSpace(d.Text))
+}
+
+// hunkHeader captures the first line numbers of a hunk in the old and new file
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)`)
+
+// changedLines returns the added and removed lines of a unified diff
+func changedLines(diff string) []diffLine {
+	var lines []diffLine
+	var oldFile, newFile string
+	oldLine, newLine := 0, 0
+	for _, text := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(text, "--- "):
+			oldFile = strings.TrimPrefix(strings.TrimPrefix(text, "--- "), "a/")
+		case strings.HasPrefix(text, "+++ "):
+			newFile = strings.TrimPrefix(strings.TrimPrefix(text, "+++ "), "b/")
+		case strings.HasPrefix(text, "@@"):
+			if m := hunkHeader.FindStringSubmatch(text); m != nil {
+				oldLine, _ = strconv.Atoi(m[1])
+				newLine, _ = strconv.Atoi(m[2])
+			}
+		case strings.HasPrefix(text, "+"):
+			lines = append(lines, diffLine{Path: newFile, Line: newLine, Text: text[1:], Added: true})
+			newLine++
+		case strings.HasPrefix(text, "-"):
+			lines = append(lines, diffLine{Path: oldFile, Line: oldLine, Text: text[1:]})
+			oldLine++
+		case strings.HasPrefix(text, " "):
+			oldLine++
+			newLine++
+		}
+	}
+	return lines
+}
+
+// fullDiff returns the diff of changes with every line, fetching it when
+// the review only gets a stat summary. Local checks use it.
+func fullDiff(changes *changeSet) string {
+	if changes.mode() == "full" {
+		return changes.Diff
+	}
+	diff, err := git.GetDiff(runCtx)
+	if err != nil {
+		debuglog.Log("getting the full diff for local checks", "error", err)
+		return ""
+	}
+	return diff
+}
+
+// committedLines returns the added and removed lines of everything that
+// gets committed, including the files the review leaves out (ignore,
+// collapsed lockfiles and generated files)
+func committedLines() []diffLine {
+	diff, err := git.GetCommittedDiff(runCtx)
+	if err != nil {
+		debuglog.Log("getting the committed diff for local checks", "error", err)
+		return nil
+	}
+	return changedLines(diff)
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// doctorTimeout bounds the checks that go over the network
+const doctorTimeout = 30 * time.Second
+
+// checkStatus is the outcome of one doctor check
+type checkStatus int
+
+const (
+	checkPass checkStatus = iota
+	checkWarn
+	checkFail
+)
+
+var checkIcons = map[checkStatus]string{checkPass: "✅", checkWarn: "⚠️ ", checkFail: "❌"}
+
+// checkResult is one line of the doctor report, with a hint on how to fix it
+type checkResult struct {
+	Name   string
+	Status checkStatus
+	Detail string
+	Hint   string
+}
+
+// handleDoctor checks the environment cc depends on and prints each result
+// with a hint on how to fix it. With offline, the checks that contact
+// Claude or the remote are skipped. It fails if any check failed.
+func handleDoctor(cfg *config.Config, offline bool) error {
+	printf("🩺 Checking your cc setup...\n")
+	fmt.Println()
+
+	var results []checkResult
+	report := func(r checkResult) {
+		results = append(results, r)
+		printf("%s %s: %s\n", checkIcons[r.Status], r.Name, r.Detail)
+		if r.Hint != "" && r.Status != checkPass {
+			printf("   💡 %s\n", r.Hint)
+		}
+	}
+
+	gitOK := checkGit(cfg, report)
+	if checkClaudeCLI(report) && !offline {
+		checkClaudeAuth(cfg, report)
+	}
+	inRepo := false
+	if gitOK {
+		inRepo, _ = git.IsInsideWorkTree(runCtx)
+	}
+	checkConfig(cfg, inRepo, report)
+	if inRepo {
+		if !offline {
+			checkRemote(cfg, report)
+		}
+		checkHooks(report)
+	} else {
+		report(checkResult{Name: "repository", Status: checkWarn, Detail: "not inside a git repository, so the remote and hooks weren't checked", Hint: "run cc doctor inside a repository to check them"})
+	}
+
+	failed, warned := 0, 0
+	for _, r := range results {
+		switch r.Status {
+		case checkFail:
+			failed++
+		case checkWarn:
+			warned++
+		}
+	}
+	fmt.Println()
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed, %d warning(s)", failed, warned)
+	}
+	if warned > 0 {
+		printf("✨ No problems found (%d warning(s)).\n", warned)
+	} else {
+		printf("✨ Everything looks good!\n")
+	}
+	return nil
+}
+
+// checkGit reports the git version and whether git can run at all
+func checkGit(cfg *config.Config, report func(checkResult)) bool {
+	if _, err := exec.LookPath("git"); err != nil {
+		status := checkFail
+		if cfg.GitBackend == "go-git" {
+			status = checkWarn // Commits still work without the binary
+		}
+		report(checkResult{Name: "git", Status: status, Detail: "not found in your PATH", Hint: "install git from https://git-scm.com"})
+		return false
+	}
+	version, err := git.Version(runCtx)
+	if err != nil {
+		report(checkResult{Name: "git", Status: checkFail, Detail: err.Error(), Hint: "check that git runs in this shell: git --version"})
+		return false
+	}
+	report(checkResult{Name: "git", Status: checkPass, Detail: strings.TrimPrefix(version, "git version ")})
+	return true
+}
+
+// checkClaudeCLI reports whether the claude CLI is installed
+func checkClaudeCLI(report func(checkResult)) bool {
+	hint := "install it with 'npm install -g @anthropic-ai/claude-code' (see https://github.com/anthropics/claude-code)"
+	if _, err := exec.LookPath("claude"); err != nil {
+		report(checkResult{Name: "claude CLI", Status: checkFail, Detail: "not found in your PATH", Hint: hint})
+		return false
+	}
+	version, err := claude.Version(runCtx)
+	if err != nil {
+		report(checkResult{Name: "claude CLI", Status: checkFail, Detail: fmt.Sprintf("claude --version failed: %v", err), Hint: "reinstall it: " + hint})
+		return false
+	}
+	report(checkResult{Name: "claude CLI", Status: checkPass, Detail: firstLine(version)})
+	return true
+}
+
+// checkClaudeAuth sends the configured model a tiny prompt, which fails
+// when the CLI isn't logged in or the API key is invalid
+func checkClaudeAuth(cfg *config.Config, report func(checkResult)) {
+	if claude.Timeout == 0 || claude.Timeout > doctorTimeout {
+		claude.Timeout = doctorTimeout
+	}
+	credentials := "the claude login"
+	if os.Getenv("ANTHROPIC_API_KEY") != "" {
+		credentials = "ANTHROPIC_API_KEY"
+	}
+	stop := startSpinner("🤖 Asking "+cfg.Model+" for a reply", "")
+	err := claude.Ping(runCtx, cfg.Model)
+	stop()
+	if err != nil {
+		hint := "run 'claude' once to log in, or set ANTHROPIC_API_KEY"
+		if credentials == "ANTHROPIC_API_KEY" {
+			hint = "check that ANTHROPIC_API_KEY is a valid key, or unset it to use the claude login"
+		}
+		report(checkResult{Name: "claude auth", Status: checkFail, Detail: fmt.Sprintf("%s didn't answer: %v", cfg.Model, strings.TrimSpace(err.Error())), Hint: hint + "; also check the model name with 'cc models'"})
+		return
+	}
+	report(checkResult{Name: "claude auth", Status: checkPass, Detail: fmt.Sprintf("%s answered, using %s", cfg.Model, credentials)})
+}
+
+// checkConfig loads the global and repository config and validates the
+// merged settings
+func checkConfig(cfg *config.Config, inRepo bool, report func(checkResult)) {
+	path := "the config file"
+	if dir, err := config.GetConfigDir(); err == nil {
+		path = filepath.Join(dir, config.ConfigFileName)
+	}
+	if _, err := config.Load(); err != nil {
+		report(checkResult{Name: "config", Status: checkFail, Detail: fmt.Sprintf("%s can't be read: %v", path, err), Hint: "fix the JSON by hand, or move it away and run 'cc setup'"})
+		return
+	}
+
+	merged := *cfg
+	source := path + " is"
+	if inRepo {
+		if err := applyRepoConfig(&merged); err != nil {
+			report(checkResult{Name: "config", Status: checkFail, Detail: fmt.Sprintf("repository settings: %v", err), Hint: "fix .claude-commit.json or the [claude-commit] section of git config"})
+			return
+		}
+		source = path + " and the repository settings are"
+	}
+	if err := validateConfig(&merged); err != nil {
+		report(checkResult{Name: "config", Status: checkFail, Detail: err.Error(), Hint: "change it with 'cc config set <key> <value>' or 'cc config unset <key>'"})
+		return
+	}
+	if !config.Exists() {
+		report(checkResult{Name: "config", Status: checkPass, Detail: "using the defaults (no config file yet)"})
+		return
+	}
+	report(checkResult{Name: "config", Status: checkPass, Detail: source + " valid"})
+}
+
+// checkRemote checks that the remote cc pushes to can be reached with the
+// current credentials
+func checkRemote(cfg *config.Config, report func(checkResult)) {
+	remote := cfg.PushRemote
+	if t, err := resolvePushTarget(cfg); err == nil {
+		remote = t.Remote
+	} else if remote == "" {
+		remote = "origin"
+	}
+	url, err := git.GetRemoteURL(runCtx, remote)
+	if err != nil {
+		report(checkResult{Name: "remote", Status: checkWarn, Detail: fmt.Sprintf("no remote named %s, so pushing will fail", remote), Hint: "add one with 'git remote add " + remote + " <url>', or set no_push"})
+		return
+	}
+	stop := startSpinner("🌐 Contacting "+remote, "")
+	err = git.CheckRemote(runCtx, remote, doctorTimeout)
+	stop()
+	if err != nil {
+		var timeout *git.TimeoutError
+		detail := fmt.Sprintf("%s (%s) can't be reached: %s", remote, url, gitErrorLine(err))
+		if errors.As(err, &timeout) {
+			detail = fmt.Sprintf("%s (%s) didn't answer within %s", remote, url, doctorTimeout)
+		}
+		report(checkResult{Name: "remote", Status: checkFail, Detail: detail, Hint: "check your network and credentials with 'git ls-remote " + remote + "'"})
+		return
+	}
+	report(checkResult{Name: "remote", Status: checkPass, Detail: fmt.Sprintf("%s (%s) is reachable", remote, url)})
+}
+
+// gitErrorLine returns the first line git printed to stderr for err
+func gitErrorLine(err error) string {
+	msg := err.Error()
+	if _, stderr, ok := strings.Cut(msg, "stderr: "); ok && strings.TrimSpace(stderr) != "" {
+		msg = stderr
+	}
+	return firstLine(strings.TrimSpace(msg))
+}
+
+// checkHooks reports the hooks cc installed, and whether they still point
+// at a cc binary that exists
+func checkHooks(report func(checkResult)) {
+	dir, err := git.GetHooksDir(runCtx)
+	if err != nil {
+		report(checkResult{Name: "hooks", Status: checkWarn, Detail: fmt.Sprintf("can't find the hooks directory: %v", err)})
+		return
+	}
+	var installed []string
+	for _, name := range []string{"prepare-commit-msg", "pre-push", "commit-msg"} {
+		script, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || !strings.Contains(string(script), hookMarker) {
+			continue
+		}
+		flag := map[string]string{"pre-push": " --pre-push", "commit-msg": " --commit-msg"}[name]
+		if exe := hookExecutable(string(script)); exe != "" {
+			if _, err := os.Stat(exe); err != nil {
+				report(checkResult{Name: "hooks", Status: checkFail, Detail: fmt.Sprintf("the %s hook runs %s, which no longer exists", name, exe), Hint: "reinstall it with 'cc hook install" + flag + " --force'"})
+				continue
+			}
+		}
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && info.Mode()&0111 == 0 {
+			report(checkResult{Name: "hooks", Status: checkFail, Detail: fmt.Sprintf("the %s hook isn't executable, so git skips it", name), Hint: "reinstall it with 'cc hook install" + flag + " --force'"})
+			continue
+		}
+		installed = append(installed, name)
+	}
+	if len(installed) == 0 {
+		report(checkResult{Name: "hooks", Status: checkPass, Detail: "none installed (optional, see 'cc hook --help')"})
+		return
+	}
+	report(checkResult{Name: "hooks", Status: checkPass, Detail: strings.Join(installed, ", ") + " installed"})
+}
+
+// hookExecutable returns the cc binary a hook script written by
+// handleHookInstall runs
+func hookExecutable(script string) string {
+	for _, line := range strings.Split(script, "\n") {
+		rest, ok := strings.CutPrefix(line, "exec '")
+		if !ok {
+			continue
+		}
+		end := strings.LastIndex(rest, "' hook ")
+		if end < 0 {
+			return ""
+		}
+		return strings.ReplaceAll(rest[:end], `'\''`, "'")
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// editMessage opens message in the editor git uses for commit messages and
+// returns the saved text without comment lines. An empty result means the
+// user wants to abort, like with `git commit`.
+func editMessage(message string) (string, error) {
+	editor, err := git.GetEditor(runCtx)
+	if err != nil {
+		return "", fmt.Errorf("finding editor: %w", err)
+	}
+
+	file, err := os.CreateTemp("", "cc-COMMIT_EDITMSG-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	content := message + "\n\n# Edit the commit message above. Lines starting with '#' are ignored,\n# and an empty message aborts the commit.\n"
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	// Run through the shell like git does, so editors with arguments (e.g. "code --wait") work
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, file.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("running editor %q: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return stripComments(string(edited)), nil
+}
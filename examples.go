@@ -0,0 +1,144 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/conventions"
+	"github.com/quaywin/claude-commit/internal/debuglog"
+	"github.com/quaywin/claude-commit/internal/git"
+	"github.com/quaywin/claude-commit/internal/repomap"
+)
+
+// messageFormat builds the message format from the config and the project's
+// conventions, with the repository's recent subjects as examples and hint as
+// extra guidance
+func messageFormat(cfg *config.Config, hint string) claude.MessageFormat {
+	format := claude.MessageFormat{
+		Body:            cfg.MessageBody,
+		SubjectLanguage: cfg.SubjectLanguage,
+		BodyLanguage:    cfg.BodyLanguage,
+		Hint:            hint,
+		Examples:        styleExamples(cfg),
+	}
+	if rewrites, err := compileRewrites(cfg.Rewrites); err == nil {
+		format.Rewrites = rewrites
+	} else {
+		logf("⚠️  Skipping the rewrite rules: %v\n", err)
+	}
+	if found := projectConventions(cfg); found != nil {
+		format.Types = found.Types
+		format.Scopes = found.Scopes
+		format.SubjectMaxLength = found.MaxHeaderLength
+		format.Conventions = found.Guidance
+	}
+	if issue := currentIssue(cfg); issue != nil {
+		format.Issue = issue.promptContext()
+	}
+	if cfg.RepoMap {
+		format.RepoMap = buildRepoMap()
+	}
+	return format
+}
+
+// repoMap caches the outline of the repository for the run
+var repoMap *string
+
+// buildRepoMap outlines the repository for the prompt. Any problem just
+// means no outline.
+func buildRepoMap() string {
+	if repoMap == nil {
+		outline := ""
+		root, err := git.GetRepoRoot(runCtx)
+		if err == nil {
+			var files []string
+			if files, err = git.GetTrackedFiles(runCtx); err == nil {
+				outline = repomap.Build(root, files)
+			}
+		}
+		if err != nil {
+			debuglog.Log("building the repo map", "error", err)
+		}
+		repoMap = &outline
+	}
+	return *repoMap
+}
+
+// detectedConventions caches the project's conventions for the run
+var detectedConventions *conventions.Conventions
+
+// projectConventions returns the commit conventions documented in the
+// repository, or nil when there are none or ignore_conventions is set
+func projectConventions(cfg *config.Config) *conventions.Conventions {
+	if cfg.IgnoreConventions {
+		return nil
+	}
+	if detectedConventions == nil {
+		root, err := git.GetRepoRoot(runCtx)
+		if err != nil {
+			return nil
+		}
+		detectedConventions = conventions.Detect(root, git.GetConfigValue(runCtx, "commit.template"))
+		if !detectedConventions.Empty() {
+			logf("📏 Following the commit conventions in %s\n", strings.Join(detectedConventions.Sources, ", "))
+		}
+	}
+	if detectedConventions.Empty() {
+		return nil
+	}
+	return detectedConventions
+}
+
+// styleExamples returns up to examples.count recent commit subjects that
+// are worth imitating. Any problem just means no examples.
+func styleExamples(cfg *config.Config) []string {
+	count, err := cfg.Examples.CountValue()
+	if err != nil || count == 0 {
+		return nil
+	}
+	var match *regexp.Regexp
+	if cfg.Examples.Match != "" {
+		if match, err = regexp.Compile(cfg.Examples.Match); err != nil {
+			return nil
+		}
+	}
+
+	// Look further back than count, since some subjects get filtered out
+	subjects, err := git.GetRecentSubjects(runCtx, count*5)
+	if err != nil {
+		debuglog.Log("reading recent commit subjects", "error", err)
+		return nil
+	}
+	var examples []string
+	seen := map[string]bool{}
+	for _, subject := range subjects {
+		subject = strings.TrimSpace(subject)
+		if seen[subject] || !goodExample(subject) || (match != nil && !match.MatchString(subject)) {
+			continue
+		}
+		seen[subject] = true
+		examples = append(examples, subject)
+		if len(examples) == count {
+			break
+		}
+	}
+	debuglog.Log("style examples", "count", len(examples))
+	return examples
+}
+
+// goodExample reports whether a subject shows how the project writes
+// messages, rather than being generated by git or a throwaway checkpoint
+func goodExample(subject string) bool {
+	length := len([]rune(subject))
+	if length < 10 || length > claude.MaxSubjectLength {
+		return false
+	}
+	for _, prefix := range []string{"Merge ", "Revert ", "fixup! ", "squash! ", "amend! "} {
+		if strings.HasPrefix(subject, prefix) {
+			return false
+		}
+	}
+	return !isWIP(subject)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// Exit codes let scripts wrapping cc tell failure classes apart.
+// Keep this list in sync with the README.
+const (
+	exitOK        = 0 // Changes reviewed, committed, and pushed (or the command succeeded)
+	exitError     = 1 // Usage, config, or other unexpected errors
+	exitNoChanges = 2 // Nothing to commit or review
+	exitBlocked   = 3 // Claude reported findings at or above block_severity
+	exitGitError  = 4 // A git command failed (diff, stage, commit)
+	exitModelErr  = 5 // The model call failed or returned an unusable answer
+	exitPushError = 6 // git push failed; the commit was created locally
+	exitAborted   = 7 // The user declined the confirmation prompt
+
+	exitInterrupted = 130 // Stopped with Ctrl+C; the shell convention for SIGINT
+)
+
+// exitCodeError attaches an exit code to an error
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// withExitCode wraps err so that exitCodeOf returns code for it
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitCodeError{code: code, err: err}
+}
+
+// exitCodeOf returns the exit code attached to err, or exitError. Errors
+// caused by Ctrl+C, such as a killed claude call, get exitInterrupted.
+func exitCodeOf(err error) int {
+	if runCtx.Err() != nil && errors.Is(err, context.Canceled) {
+		return exitInterrupted
+	}
+	var codeErr *exitCodeError
+	if errors.As(err, &codeErr) {
+		return codeErr.code
+	}
+	return exitError
+}
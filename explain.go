@@ -0,0 +1,110 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// handleExplain explains the uncommitted changes, a commit, or a range of
+// commits ("from..to") in plain language, without touching the repository
+func handleExplain(cfg *config.Config, rev string) error {
+	var diff, log, detail string
+	summary := false
+
+	if rev == "" {
+		logln("🔍 Checking for changes...")
+		changes, err := collectChanges()
+		if err != nil {
+			return withExitCode(exitGitError, err)
+		}
+		if len(changes.Files) == 0 || changes.Diff == "" {
+			return withExitCode(exitNoChanges, errors.New("no uncommitted changes to explain (name a commit or a from..to range)"))
+		}
+		diff, summary, detail = changes.Diff, changes.UseSummaryMode, changes.spinnerDetail()
+	} else {
+		from, to, err := resolveRange(rev)
+		if err != nil {
+			return err
+		}
+		files, err := git.GetRangeChangedFiles(runCtx, from, to)
+		if err != nil {
+			return withExitCode(exitGitError, fmt.Errorf("getting changed files: %w", err))
+		}
+		if len(files) == 0 {
+			return withExitCode(exitNoChanges, fmt.Errorf("%s changes no files", rev))
+		}
+		var mode git.DiffMode
+		diff, mode, err = git.ChooseDiff(len(files), func(summary bool) (string, error) {
+			return git.GetRangeDiff(runCtx, from, to, summary)
+		})
+		if err != nil {
+			return withExitCode(exitGitError, fmt.Errorf("getting diff: %w", err))
+		}
+		if log, err = git.GetRangeLog(runCtx, from, to); err != nil {
+			return withExitCode(exitGitError, fmt.Errorf("getting commit log: %w", err))
+		}
+		summary = mode == git.SummaryDiff
+		detail = fmt.Sprintf(" (%d files)", len(files))
+	}
+
+	stopSpinner := startSpinner("🤖 Claude is reading the changes", detail)
+	explanation, err := claude.ExplainChanges(runCtx, diff, log, modelFor(cfg, taskReview), summary)
+	stopSpinner()
+	if err != nil {
+		return withExitCode(exitModelErr, fmt.Errorf("calling Claude: %w", err))
+	}
+
+	if output.JSON {
+		printJSON(explanation)
+		return nil
+	}
+	printf("\n📖 What changed:\n%s\n", indent(explanation.What))
+	if explanation.Why != "" {
+		printf("\n💭 Why:\n%s\n", indent(explanation.Why))
+	}
+	if len(explanation.Risks) == 0 {
+		printLine("\n✅ No particular risk areas.")
+	} else {
+		printf("\n⚠️  Risk areas (%d):\n", len(explanation.Risks))
+		for _, risk := range explanation.Risks {
+			printf("   - %s\n", risk)
+		}
+	}
+	return nil
+}
+
+// resolveRange turns a commit or a "from..to" range into the two commits
+// to diff. A commit is compared with its parent, or with the empty tree
+// when it has none; an empty end of a range means HEAD.
+func resolveRange(rev string) (from, to string, err error) {
+	if start, end, found := strings.Cut(rev, ".."); found {
+		if strings.HasPrefix(end, ".") {
+			return "", "", fmt.Errorf("%q: use a from..to range", rev)
+		}
+		if from = git.ResolveCommit(runCtx, cmp.Or(start, "HEAD")); from == "" {
+			return "", "", fmt.Errorf("unknown commit %q", start)
+		}
+		if to = git.ResolveCommit(runCtx, cmp.Or(end, "HEAD")); to == "" {
+			return "", "", fmt.Errorf("unknown commit %q", end)
+		}
+		return from, to, nil
+	}
+	if to = git.ResolveCommit(runCtx, rev); to == "" {
+		return "", "", fmt.Errorf("unknown commit %q", rev)
+	}
+	if from = git.ResolveCommit(runCtx, to+"^"); from == "" {
+		from = git.EmptyTree
+	}
+	return from, to, nil
+}
+
+// indent indents each line of text for the explanation output
+func indent(text string) string {
+	return "   " + strings.ReplaceAll(text, "\n", "\n   ")
+}
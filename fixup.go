@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// maxFixupCandidates bounds how many recent commits cc fixup considers
+const maxFixupCandidates = 15
+
+// handleFixup checks whether the pending changes belong to one of the
+// unpushed commits on the branch, and if so commits them as a fixup for it
+// after confirmation. The staged changes are used when there are any,
+// otherwise all changes.
+func handleFixup(cfg *config.Config) error {
+	logln("🔍 Checking for changes...")
+	if _, err := checkRepoState(false); err != nil {
+		return err
+	}
+
+	staged, err := git.GetStagedFiles(runCtx)
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("getting staged files: %w", err))
+	}
+	var changes *changeSet
+	if len(staged) > 0 {
+		changes, err = collectStagedChanges()
+	} else {
+		changes, err = collectChanges()
+	}
+	if err != nil {
+		return withExitCode(exitGitError, err)
+	}
+	if len(changes.Files) == 0 || changes.Diff == "" {
+		summaryf("✅ No changes to commit.\n")
+		exit(exitNoChanges)
+	}
+
+	candidates, err := fixupCandidates()
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		summaryf("✅ No unpushed commits on this branch to fix up; commit the changes with 'cc'.\n")
+		exit(exitNoChanges)
+	}
+
+	stopSpinner := startSpinner("🤖 Claude is matching your changes to recent commits", changes.spinnerDetail())
+	target, err := claude.FindFixupTarget(runCtx, changes.Diff, candidates, modelFor(cfg, taskReview), changes.UseSummaryMode)
+	stopSpinner()
+	if err != nil {
+		return withExitCode(exitModelErr, fmt.Errorf("calling Claude: %w", err))
+	}
+	if target.SHA == "" {
+		summaryf("\n🆕 These changes don't clearly belong to an earlier commit; commit them with 'cc'.\n")
+		if target.Reason != "" {
+			summaryf("   %s\n", target.Reason)
+		}
+		exit(exitNoChanges)
+	}
+
+	var subject string
+	for _, c := range candidates {
+		if c.SHA == target.SHA {
+			subject = c.Subject
+		}
+	}
+	summaryf("\n🎯 These changes belong to %s %s\n", shortSHA(target.SHA), subject)
+	if target.Reason != "" {
+		summaryf("   %s\n", target.Reason)
+	}
+
+	if !interactive() && !output.Yes {
+		return withExitCode(exitAborted, fmt.Errorf("rerun with --yes to create the fixup commit without a terminal"))
+	}
+	ok, err := confirm(fmt.Sprintf("Create a fixup commit for %s?", shortSHA(target.SHA)))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		summaryf("❌ Aborted. No changes were committed.\n")
+		exit(exitAborted)
+	}
+
+	indexTree := indexBeforeStaging()
+	if len(staged) == 0 {
+		if err := git.StageAll(runCtx); err != nil {
+			return withExitCode(exitGitError, fmt.Errorf("staging changes: %w", err))
+		}
+	}
+	if err := git.CommitFixup(runCtx, target.SHA, cfg.SignOff); err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("committing: %w", err))
+	}
+	recordCommit(indexTree)
+	sha, _ := git.GetHeadSHA(runCtx)
+
+	base := shortSHA(target.SHA) + "^"
+	if git.ResolveCommit(runCtx, target.SHA+"^") == "" {
+		base = "--root"
+	}
+	summaryf("✨ Created fixup commit %s; fold it in with 'git rebase -i --autosquash %s'.\n", shortSHA(sha), base)
+	return nil
+}
+
+// fixupCandidates returns the unpushed commits at the tip of the branch,
+// newest first, stopping at merges; fixup commits themselves are skipped
+func fixupCandidates() ([]claude.FixupCandidate, error) {
+	commits, err := git.GetCommits(runCtx, maxFixupCandidates)
+	if err != nil {
+		return nil, withExitCode(exitGitError, fmt.Errorf("reading commits: %w", err))
+	}
+	unpushed, err := git.GetUnpushedCommits(runCtx)
+	if err != nil {
+		return nil, withExitCode(exitGitError, fmt.Errorf("checking for pushed commits: %w", err))
+	}
+	isUnpushed := make(map[string]bool, len(unpushed))
+	for _, sha := range unpushed {
+		isUnpushed[sha] = true
+	}
+
+	var candidates []claude.FixupCandidate
+	for _, c := range commits {
+		if !isUnpushed[c.SHA] || len(c.Parents) > 1 {
+			break
+		}
+		subject, _ := claude.SplitMessage(c.Message)
+		if strings.HasPrefix(subject, "fixup! ") || strings.HasPrefix(subject, "squash! ") || strings.HasPrefix(subject, "amend! ") {
+			continue
+		}
+		parent := git.EmptyTree
+		if len(c.Parents) == 1 {
+			parent = c.Parents[0]
+		}
+		files, err := git.GetRangeChangedFiles(runCtx, parent, c.SHA)
+		if err != nil {
+			return nil, withExitCode(exitGitError, fmt.Errorf("getting the files of %s: %w", shortSHA(c.SHA), err))
+		}
+		candidates = append(candidates, claude.FixupCandidate{SHA: c.SHA, Subject: subject, Files: files})
+	}
+	return candidates, nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/debuglog"
+	"github.com/quaywin/claude-commit/internal/git"
+	"github.com/quaywin/claude-commit/internal/history"
+)
+
+// historyPath returns the run log in the config dir
+func historyPath() (string, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, history.FileName), nil
+}
+
+// recordRun appends a commit run to the history, unless it is turned off,
+// ran in demo mode, or had no changes for Claude to look at
+func recordRun(cfg *config.Config, opts commitOptions, report *commitReport, runErr error) {
+	if cfg.NoHistory || opts.Demo || len(report.ChangedFiles) == 0 {
+		return
+	}
+	path, err := historyPath()
+	if err != nil {
+		return
+	}
+
+	command := "commit"
+	if opts.Plan {
+		command = "plan"
+	}
+	repo, _ := git.GetRepoRoot(runCtx)
+	entry := history.Entry{
+		Time:      time.Now(),
+		Command:   command,
+		Repo:      repo,
+		Branch:    report.Branch,
+		Files:     report.ChangedFiles,
+		Mode:      report.Mode,
+		Model:     report.Model,
+		Findings:  report.Findings,
+		Message:   report.CommitMessage,
+		CommitSHA: report.CommitSHA,
+		Pushed:    report.Pushed,
+		DryRun:    report.DryRun,
+		ExitCode:  report.ExitCode,
+	}
+	if entry.Branch == "" {
+		entry.Branch, _ = git.GetCurrentBranch(runCtx)
+	}
+	if runErr != nil {
+		entry.Error = runErr.Error()
+	}
+	if usage := claude.TotalUsage(); usage.Calls > 0 {
+		entry.Usage = &usage
+	}
+	if err := history.Append(path, entry); err != nil {
+		debuglog.Log("writing history", "error", err)
+	}
+}
+
+// handleHistory prints the most recent runs, newest first, optionally only
+// those in the repository at repoFilter
+func handleHistory(repoFilter string, limit int) error {
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	entries, err := history.Load(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if repoFilter != "" {
+		repo, err := resolveRepoFilter(repoFilter)
+		if err != nil {
+			return err
+		}
+		var matching []history.Entry
+		for _, entry := range entries {
+			if entry.Repo == repo {
+				matching = append(matching, entry)
+			}
+		}
+		entries = matching
+	}
+
+	// Newest first, up to limit
+	var shown []history.Entry
+	for i := len(entries) - 1; i >= 0 && (limit <= 0 || len(shown) < limit); i-- {
+		shown = append(shown, entries[i])
+	}
+	if output.JSON {
+		if shown == nil {
+			shown = []history.Entry{}
+		}
+		printJSON(shown)
+		return nil
+	}
+	if len(shown) == 0 {
+		printf("📜 No runs recorded yet.\n")
+		return nil
+	}
+
+	for _, entry := range shown {
+		printf("\n📜 %s  %s (%s)  %s, %d file(s), %s\n", entry.Time.Local().Format("2006-01-02 15:04"), filepath.Base(entry.Repo), entry.Branch, entry.Command, len(entry.Files), entry.Model)
+		subject, _ := claude.SplitMessage(entry.Message)
+		switch {
+		case entry.CommitSHA != "":
+			pushed := ""
+			if entry.Pushed {
+				pushed = " (pushed)"
+			}
+			printf("   💾 %s %s%s\n", shortSHA(entry.CommitSHA), subject, pushed)
+		case entry.DryRun:
+			printf("   🧪 %s (dry run)\n", subject)
+		case entry.Error != "":
+			printf("   ❌ %s\n", firstLine(entry.Error))
+		default:
+			printf("   ⏹️  Not committed (exit %d)\n", entry.ExitCode)
+		}
+		for _, f := range entry.Findings {
+			printf("   🔎 [%s] %s\n", f.Severity, f.Description)
+		}
+	}
+	return nil
+}
+
+// resolveRepoFilter turns a --repo argument into the repository root the
+// history entries were recorded with
+func resolveRepoFilter(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("--repo %s: not a directory", dir)
+	}
+	root, err := git.GetRepoRootOf(runCtx, abs)
+	if err != nil {
+		return filepath.Clean(abs), nil
+	}
+	return root, nil
+}
+
+// firstLine returns the first line of text
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return line
+}
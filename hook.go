@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// hookMarker identifies hook scripts written by cc, so they can be replaced
+// or removed without touching hooks installed by other tools
+const hookMarker = "# Installed by claude-commit (cc hook install)"
+
+// handleHookInstall writes a hook script that calls back into this binary
+func handleHookInstall(name string, force bool) error {
+	dir, err := git.GetHooksDir(runCtx)
+	if err != nil {
+		return fmt.Errorf("finding hooks directory: %w", err)
+	}
+	path := filepath.Join(dir, name)
+
+	if existing, err := os.ReadFile(path); err == nil && !strings.Contains(string(existing), hookMarker) && !force {
+		return fmt.Errorf("%s already exists and was not installed by cc (use --force to replace it)", path)
+	}
+
+	// Hooks run with git's PATH, where "cc" is often the C compiler, so call this binary directly
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locating cc: %w", err)
+	}
+
+	quoted := "'" + strings.ReplaceAll(exe, "'", `'\''`) + "'"
+	script := fmt.Sprintf("#!/bin/sh\n%s\nexec %s hook %s \"$@\"\n", hookMarker, quoted, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		return err
+	}
+
+	printf("✅ Installed %s hook at %s\n", name, path)
+	return nil
+}
+
+// handleHookUninstall removes a hook script written by cc
+func handleHookUninstall(name string) error {
+	dir, err := git.GetHooksDir(runCtx)
+	if err != nil {
+		return fmt.Errorf("finding hooks directory: %w", err)
+	}
+	path := filepath.Join(dir, name)
+
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		printf("ℹ️  No %s hook installed.\n", name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(string(existing), hookMarker) {
+		return fmt.Errorf("%s was not installed by cc; leaving it alone", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+
+	printf("🗑️  Removed %s hook\n", name)
+	return nil
+}
+
+// runPrepareCommitMsgHook pre-fills the message file of a plain `git commit`
+// with a generated message for the staged changes. Findings are added as
+// comments. Failures are reported as warnings so they never block the commit.
+func runPrepareCommitMsgHook(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: cc hook prepare-commit-msg <message-file> [source] [sha]")
+	}
+	messageFile := args[0]
+
+	// A source means the message already came from -m, -F, a merge, a squash, or an amend
+	if len(args) > 1 && args[1] != "" {
+		return nil
+	}
+
+	if err := prefillCommitMessage(cfg, messageFile); err != nil {
+		eprintf("⚠️  cc: could not generate a commit message: %v\n", err)
+	}
+	return nil
+}
+
+func prefillCommitMessage(cfg *config.Config, messageFile string) error {
+	existing, err := os.ReadFile(messageFile)
+	if err != nil {
+		return err
+	}
+	if hasMessage(string(existing)) {
+		return nil
+	}
+
+	changes, err := collectStagedChanges()
+	if err != nil {
+		return err
+	}
+	if len(changes.Files) == 0 {
+		return nil
+	}
+
+	eprintf("🤖 cc: generating a commit message for %d staged files...\n", len(changes.Files))
+	format := messageFormat(cfg, "")
+	result, err := claude.ReviewAndCommitMessage(runCtx, changes.Diff, modelFor(cfg, taskReview), changes.UseSummaryMode, format, nil)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString(finishMessage(cfg, result.Message, claude.LastModel()))
+	b.WriteString("\n")
+	if len(result.Findings) > 0 {
+		b.WriteString("\n# Claude's findings:\n")
+		for _, finding := range result.Findings {
+			fmt.Fprintf(&b, "#   [%s] %s\n", finding.Severity, finding.Description)
+		}
+	}
+	b.Write(existing)
+
+	return os.WriteFile(messageFile, []byte(b.String()), 0644)
+}
+
+// hasMessage reports whether a commit message file contains anything besides comments
+func hasMessage(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return true
+		}
+	}
+	return false
+}
+
+// runPrePushHook reviews the commits about to be pushed and fails, blocking
+// the push, when Claude reports findings at or above block_severity. git
+// passes the remote name as the first argument and one
+// "<local ref> <local sha> <remote ref> <remote sha>" line per ref on stdin.
+// Model errors are reported but don't block the push.
+func runPrePushHook(cfg *config.Config, args []string, stdin io.Reader) error {
+	remote := "origin"
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	threshold, err := claude.ParseSeverity(cfg.BlockSeverity)
+	if err != nil {
+		return fmt.Errorf("invalid block_severity in config: %w", err)
+	}
+
+	blocked := 0
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 {
+			continue
+		}
+		localRef, localSHA, remoteSHA := fields[0], fields[1], fields[3]
+		if isZeroSHA(localSHA) {
+			continue // Deleting a remote branch
+		}
+
+		from := remoteSHA
+		if isZeroSHA(remoteSHA) {
+			// New branch: review the commits that aren't on the remote yet
+			if from, err = git.UnpushedBase(runCtx, localSHA, remote); err != nil {
+				return fmt.Errorf("finding new commits on %s: %w", localRef, err)
+			}
+		}
+
+		files, err := git.GetRangeChangedFiles(runCtx, from, localSHA)
+		if err != nil {
+			return fmt.Errorf("getting changed files for %s: %w", localRef, err)
+		}
+		if len(files) == 0 {
+			continue
+		}
+		diff, mode, err := git.ChooseDiff(len(files), func(summary bool) (string, error) {
+			return git.GetRangeDiff(runCtx, from, localSHA, summary)
+		})
+		if err != nil {
+			return fmt.Errorf("getting diff for %s: %w", localRef, err)
+		}
+
+		eprintf("🤖 cc: reviewing %d changed files on %s before pushing...\n", len(files), localRef)
+		review, err := claude.ReviewChanges(runCtx, diff, modelFor(cfg, taskReview), mode == git.SummaryDiff)
+		if err != nil {
+			eprintf("⚠️  cc: could not review %s, pushing anyway: %v\n", localRef, err)
+			continue
+		}
+		if len(review.Findings) > 0 {
+			printFindings(review.Findings)
+		}
+		blocked += len(claude.Blocking(review.Findings, threshold))
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading refs from git: %w", err)
+	}
+
+	if blocked > 0 {
+		return withExitCode(exitBlocked, fmt.Errorf("push blocked: %d finding(s) at or above %s severity (push with --no-verify to skip the review)", blocked, threshold))
+	}
+	return nil
+}
+
+func isZeroSHA(sha string) bool {
+	return strings.Trim(sha, "0") == ""
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// installDir picks where `cc install` puts the binary, like install.sh:
+// ~/.local/bin if it exists, otherwise /usr/local/bin
+func installDir() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		dir := filepath.Join(home, ".local", "bin")
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	if runtime.GOOS == "windows" {
+		if local := os.Getenv("LOCALAPPDATA"); local != "" {
+			return filepath.Join(local, "Programs", "cc")
+		}
+	}
+	return "/usr/local/bin"
+}
+
+// handleInstall copies the running binary into dir (or the default install
+// directory), falling back to sudo when dir isn't writable, and checks that
+// `cc` on the PATH now runs it
+func handleInstall(dir string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locating cc: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	if dir == "" {
+		dir = installDir()
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	name := "cc"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	target := filepath.Join(dir, name)
+
+	if resolved, err := filepath.EvalSymlinks(target); err == nil && resolved == exe {
+		printf("✅ cc %s is already installed at %s\n", VERSION, target)
+	} else {
+		printf("🚚 Installing cc %s to %s...\n", VERSION, target)
+		if err := installBinary(exe, dir, target); err != nil {
+			return err
+		}
+		printf("✅ Installed cc %s at %s\n", VERSION, target)
+	}
+
+	checkInstallPath(dir, target)
+	return nil
+}
+
+// installBinary copies exe to target through a temporary file, so a running
+// cc at target is replaced rather than overwritten. Without write access to
+// dir it retries with sudo.
+func installBinary(exe, dir, target string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err == nil {
+		tmp := target + ".new"
+		if err = copyFile(exe, tmp); err == nil {
+			if err = os.Rename(tmp, target); err != nil {
+				os.Remove(tmp)
+			}
+		}
+	}
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, os.ErrPermission) || runtime.GOOS == "windows" {
+		return fmt.Errorf("installing to %s: %w", target, err)
+	}
+	if _, lookErr := exec.LookPath("sudo"); lookErr != nil {
+		return fmt.Errorf("%s isn't writable and sudo isn't available; rerun with --dir pointing at a directory you own (e.g. ~/.local/bin)", dir)
+	}
+
+	printf("🔑 %s isn't writable; using sudo (you may be asked for your password)\n", dir)
+	cmd := exec.Command("sudo", "install", "-d", "-m", "0755", dir)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sudo install -d %s: %w", dir, err)
+	}
+	cmd = exec.Command("sudo", "install", "-m", "0755", exe, target)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sudo install %s: %w", target, err)
+	}
+	return nil
+}
+
+// checkInstallPath warns when dir isn't on the PATH, or when another cc
+// (often the C compiler) comes first
+func checkInstallPath(dir, target string) {
+	onPath := false
+	for _, entry := range filepath.SplitList(os.Getenv("PATH")) {
+		if abs, err := filepath.Abs(entry); err == nil && abs == dir {
+			onPath = true
+			break
+		}
+	}
+	if !onPath {
+		printf("⚠️  Warning: %s is not in your PATH.\n", dir)
+		printf("To add it, add the following line to your shell profile (~/.zshrc, ~/.bashrc, etc.):\n")
+		printf("  export PATH=\"$PATH:%s\"\n", dir)
+		return
+	}
+
+	found, err := exec.LookPath("cc")
+	if err != nil {
+		return
+	}
+	found, _ = filepath.Abs(found)
+	if resolvedFound, err := filepath.EvalSymlinks(found); err == nil {
+		if resolvedTarget, err := filepath.EvalSymlinks(target); err == nil && resolvedFound == resolvedTarget {
+			printf("✨ Run 'cc' in any git repo to start.\n")
+			return
+		}
+	}
+	printf("⚠️  Warning: 'cc' runs %s, which comes before %s in your PATH.\n", found, dir)
+	if strings.Contains(found, "/usr/bin") {
+		printf("   That is probably the C compiler.\n")
+	}
+	printf("💡 Move %s earlier in your PATH, or add an alias: alias cc='%s'\n", dir, target)
+}
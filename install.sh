@@ -31,7 +31,7 @@ echo "🚀 Installing $APP_NAME..."
 # Check if we are in a git repo with source code
 if [ -f "main.go" ] && command -v go >/dev/null 2>&1; then
     echo "🔨 Building from source..."
-    go build -o $APP_NAME main.go
+    go build -o $APP_NAME .
 else
     echo "📦 Downloading binary for $OS/$ARCH..."
     # Note: This expects binaries to be named like 'cc-darwin-arm64' in GitHub Releases
@@ -47,7 +47,7 @@ else
     # For now, we'll try to download, but warn if it fails
     if ! curl -fsSL "$BINARY_URL" -o $APP_NAME; then
         echo "❌ Could not download binary from $BINARY_URL"
-        echo "If you have the source code, run: go build -o cc main.go"
+        echo "If you have the source code, run: go build -o cc ."
         exit 1
     fi
     chmod +x $APP_NAME
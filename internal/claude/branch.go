@@ -0,0 +1,83 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxBranchSlugLength caps the generated part of a branch name
+const MaxBranchSlugLength = 50
+
+// BranchTypes are the prefixes Claude may choose from
+var BranchTypes = []string{"feat", "fix", "chore", "docs", "refactor", "test", "perf", "ci", "build", "style"}
+
+// GenerateBranchName asks Claude for a branch type (feat, fix, ...) and a short
+// kebab-case name, based on a task description or, if description is empty, on the diff.
+func GenerateBranchName(ctx context.Context, description string, diff string, model string) (branchType string, name string, err error) {
+	if description == "" && diff == "" {
+		return "", "", fmt.Errorf("no description or changes to name the branch after")
+	}
+
+	source := fmt.Sprintf("Task description:\n%s", description)
+	if description == "" {
+		source = fmt.Sprintf("Uncommitted changes:\n%s", diff)
+	}
+
+	prompt := fmt.Sprintf(`Suggest a git branch name for the following work.
+Respond with ONLY one line in the form "<type> <name>", where:
+- <type> is one of: %s
+- <name> is a short kebab-case summary (2-5 lowercase words joined by hyphens, ASCII only)
+
+Example: feat add-user-login
+
+%s`, strings.Join(BranchTypes, ", "), source)
+
+	result, err := runClaude(ctx, prompt, model, nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	branchType, name = parseBranchName(result)
+	if name == "" {
+		return "", "", fmt.Errorf("could not parse branch name from Claude output: %s", strings.TrimSpace(result))
+	}
+	return branchType, name, nil
+}
+
+// parseBranchName extracts "<type> <name>" from Claude's answer, tolerating
+// "type/name" and surrounding noise
+func parseBranchName(output string) (string, string) {
+	line := strings.TrimSpace(strings.Split(CleanMessage(output), "\n")[0])
+	line = strings.Trim(line, "`\"'")
+	line = strings.Replace(line, "/", " ", 1)
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", ""
+	}
+
+	branchType := "feat"
+	for _, t := range BranchTypes {
+		if strings.ToLower(fields[0]) == t {
+			branchType = t
+			fields = fields[1:]
+			break
+		}
+	}
+
+	return branchType, Slugify(strings.Join(fields, "-"))
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify turns text into a lowercase kebab-case string safe for branch names
+func Slugify(text string) string {
+	slug := nonSlugChars.ReplaceAllString(strings.ToLower(text), "-")
+	slug = strings.Trim(slug, "-")
+	if len(slug) > MaxBranchSlugLength {
+		slug = strings.TrimRight(slug[:MaxBranchSlugLength], "-")
+	}
+	return slug
+}
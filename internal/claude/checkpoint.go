@@ -0,0 +1,63 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// CheckpointMessage asks Claude for a one-line Conventional Commits subject
+// describing work in progress, for the checkpoint commits of `cc watch`
+func CheckpointMessage(ctx context.Context, diff string, model string, useSummaryMode bool) (string, error) {
+	if diff == "" {
+		return "", fmt.Errorf("no changes detected")
+	}
+
+	kind := "Diff"
+	if useSummaryMode {
+		kind = "Diff summary"
+	}
+	prompt := fmt.Sprintf(`The following changes were made since the last checkpoint of a work-in-progress session.
+Write a single-line commit subject describing them, following the Conventional Commits
+specification (e.g., feat: ..., fix: ..., refactor: ...), at most 72 characters.
+Describe what the work is heading towards; don't review the code.
+Return only the subject, with no quotes, explanations, or code fences.
+
+%s:
+%s`, kind, diff)
+
+	return oneLine(ctx, prompt, model)
+}
+
+// WIPDescription asks Claude for a few words describing the changes in a
+// diff --stat summary, for the commits of `cc wip`
+func WIPDescription(ctx context.Context, summary string, model string) (string, error) {
+	if summary == "" {
+		return "", fmt.Errorf("no changes detected")
+	}
+
+	prompt := fmt.Sprintf(`Describe the work in progress shown by this git diff summary in at most 8 words,
+lowercase, with no type prefix and no trailing period (e.g., "parser error handling and tests").
+Return only the description, with no quotes or explanations.
+
+Diff Summary:
+%s`, summary)
+
+	return oneLine(ctx, prompt, model)
+}
+
+// oneLine runs prompt and returns the first non-empty line of the answer,
+// without surrounding quotes or backticks
+func oneLine(ctx context.Context, prompt string, model string) (string, error) {
+	output, err := runClaude(ctx, prompt, model, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(CleanMessage(output), "\n") {
+		line = strings.Trim(strings.TrimSpace(line), "`\"")
+		if line != "" {
+			return line, nil
+		}
+	}
+	return "", fmt.Errorf("empty answer from Claude")
+}
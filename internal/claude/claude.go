@@ -2,54 +2,226 @@ package claude
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/quaywin/claude-commit/internal/debuglog"
+	"github.com/quaywin/claude-commit/internal/network"
 )
 
-// ReviewAndCommitMessage takes a git diff and returns a suggested commit message or an error if issues are found.
+// Timeout bounds each call to the claude CLI. Zero means no limit.
+var Timeout time.Duration
+
+// Usage is the token usage and cost of claude CLI calls
+type Usage struct {
+	Calls               int     `json:"calls"`
+	InputTokens         int     `json:"input_tokens"`
+	OutputTokens        int     `json:"output_tokens"`
+	CacheReadTokens     int     `json:"cache_read_input_tokens,omitempty"`
+	CacheCreationTokens int     `json:"cache_creation_input_tokens,omitempty"`
+	CostUSD             float64 `json:"cost_usd,omitempty"` // As reported by the CLI; zero when unknown
+}
+
+// Add accumulates other into u
+func (u *Usage) Add(other Usage) {
+	u.Calls += other.Calls
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CacheReadTokens += other.CacheReadTokens
+	u.CacheCreationTokens += other.CacheCreationTokens
+	u.CostUSD += other.CostUSD
+}
+
+// OnUsage, when set, is called after every claude call that reported its usage
+var OnUsage func(model string, usage Usage)
+
+// totalUsage adds up the usage of every call in this process
+var totalUsage Usage
+
+// TotalUsage returns the usage of every claude call made so far
+func TotalUsage() Usage {
+	return totalUsage
+}
+
+// ErrUnavailable matches (with errors.Is) the errors of calls that couldn't
+// reach Claude: the claude CLI is missing, or the network is down. Other
+// failures (login, model, rate limits, timeouts) don't match.
+var ErrUnavailable = errors.New("claude is unavailable")
+
+type unavailableError struct{ err error }
+
+func (e *unavailableError) Error() string        { return e.err.Error() }
+func (e *unavailableError) Unwrap() error        { return e.err }
+func (e *unavailableError) Is(target error) bool { return target == ErrUnavailable }
+
+func unavailable(err error) error { return &unavailableError{err: err} }
+
+// cliResult is the answer of `claude -p --output-format json`
+type cliResult struct {
+	Type         string  `json:"type"`
+	Result       string  `json:"result"`
+	IsError      bool    `json:"is_error"`
+	TotalCostUSD float64 `json:"total_cost_usd"`
+	Usage        Usage   `json:"usage"`
+}
+
+// parseCLIOutput extracts the answer and its usage from the CLI's JSON
+// output. Plain text (from older CLIs or wrappers) is returned as is.
+func parseCLIOutput(output string) (string, *Usage, error) {
+	var result cliResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil || result.Type != "result" {
+		return output, nil, nil
+	}
+	if result.IsError {
+		return "", nil, fmt.Errorf("claude reported an error: %s", result.Result)
+	}
+	usage := result.Usage
+	usage.Calls = 1
+	usage.CostUSD = result.TotalCostUSD
+	return result.Result, &usage, nil
+}
+
+// ReviewAndCommitMessage takes a git diff and returns the review findings together with a suggested commit message.
+// format controls whether a body is generated and which language each part is written in.
 // progressWriter can be provided to show real-time output from Claude.
-func ReviewAndCommitMessage(diff string, model string, useSummaryMode bool, progressWriter io.Writer) (string, error) {
+func ReviewAndCommitMessage(ctx context.Context, diff string, model string, useSummaryMode bool, format MessageFormat, progressWriter io.Writer) (*Result, error) {
 	if diff == "" {
-		return "", fmt.Errorf("no changes detected")
+		return nil, fmt.Errorf("no changes detected")
 	}
 
 	var prompt string
 	if useSummaryMode {
 		prompt = fmt.Sprintf(`Review the following git diff summary showing changed files and line counts.
-Since this is a large changeset (10+ files), you're seeing a summary rather than full diffs.
+The full diff is too large to send, so you're seeing a summary rather than full diffs.
 
 Focus on:
 - Overall scope and impact of changes
 - File naming and organizational patterns
 - Scale of changes (large refactors vs small fixes)
 
-If you notice concerning patterns (e.g., many files with massive changes suggesting risky refactoring),
-start your response with "ISSUE: " followed by the concern.
+Report concerning patterns (e.g., many files with massive changes suggesting risky refactoring) as findings.
+%s
 
-Otherwise, provide a concise commit message following Conventional Commits specification.
+Provide a concise commit message following Conventional Commits specification.
 Focus on the "why" and overall scope, not individual file details.
-Provide ONLY the commit message in one line. Do NOT include any "Co-Authored-By" trailers or attribution.
+%s
 
 Diff Summary:
-%s`, diff)
+%s`, findingInstructions+reviewInstructions(), format.instructions(), diff)
 	} else {
 		prompt = fmt.Sprintf(`Review the following git diff for any issues (bugs, security risks, style).
-If there are critical issues, you MUST start your response with "ISSUE: " followed by the description.
+%s
 
-If the code looks good, provide a concise, professional commit message.
+Provide a concise, professional commit message.
 Follow the Conventional Commits specification (e.g., feat: ..., fix: ..., chore: ...).
 Focus on "why" the change was made, not just "what" changed.
-Provide ONLY the commit message in one line. Do NOT include any "Co-Authored-By" trailers or attribution.
+%s
 
 Diff:
-%s`, diff)
+%s`, findingInstructions+reviewInstructions(), format.instructions(), diff)
+	}
+
+	output, err := runClaude(ctx, prompt, model, progressWriter)
+	if err != nil {
+		return nil, err
+	}
+	result := ParseResult(output)
+	result.Message = format.postProcess(result.Message)
+	if result.Message == "" && len(Blocking(result.Findings, SeverityCritical)) > 0 {
+		// A critical finding blocks the commit anyway; don't lose it to a retry
+		return result, nil
+	}
+
+	// Validate the message shape and ask once more if Claude got it wrong
+	if validationErr := ValidateMessage(result.Message, format); validationErr != nil {
+		retryPrompt := fmt.Sprintf("%s\n\nYour previous answer was rejected (%v):\n%s\n\nAnswer again following the format rules exactly.", prompt, validationErr, strings.TrimSpace(output))
+		output, err = runClaude(ctx, retryPrompt, model, progressWriter)
+		if err != nil {
+			return nil, err
+		}
+		result = ParseResult(output)
+		result.Message = format.postProcess(result.Message)
+		if validationErr := ValidateMessage(result.Message, format); validationErr != nil {
+			return nil, fmt.Errorf("invalid commit message from Claude: %w", validationErr)
+		}
+	}
+
+	return result, nil
+}
+
+// Fallbacks are the models tried in order when a call fails because the
+// model is overloaded or the API has a server error
+var Fallbacks []string
+
+// OnFallback, when set, is called before a call is retried with another model
+var OnFallback func(failed, next string, err error)
+
+// lastModel is the model that gave the last answer
+var lastModel string
+
+// LastModel returns the model that gave the last answer, which differs
+// from the requested one after a fallback. It is empty before any answer.
+func LastModel() string {
+	return lastModel
+}
+
+// serverError matches the errors worth retrying with another model:
+// overloaded models and 5xx responses
+var serverError = regexp.MustCompile(`(?i)overloaded|internal server error|bad gateway|service unavailable|gateway timeout|\b(500|502|503|504|529)\b`)
+
+// runClaude sends the prompt to the claude CLI and returns its output. When
+// model is overloaded or the API fails, the Fallbacks are tried in order.
+func runClaude(ctx context.Context, prompt string, model string, progressWriter io.Writer) (string, error) {
+	models := []string{model}
+	for _, fallback := range Fallbacks {
+		if !slices.Contains(models, fallback) {
+			models = append(models, fallback)
+		}
+	}
+	var output string
+	var err error
+	for i, m := range models {
+		if i > 0 {
+			if OnFallback != nil {
+				OnFallback(models[i-1], m, err)
+			}
+			debuglog.Log("claude fallback", "failed", models[i-1], "next", m, "error", err)
+		}
+		output, err = runWithBackoff(ctx, prompt, m, progressWriter)
+		if err == nil {
+			lastModel = m
+			return output, nil
+		}
+		if !serverError.MatchString(err.Error()) || ctx.Err() != nil {
+			break
+		}
 	}
+	return output, err
+}
 
+// runModel runs the claude CLI once with model
+func runModel(ctx context.Context, prompt string, model string, progressWriter io.Writer) (string, error) {
 	// We use the specified model, and '-p' for non-interactive output.
 	// We pass the prompt via stdin to avoid "argument list too long" errors for large diffs.
-	cmd := exec.Command("claude", "--model", model, "-p")
+	// JSON output carries the token usage and cost next to the answer.
+	if Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "claude", "--model", model, "-p", "--output-format", "json")
 	cmd.Stdin = bytes.NewReader([]byte(prompt))
+	// Don't wait on output pipes held open by children of a killed claude
+	cmd.WaitDelay = time.Second
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -59,10 +231,75 @@ Diff:
 		cmd.Stderr = io.MultiWriter(&stderr, progressWriter)
 	}
 
+	debuglog.Log("claude request", "model", model, "prompt_bytes", len(prompt), "prompt_lines", strings.Count(prompt, "\n")+1)
+	start := time.Now()
 	err := cmd.Run()
+	debuglog.Log("claude response", "model", model, "latency", time.Since(start), "output_bytes", stdout.Len(), "error", err)
+	debuglog.Log("claude raw output", "output", stdout.String(), "stderr", stderr.String())
 	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("claude (%s) timed out after %s", model, Timeout)
+		}
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return "", fmt.Errorf("claude (%s) was interrupted: %w", model, context.Canceled)
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", unavailable(fmt.Errorf("the claude CLI isn't installed or isn't on your PATH"))
+		}
+		if hint := network.ExplainOutput(stderr.String() + stdout.String()); hint != "" {
+			return "", unavailable(fmt.Errorf("claude command failed: %w, stderr: %s (%s)", err, strings.TrimSpace(stderr.String()), hint))
+		}
 		return "", fmt.Errorf("claude command failed: %w, stderr: %s", err, stderr.String())
 	}
 
-	return stdout.String(), nil
+	answer, usage, err := parseCLIOutput(stdout.String())
+	if err != nil {
+		return "", err
+	}
+	if usage != nil {
+		debuglog.Log("claude usage", "model", model, "input_tokens", usage.InputTokens, "output_tokens", usage.OutputTokens, "cost_usd", usage.CostUSD)
+		totalUsage.Add(*usage)
+		if OnUsage != nil {
+			OnUsage(model, *usage)
+		}
+	}
+	return answer, nil
+}
+
+// Version returns the installed claude CLI version
+func Version(ctx context.Context) (string, error) {
+	out, err := exec.CommandContext(ctx, "claude", "--version").Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// Ping sends model a tiny prompt to check that the CLI is logged in and
+// can reach the API
+func Ping(ctx context.Context, model string) error {
+	_, err := runClaude(ctx, "Reply with just: OK", model, nil)
+	return err
+}
+
+// RewriteMessage asks Claude to fix the listed problems in a hand-written
+// commit message while keeping its meaning
+func RewriteMessage(ctx context.Context, message string, problems []string, model string, format MessageFormat) (string, error) {
+	prompt := fmt.Sprintf(`Rewrite the following git commit message so that it fixes these problems:
+- %s
+
+Keep the meaning and any details of the original. Follow the Conventional Commits specification (e.g., feat: ..., fix: ..., chore: ...).
+%s
+Return only the rewritten commit message, with no explanations or code fences.
+
+Original message:
+%s`, strings.Join(problems, "\n- "), format.instructions(), message)
+
+	output, err := runClaude(ctx, prompt, model, nil)
+	if err != nil {
+		return "", err
+	}
+
+	rewritten := format.postProcess(output)
+	if err := ValidateMessage(rewritten, format); err != nil {
+		return "", fmt.Errorf("invalid commit message from Claude: %w", err)
+	}
+	return rewritten, nil
 }
@@ -0,0 +1,170 @@
+package claude
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Claude tends to dress up a commit message: code fences, quotes, a
+// "Commit message:" label, a sentence introducing it, a Co-Authored-By
+// trailer, or a closing remark. CleanMessage takes all of that off, one
+// step at a time, so that only the message reaches git.
+
+// cleanupSteps run in order on the whole message
+var cleanupSteps = []func(string) string{
+	stripFences,
+	stripIntro,
+	stripLabel,
+	stripQuotes,
+	stripAttribution,
+	stripOutro,
+	tidyLines,
+}
+
+// CleanMessage removes the wrapping and chatter around a commit message
+func CleanMessage(message string) string {
+	message = strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n"))
+	for _, step := range cleanupSteps {
+		message = strings.TrimSpace(step(message))
+	}
+	return message
+}
+
+// postProcess turns Claude's message into the one to validate and use: it
+// is cleaned, cut to the subject when the format has no body (anything
+// below it is commentary), given the requested type, and rewritten by the
+// user's rules
+func (f MessageFormat) postProcess(message string) string {
+	message = CleanMessage(message)
+	if !f.wantsBody() {
+		message, _ = SplitMessage(message)
+	}
+	message = f.applyType(message)
+	for _, rewrite := range f.Rewrites {
+		message = rewrite.apply(message)
+	}
+	return strings.TrimSpace(message)
+}
+
+// fence matches an opening or closing code fence, e.g. "```text"
+var fence = regexp.MustCompile("^(```|~~~)[a-zA-Z0-9_-]*$")
+
+// stripFences returns what is inside the first code fence, or the message
+// without stray fence lines when nothing is fenced in
+func stripFences(message string) string {
+	lines := strings.Split(message, "\n")
+	start := -1
+	for i, line := range lines {
+		if !fence.MatchString(strings.TrimSpace(line)) {
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		if inside := strings.TrimSpace(strings.Join(lines[start+1:i], "\n")); inside != "" {
+			return inside
+		}
+		start = -1
+	}
+
+	var kept []string
+	for _, line := range lines {
+		if !fence.MatchString(strings.TrimSpace(line)) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// intro matches a sentence introducing the message, e.g. "Here's a commit
+// message for these changes:"
+var intro = regexp.MustCompile(`(?i)^(here('s| is| are)|sure|okay|ok|based on|i('ve| have| would| suggest)|the following|this is)\b.*:$`)
+
+// stripIntro drops an introductory sentence before the message
+func stripIntro(message string) string {
+	first, rest, _ := strings.Cut(message, "\n")
+	if intro.MatchString(strings.TrimSpace(first)) {
+		return rest
+	}
+	return message
+}
+
+// label matches a label in front of the message, e.g. "**Commit message:**"
+var label = regexp.MustCompile(`(?i)^[*_]*(suggested |proposed )?(git )?(commit )?(message|subject)[*_]*:[*_]*\s*`)
+
+// stripLabel drops a label in front of the message, on its own line or not
+func stripLabel(message string) string {
+	return label.ReplaceAllString(message, "")
+}
+
+// stripQuotes removes quotes or backticks around the whole message, or
+// around the subject line alone
+func stripQuotes(message string) string {
+	if unquoted, ok := unquote(message); ok {
+		return unquoted
+	}
+	subject, rest, found := strings.Cut(message, "\n")
+	if unquoted, ok := unquote(strings.TrimSpace(subject)); ok && found {
+		return unquoted + "\n" + rest
+	}
+	return message
+}
+
+// unquote removes one pair of matching quotes or backticks around s
+func unquote(s string) (string, bool) {
+	for _, q := range []string{"`", `"`, "'", "“"} {
+		end := q
+		if q == "“" {
+			end = "”"
+		}
+		if len(s) > len(q)+len(end) && strings.HasPrefix(s, q) && strings.HasSuffix(s, end) {
+			inner := s[len(q) : len(s)-len(end)]
+			if !strings.Contains(inner, q) {
+				return strings.TrimSpace(inner), true
+			}
+		}
+	}
+	return s, false
+}
+
+// attribution matches trailers and lines crediting a tool
+var attribution = regexp.MustCompile(`(?i)^(co-authored-by:|generated (with|by)\b|🤖 generated)`)
+
+// stripAttribution drops Co-Authored-By trailers and "Generated with" lines
+func stripAttribution(message string) string {
+	var kept []string
+	for _, line := range strings.Split(message, "\n") {
+		if !attribution.MatchString(strings.TrimSpace(line)) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// outro matches the start of a closing remark, e.g. "Let me know if ..."
+var outro = regexp.MustCompile(`(?i)^(let me know|i hope|hope this|feel free|this commit message|this message|the commit message|explanation:)`)
+
+// stripOutro drops a closing paragraph addressed to the user
+func stripOutro(message string) string {
+	paragraphs := strings.Split(message, "\n\n")
+	for len(paragraphs) > 1 && outro.MatchString(strings.TrimSpace(paragraphs[len(paragraphs)-1])) {
+		paragraphs = paragraphs[:len(paragraphs)-1]
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// tidyLines trims trailing spaces and collapses runs of blank lines
+func tidyLines(message string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" && blank {
+			continue
+		}
+		blank = line == ""
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,70 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Conversation is a question-and-answer session about pending changes.
+// Every question is sent along with the diff and the earlier exchanges, so
+// follow-up questions keep their context.
+type Conversation struct {
+	Diff           string
+	UseSummaryMode bool
+	Message        string // The proposed commit message, if any
+
+	exchanges []exchange
+}
+
+// exchange is one question and its answer
+type exchange struct {
+	question string
+	answer   string
+}
+
+// NewConversation starts a conversation about diff
+func NewConversation(diff string, useSummaryMode bool) *Conversation {
+	return &Conversation{Diff: diff, UseSummaryMode: useSummaryMode}
+}
+
+// Ask sends question to model and returns the answer, remembering both for
+// the next question
+func (c *Conversation) Ask(ctx context.Context, question string, model string) (string, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return "", fmt.Errorf("empty question")
+	}
+
+	diffLabel := "Diff"
+	if c.UseSummaryMode {
+		diffLabel = "Diff Summary (large changeset, showing changed files and line counts only)"
+	}
+	var b strings.Builder
+	b.WriteString(`A developer is about to commit the following git changes and has questions about them.
+Answer each question directly and concisely, as a careful senior engineer would, pointing to the files and code involved.
+Say so when the diff doesn't show enough to be sure. Use plain text without Markdown headings.
+`)
+	if c.Message != "" {
+		fmt.Fprintf(&b, "\nProposed commit message:\n%s\n", c.Message)
+	}
+	fmt.Fprintf(&b, "\n%s:\n%s\n", diffLabel, c.Diff)
+	if len(c.exchanges) > 0 {
+		b.WriteString("\nConversation so far:\n")
+		for _, e := range c.exchanges {
+			fmt.Fprintf(&b, "Question: %s\nAnswer: %s\n\n", e.question, e.answer)
+		}
+	}
+	fmt.Fprintf(&b, "\nQuestion: %s\n", question)
+
+	output, err := runClaude(ctx, b.String(), model, nil)
+	if err != nil {
+		return "", err
+	}
+	answer := strings.TrimSpace(output)
+	if answer == "" {
+		return "", fmt.Errorf("claude returned an empty answer")
+	}
+	c.exchanges = append(c.exchanges, exchange{question: question, answer: answer})
+	return answer, nil
+}
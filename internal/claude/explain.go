@@ -0,0 +1,93 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Explanation is a plain-language account of a set of changes
+type Explanation struct {
+	What  string   `json:"what"`  // What changed
+	Why   string   `json:"why"`   // Why it likely changed
+	Risks []string `json:"risks"` // Areas that deserve a careful look
+}
+
+// ExplainChanges asks Claude to explain a diff in plain language: what
+// changed, why it likely changed, and where the risks are. log holds the
+// messages of the commits involved, if any.
+func ExplainChanges(ctx context.Context, diff string, log string, model string, useSummaryMode bool) (*Explanation, error) {
+	if diff == "" {
+		return nil, fmt.Errorf("no changes detected")
+	}
+
+	diffLabel := "Diff"
+	if useSummaryMode {
+		diffLabel = "Diff Summary (large changeset, showing changed files and line counts only)"
+	}
+	commits := ""
+	if strings.TrimSpace(log) != "" {
+		commits = fmt.Sprintf("\nCommits:\n%s\n", log)
+	}
+
+	prompt := fmt.Sprintf(`Explain the following git changes in plain language to a developer who hasn't seen them.
+Don't write a commit message and don't review the code style.
+
+Respond in exactly this format and nothing else:
+WHAT:
+<a short paragraph on what changed, naming the main files or components>
+WHY:
+<a short paragraph on why it most likely changed; say so when it's a guess>
+RISKS:
+- <one area that deserves a careful look per line, e.g. behavior changes, migrations, or missing tests; write "- none" if there are none>
+%s
+%s:
+%s`, commits, diffLabel, diff)
+
+	result, err := runClaude(ctx, prompt, model, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := parseExplanation(result)
+	if explanation.What == "" {
+		return nil, fmt.Errorf("could not parse explanation from Claude output: %s", strings.TrimSpace(result))
+	}
+	return explanation, nil
+}
+
+// parseExplanation extracts the WHAT/WHY/RISKS sections from Claude's output
+func parseExplanation(output string) *Explanation {
+	explanation := &Explanation{Risks: []string{}}
+	var what, why []string
+	var section *[]string
+	inRisks := false
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		upper := strings.ToUpper(trimmed)
+		switch {
+		case strings.HasPrefix(upper, "WHAT:"):
+			section, inRisks = &what, false
+			trimmed = strings.TrimSpace(trimmed[len("WHAT:"):])
+		case strings.HasPrefix(upper, "WHY:"):
+			section, inRisks = &why, false
+			trimmed = strings.TrimSpace(trimmed[len("WHY:"):])
+		case strings.HasPrefix(upper, "RISKS:"):
+			section, inRisks = nil, true
+			continue
+		}
+		if inRisks {
+			item := strings.TrimSpace(strings.TrimLeft(trimmed, "-*•"))
+			if item != "" && !strings.EqualFold(item, "none") {
+				explanation.Risks = append(explanation.Risks, item)
+			}
+		} else if section != nil && trimmed != "" {
+			*section = append(*section, trimmed)
+		}
+	}
+
+	explanation.What = strings.Join(what, " ")
+	explanation.Why = strings.Join(why, " ")
+	return explanation
+}
@@ -0,0 +1,232 @@
+package claude
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Severity ranks how serious a review finding is
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityCritical:
+		return "critical"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// MarshalText encodes the severity as its name in JSON output
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a severity name, e.g. when reading the history
+func (s *Severity) UnmarshalText(text []byte) error {
+	severity, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = severity
+	return nil
+}
+
+// ParseSeverity parses "info", "warning", or "critical" (case-insensitive)
+func ParseSeverity(text string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "info":
+		return SeverityInfo, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "critical", "error":
+		return SeverityCritical, nil
+	}
+	return SeverityInfo, fmt.Errorf("unknown severity %q (use info, warning, or critical)", text)
+}
+
+// Finding is a single problem reported by the review
+type Finding struct {
+	Severity    Severity `json:"severity"`
+	Description string   `json:"description"`
+	Checklist   string   `json:"checklist,omitempty"` // The checklist item it violates
+}
+
+// Result is the parsed response of a review-and-message call
+type Result struct {
+	Findings []Finding
+	Message  string // Empty when Claude only reported a critical finding (an "ISSUE:" answer without a suggested message)
+}
+
+// Blocking returns the findings at or above the threshold severity
+func (r *Result) Blocking(threshold Severity) []Finding {
+	return Blocking(r.Findings, threshold)
+}
+
+// Blocking returns the findings at or above threshold
+func Blocking(findings []Finding, threshold Severity) []Finding {
+	var blocking []Finding
+	for _, f := range findings {
+		if f.Severity >= threshold {
+			blocking = append(blocking, f)
+		}
+	}
+	return blocking
+}
+
+// findingInstructions tells Claude how to report findings and the message
+const findingInstructions = `Report each finding on its own line as:
+FINDING: <severity>: <description>
+where <severity> is one of:
+- critical: bugs, data loss, security risks, or anything that must not be committed
+- warning: likely problems worth a second look
+- info: minor style or readability notes
+Omit FINDING lines entirely if there is nothing to report.
+
+Then write "MESSAGE:" on its own line, followed by the commit message. Always include the message, even when there are findings.`
+
+// ParseResult parses Claude's FINDING/MESSAGE response. Output using the
+// older "ISSUE: ..." protocol is treated as a single critical finding, with
+// the message from its "Suggested message:" line if there is one, and
+// output without any markers is treated as a bare commit message.
+func ParseResult(output string) *Result {
+	output = strings.TrimSpace(output)
+	result := &Result{}
+
+	if strings.HasPrefix(strings.ToUpper(output), "ISSUE:") {
+		var description []string
+		for _, line := range strings.Split(output[len("ISSUE:"):], "\n") {
+			lower := strings.ToLower(strings.TrimSpace(line))
+			if result.Message == "" && (strings.HasPrefix(lower, "suggested message:") || strings.HasPrefix(lower, "commit message:")) {
+				_, message, _ := strings.Cut(line, ":")
+				result.Message = CleanMessage(message)
+				continue
+			}
+			description = append(description, line)
+		}
+		result.Findings = append(result.Findings, Finding{
+			Severity:    SeverityCritical,
+			Description: strings.TrimSpace(strings.Join(description, "\n")),
+		})
+		return result
+	}
+
+	var message []string
+	inMessage := false
+	sawMarker := false
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		upper := strings.ToUpper(trimmed)
+
+		switch {
+		case !inMessage && strings.HasPrefix(upper, "FINDING:"):
+			sawMarker = true
+			result.Findings = append(result.Findings, parseFinding(trimmed[len("FINDING:"):]))
+		case !inMessage && strings.HasPrefix(upper, "MESSAGE:"):
+			sawMarker = true
+			inMessage = true
+			if rest := strings.TrimSpace(trimmed[len("MESSAGE:"):]); rest != "" {
+				message = append(message, rest)
+			}
+		case inMessage:
+			message = append(message, strings.TrimRight(line, " \t\r"))
+		}
+	}
+
+	if !sawMarker {
+		result.Message = CleanMessage(output)
+		return result
+	}
+
+	result.Message = CleanMessage(strings.Join(message, "\n"))
+	return result
+}
+
+// Checklist are the team's review rules. Each violation is reported as a
+// finding with the item in Checklist.
+var Checklist []string
+
+// checklistInstructions asks Claude to check the changes against Checklist
+func checklistInstructions() string {
+	if len(Checklist) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\nAlso check the changes against the team's review checklist:\n")
+	for i, item := range Checklist {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, item)
+	}
+	b.WriteString(`Report each violation of a checklist item as a finding whose description starts with "[checklist <number>]", e.g. "[checklist 1] ...". Don't report items that aren't violated.`)
+	return b.String()
+}
+
+// SecurityReview narrows reviews to security problems (--security)
+var SecurityReview bool
+
+// securityInstructions focus a review on security
+const securityInstructions = `
+This is a security review: report only security problems, such as
+- injection: SQL, shell commands, templates, file paths, and unescaped output (XSS)
+- broken authentication or authorization: missing permission checks, access to other users' data by ID, trusting client-supplied roles
+- crypto misuse: weak or home-made algorithms, hard-coded keys, predictable randomness, disabled certificate checks
+- dependency risks: new or upgraded dependencies that are unmaintained, typo-squatted, known to be vulnerable, or fetched over plain HTTP
+- secrets, credentials, or personal data in code, logs, or config
+- unsafe deserialization, server-side request forgery, and open redirects
+Rank them as critical (exploitable), warning (risky patterns that need a closer look), or info (hardening). Skip style and bugs unrelated to security.`
+
+// reviewInstructions are the checklist and security focus added to every
+// review prompt
+func reviewInstructions() string {
+	instructions := checklistInstructions()
+	if SecurityReview {
+		instructions += securityInstructions
+	}
+	return instructions
+}
+
+// checklistRef matches the "[checklist 2]" in front of a finding
+var checklistRef = regexp.MustCompile(`(?i)^\[checklist\s*#?(\d+)\]\s*`)
+
+// parseFinding parses "<severity>: <description>", defaulting to warning
+// when the severity is missing or unknown, and links a violation to its
+// checklist item
+func parseFinding(text string) Finding {
+	finding := parseSeverityAndDescription(text)
+	if m := checklistRef.FindStringSubmatch(finding.Description); m != nil {
+		if n, err := strconv.Atoi(m[1]); err == nil && n >= 1 && n <= len(Checklist) {
+			finding.Checklist = Checklist[n-1]
+			finding.Description = finding.Description[len(m[0]):]
+		}
+	}
+	return finding
+}
+
+// parseSeverityAndDescription splits "<severity>: <description>" or
+// "[severity] <description>"
+func parseSeverityAndDescription(text string) Finding {
+	text = strings.TrimSpace(text)
+	if sev, rest, found := strings.Cut(text, ":"); found {
+		if severity, err := ParseSeverity(strings.Trim(sev, "[] ")); err == nil {
+			return Finding{Severity: severity, Description: strings.TrimSpace(rest)}
+		}
+	}
+	if strings.HasPrefix(text, "[") {
+		if sev, rest, found := strings.Cut(text[1:], "]"); found {
+			if severity, err := ParseSeverity(sev); err == nil {
+				return Finding{Severity: severity, Description: strings.TrimSpace(rest)}
+			}
+		}
+	}
+	return Finding{Severity: SeverityWarning, Description: text}
+}
@@ -0,0 +1,96 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// FixupCandidate is an earlier commit pending changes may belong to
+type FixupCandidate struct {
+	SHA     string
+	Subject string
+	Files   []string
+}
+
+// FixupTarget is the commit Claude picked for pending changes; SHA is empty
+// when they don't clearly belong to any of the candidates
+type FixupTarget struct {
+	SHA    string `json:"sha"`
+	Reason string `json:"reason"`
+}
+
+// FindFixupTarget asks Claude whether a diff finishes, corrects, or extends
+// one of the candidate commits rather than being a change of its own.
+func FindFixupTarget(ctx context.Context, diff string, candidates []FixupCandidate, model string, useSummaryMode bool) (*FixupTarget, error) {
+	if diff == "" {
+		return nil, fmt.Errorf("no changes detected")
+	}
+	if len(candidates) == 0 {
+		return &FixupTarget{}, nil
+	}
+
+	diffLabel := "Diff"
+	if useSummaryMode {
+		diffLabel = "Diff Summary (large changeset, showing changed files and line counts only)"
+	}
+	var commits strings.Builder
+	for _, c := range candidates {
+		fmt.Fprintf(&commits, "%s %s\n", c.SHA, c.Subject)
+		if len(c.Files) > 0 {
+			fmt.Fprintf(&commits, "    files: %s\n", strings.Join(c.Files, ", "))
+		}
+	}
+
+	prompt := fmt.Sprintf(`The following uncommitted changes may belong to one of the recent commits on this branch, e.g. a forgotten file, a typo fix, or a follow-up to a review comment on that commit.
+Pick a commit only if the changes clearly finish or correct it; if they are new work or could belong to several commits, answer none.
+
+Respond in exactly this format and nothing else:
+TARGET: <the full SHA of the commit, or none>
+REASON: <one sentence on why>
+
+Recent commits (newest first):
+%s
+%s:
+%s`, commits.String(), diffLabel, diff)
+
+	result, err := runClaude(ctx, prompt, model, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	target, ok := parseFixupTarget(result, candidates)
+	if !ok {
+		return nil, fmt.Errorf("could not parse fixup target from Claude output: %s", strings.TrimSpace(result))
+	}
+	return target, nil
+}
+
+// parseFixupTarget extracts TARGET/REASON from Claude's output. A target
+// that isn't one of the candidates (at least 7 characters of its SHA) counts
+// as none.
+func parseFixupTarget(output string, candidates []FixupCandidate) (*FixupTarget, bool) {
+	target := &FixupTarget{}
+	found := false
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		upper := strings.ToUpper(trimmed)
+		switch {
+		case strings.HasPrefix(upper, "TARGET:"):
+			found = true
+			sha := strings.ToLower(strings.Trim(strings.TrimSpace(trimmed[len("TARGET:"):]), "`"))
+			if len(sha) < 7 {
+				continue
+			}
+			for _, c := range candidates {
+				if strings.HasPrefix(c.SHA, sha) {
+					target.SHA = c.SHA
+					break
+				}
+			}
+		case strings.HasPrefix(upper, "REASON:"):
+			target.Reason = strings.TrimSpace(trimmed[len("REASON:"):])
+		}
+	}
+	return target, found
+}
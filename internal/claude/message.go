@@ -0,0 +1,318 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// MaxSubjectLength is the longest subject line accepted from Claude
+const MaxSubjectLength = 100
+
+// MessageFormat controls the shape and language of the generated commit message
+type MessageFormat struct {
+	Body            bool   // Generate a body below the subject line
+	SubjectLanguage string // Language of the subject line (empty = English)
+	BodyLanguage    string // Language of the body (empty = same as subject)
+	Hint            string // Extra guidance from the user, e.g. when asking for another attempt
+	// Examples are recent commit subjects of the repository whose style
+	// the message should follow
+	Examples []string
+	// Issue is the issue the change works on: its title and acceptance
+	// criteria, for the why of the message
+	Issue string
+	// RepoMap outlines the repository (see repomap.Build), so the message
+	// names components the way the project does
+	RepoMap string
+
+	// Project conventions, e.g. from a commitlint config; zero values add no rules
+	Types            []string // Allowed Conventional Commits types
+	Scopes           []string // Allowed scopes
+	SubjectMaxLength int      // Longest subject line, instead of MaxSubjectLength
+	Conventions      string   // Free-form rules quoted in the prompt
+
+	// Type is the Conventional Commits type the subject must use, e.g. from
+	// --type; it wins over Types
+	Type string
+	// Rewrites are the user's rules, applied after the cleanup
+	Rewrites []Rewrite
+}
+
+// maxSubjectLength returns the longest subject line allowed
+func (f MessageFormat) maxSubjectLength() int {
+	if f.SubjectMaxLength > 0 {
+		return f.SubjectMaxLength
+	}
+	return MaxSubjectLength
+}
+
+// conventionRules returns the prompt lines for the project's conventions
+func (f MessageFormat) conventionRules() string {
+	var rules string
+	if f.Type != "" {
+		rules += "\nThe type must be " + f.Type + "."
+	} else if len(f.Types) > 0 {
+		rules += "\nThe type must be one of: " + strings.Join(f.Types, ", ") + "."
+	}
+	if len(f.Scopes) > 0 {
+		rules += "\nThe scope, if any, must be one of: " + strings.Join(f.Scopes, ", ") + "."
+	}
+	if f.SubjectMaxLength > 0 {
+		rules += fmt.Sprintf("\nThe subject line must be at most %d characters.", f.SubjectMaxLength)
+	}
+	if f.Conventions != "" {
+		rules += "\nFollow the project's commit conventions:\n" + f.Conventions
+	}
+	return rules
+}
+
+// conventionSubject captures the type and scope of a Conventional Commits subject
+var conventionSubject = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()]+)\))?!?: `)
+
+// conventionProblems checks subject against the allowed types and scopes
+func (f MessageFormat) conventionProblems(subject string) []string {
+	m := conventionSubject.FindStringSubmatch(subject)
+	if m == nil {
+		return nil
+	}
+	var problems []string
+	if f.Type != "" && m[1] != f.Type {
+		problems = append(problems, fmt.Sprintf("type %q is not the requested type %q", m[1], f.Type))
+	} else if f.Type == "" && len(f.Types) > 0 && !slices.Contains(f.Types, m[1]) {
+		problems = append(problems, fmt.Sprintf("type %q is not one of the project's types (%s)", m[1], strings.Join(f.Types, ", ")))
+	}
+	if len(f.Scopes) > 0 && m[2] != "" {
+		for _, scope := range strings.Split(m[2], ",") {
+			if scope = strings.TrimSpace(scope); !slices.Contains(f.Scopes, scope) {
+				problems = append(problems, fmt.Sprintf("scope %q is not one of the project's scopes (%s)", scope, strings.Join(f.Scopes, ", ")))
+			}
+		}
+	}
+	return problems
+}
+
+// applyType puts the requested type on the subject of message: Claude's
+// own type is replaced, or the type is added to a subject without one
+func (f MessageFormat) applyType(message string) string {
+	if f.Type == "" {
+		return message
+	}
+	message = strings.TrimSpace(message)
+	if m := conventionSubject.FindStringSubmatchIndex(message); m != nil {
+		return f.Type + message[m[3]:]
+	}
+	if message == "" {
+		return message
+	}
+	return f.Type + ": " + message
+}
+
+// wantsBody reports whether the message should have a body
+func (f MessageFormat) wantsBody() bool {
+	return f.Body || f.BodyLanguage != ""
+}
+
+// instructions returns the prompt lines describing the expected message shape
+func (f MessageFormat) instructions() string {
+	subjectLang := f.SubjectLanguage
+	if subjectLang == "" {
+		subjectLang = "English"
+	}
+
+	hint := f.conventionRules()
+	if len(f.Examples) > 0 {
+		hint += "\nRecent commit subjects in this repository. Follow their style (types, scopes, casing, tense, and length) without copying them:\n- " + strings.Join(f.Examples, "\n- ")
+	}
+	if f.Issue != "" {
+		hint += "\nThe changes work on this issue. Use it to explain why, but describe what the diff actually does, and don't add issue references yourself:\n" + f.Issue
+	}
+	if f.RepoMap != "" {
+		hint += "\nLayout of the repository. Use its directory and package names for scopes and components:\n" + f.RepoMap
+	}
+	if f.Hint != "" {
+		hint += "\nGuidance from the author for the message: " + f.Hint
+	}
+
+	if !f.wantsBody() {
+		text := "The commit message must be a single line."
+		if f.SubjectLanguage != "" {
+			text += fmt.Sprintf(" Write it in %s.", subjectLang)
+		}
+		return text + ` Do NOT include any "Co-Authored-By" trailers or attribution.` + hint
+	}
+
+	bodyLang := f.BodyLanguage
+	if bodyLang == "" {
+		bodyLang = subjectLang
+	}
+
+	return fmt.Sprintf(`The commit message must be a subject line, then a blank line, then a short body (2-6 lines, bullet points allowed) explaining what changed and why.
+Write the subject line in %s, keeping the Conventional Commits type and scope untranslated.
+Write the body in %s.
+Do NOT include any "Co-Authored-By" trailers or attribution.%s`, subjectLang, bodyLang, hint)
+}
+
+// SplitMessage splits a commit message into its subject line and body
+func SplitMessage(message string) (subject, body string) {
+	message = strings.TrimSpace(message)
+	parts := strings.SplitN(message, "\n", 2)
+	subject = strings.TrimSpace(parts[0])
+	if len(parts) == 2 {
+		body = strings.TrimSpace(parts[1])
+	}
+	return subject, body
+}
+
+// ValidateMessage checks that a generated message matches the requested format
+func ValidateMessage(message string, format MessageFormat) error {
+	subject, body := SplitMessage(message)
+
+	if subject == "" {
+		return fmt.Errorf("commit message is empty")
+	}
+	if len([]rune(subject)) > format.maxSubjectLength() {
+		return fmt.Errorf("subject line is longer than %d characters", format.maxSubjectLength())
+	}
+	if problems := format.conventionProblems(subject); len(problems) > 0 {
+		return errors.New(problems[0])
+	}
+	if !matchesLanguage(subject, format.SubjectLanguage) {
+		return fmt.Errorf("subject line is not written in %s", format.SubjectLanguage)
+	}
+
+	if !format.wantsBody() {
+		return nil
+	}
+
+	if body == "" {
+		return fmt.Errorf("commit message has no body")
+	}
+
+	bodyLang := format.BodyLanguage
+	if bodyLang == "" {
+		bodyLang = format.SubjectLanguage
+	}
+	if !matchesLanguage(body, bodyLang) {
+		return fmt.Errorf("body is not written in %s", bodyLang)
+	}
+
+	return nil
+}
+
+// conventionalSubject matches "type(scope)!: description"
+var conventionalSubject = regexp.MustCompile(`^[a-z]+(\([^()]+\))?!?: \S`)
+
+// LintMessage checks a hand-written commit message against the same rules
+// generated messages follow, returning every problem found
+func LintMessage(message string, format MessageFormat) []string {
+	var problems []string
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	subject, body := SplitMessage(message)
+
+	if subject == "" {
+		return []string{"commit message is empty"}
+	}
+	// Messages written by git itself
+	for _, prefix := range []string{"Merge ", "Revert ", "fixup! ", "squash! ", "amend! "} {
+		if strings.HasPrefix(subject, prefix) {
+			return nil
+		}
+	}
+	if !conventionalSubject.MatchString(subject) {
+		problems = append(problems, `subject doesn't follow Conventional Commits ("type(scope): description", e.g. "fix: handle empty diff")`)
+	}
+	if n := len([]rune(subject)); n > format.maxSubjectLength() {
+		problems = append(problems, fmt.Sprintf("subject line is %d characters (max %d)", n, format.maxSubjectLength()))
+	}
+	problems = append(problems, format.conventionProblems(subject)...)
+	if !matchesLanguage(subject, format.SubjectLanguage) {
+		problems = append(problems, fmt.Sprintf("subject line is not written in %s", format.SubjectLanguage))
+	}
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		problems = append(problems, "subject and body must be separated by a blank line")
+	}
+
+	if format.wantsBody() {
+		bodyLang := format.BodyLanguage
+		if bodyLang == "" {
+			bodyLang = format.SubjectLanguage
+		}
+		if body == "" {
+			problems = append(problems, "commit message has no body")
+		} else if !matchesLanguage(body, bodyLang) {
+			problems = append(problems, fmt.Sprintf("body is not written in %s", bodyLang))
+		}
+	}
+
+	return problems
+}
+
+// matchesLanguage does a cheap script check for languages we can recognize.
+// Unknown languages always match.
+func matchesLanguage(text string, language string) bool {
+	switch strings.ToLower(language) {
+	case "ja", "japanese":
+		return containsScript(text, unicode.Hiragana, unicode.Katakana, unicode.Han)
+	case "zh", "chinese":
+		return containsScript(text, unicode.Han)
+	case "ko", "korean":
+		return containsScript(text, unicode.Hangul)
+	case "ru", "russian", "uk", "ukrainian":
+		return containsScript(text, unicode.Cyrillic)
+	case "en", "english":
+		// Code identifiers may appear anywhere, so only reject text made up
+		// mostly of non-Latin letters
+		latin, other := 0, 0
+		for _, r := range text {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			if unicode.Is(unicode.Latin, r) {
+				latin++
+			} else {
+				other++
+			}
+		}
+		return other <= latin
+	default:
+		return true
+	}
+}
+
+func containsScript(text string, tables ...*unicode.RangeTable) bool {
+	for _, r := range text {
+		if unicode.In(r, tables...) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rewrite is a find/replace rule for generated messages
+type Rewrite struct {
+	Find    *regexp.Regexp
+	Replace string // Expanded as in regexp.Regexp.Expand
+	Case    string // "lower" or "upper" changes the case of the replacement
+}
+
+// apply replaces every match of the rule in message
+func (r Rewrite) apply(message string) string {
+	var out []byte
+	last := 0
+	for _, m := range r.Find.FindAllStringSubmatchIndex(message, -1) {
+		out = append(out, message[last:m[0]]...)
+		replaced := string(r.Find.ExpandString(nil, r.Replace, message, m))
+		switch r.Case {
+		case "lower":
+			replaced = strings.ToLower(replaced)
+		case "upper":
+			replaced = strings.ToUpper(replaced)
+		}
+		out = append(out, replaced...)
+		last = m[1]
+	}
+	return string(out) + message[last:]
+}
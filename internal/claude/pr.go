@@ -0,0 +1,107 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PRDescription is a pull request title and body generated by Claude
+type PRDescription struct {
+	Title   string
+	Summary string
+	Testing string
+}
+
+// Body returns the markdown body of the pull request (without the title)
+func (d PRDescription) Body() string {
+	var b strings.Builder
+	b.WriteString("## Summary\n\n")
+	b.WriteString(d.Summary)
+	if d.Testing != "" {
+		b.WriteString("\n\n## Test notes\n\n")
+		b.WriteString(d.Testing)
+	}
+	return b.String()
+}
+
+// Markdown returns the full description with the title as a heading
+func (d PRDescription) Markdown() string {
+	return fmt.Sprintf("# %s\n\n%s\n", d.Title, d.Body())
+}
+
+// GeneratePRDescription asks Claude for a pull request title, summary, and test notes
+// based on the branch diff and its commit log.
+func GeneratePRDescription(ctx context.Context, diff string, log string, model string, useSummaryMode bool) (PRDescription, error) {
+	if diff == "" {
+		return PRDescription{}, fmt.Errorf("no changes detected")
+	}
+
+	diffLabel := "Diff"
+	if useSummaryMode {
+		diffLabel = "Diff Summary (large changeset, showing changed files and line counts only)"
+	}
+
+	prompt := fmt.Sprintf(`Write a pull request description for the following branch changes.
+
+Respond in exactly this format and nothing else:
+TITLE: <one-line title following Conventional Commits, e.g. feat: ...>
+SUMMARY:
+<2-6 markdown bullet points describing what changed and why>
+TESTING:
+<markdown bullet points describing how to test or what was tested>
+
+Do NOT include any "Co-Authored-By" trailers or attribution.
+
+Commits:
+%s
+
+%s:
+%s`, log, diffLabel, diff)
+
+	result, err := runClaude(ctx, prompt, model, nil)
+	if err != nil {
+		return PRDescription{}, err
+	}
+
+	desc := parsePRDescription(result)
+	if desc.Title == "" {
+		return PRDescription{}, fmt.Errorf("could not parse pull request description from Claude output: %s", strings.TrimSpace(result))
+	}
+	return desc, nil
+}
+
+// parsePRDescription extracts the TITLE/SUMMARY/TESTING sections from Claude's output
+func parsePRDescription(output string) PRDescription {
+	var desc PRDescription
+	var summary, testing []string
+	var section *[]string
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		upper := strings.ToUpper(trimmed)
+		switch {
+		case strings.HasPrefix(upper, "TITLE:"):
+			desc.Title = strings.TrimSpace(trimmed[len("TITLE:"):])
+			section = nil
+		case strings.HasPrefix(upper, "SUMMARY:"):
+			section = &summary
+			if rest := strings.TrimSpace(trimmed[len("SUMMARY:"):]); rest != "" {
+				summary = append(summary, rest)
+			}
+		case strings.HasPrefix(upper, "TESTING:"):
+			section = &testing
+			if rest := strings.TrimSpace(trimmed[len("TESTING:"):]); rest != "" {
+				testing = append(testing, rest)
+			}
+		default:
+			if section != nil {
+				*section = append(*section, strings.TrimRight(line, " \t\r"))
+			}
+		}
+	}
+
+	desc.Summary = strings.TrimSpace(strings.Join(summary, "\n"))
+	desc.Testing = strings.TrimSpace(strings.Join(testing, "\n"))
+	return desc
+}
@@ -0,0 +1,132 @@
+package claude
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quaywin/claude-commit/internal/debuglog"
+)
+
+// RateLimitRetries is how many times a rate-limited call is retried
+var RateLimitRetries = 4
+
+// RateLimitFile, when set, is shared by every cc process: a rate limit hit
+// by one makes the others wait until it is over instead of adding to it
+var RateLimitFile string
+
+// OnRateLimit, when set, waits out a rate limit (e.g. showing a countdown)
+// and returns early with ctx's error. Without it the wait is silent.
+var OnRateLimit func(ctx context.Context, wait time.Duration) error
+
+// rateLimited matches the errors of rate-limited calls
+var rateLimited = regexp.MustCompile(`(?i)rate.?limit|too many requests|\b429\b`)
+
+// retryAfter finds a suggested wait in seconds, e.g. "retry after 30 seconds"
+var retryAfter = regexp.MustCompile(`(?i)retry.after\D{0,10}(\d+)`)
+
+const (
+	backoffBase = 5 * time.Second
+	backoffMax  = 2 * time.Minute
+)
+
+// runWithBackoff is runModel, waiting out rate limits: first those other cc
+// processes ran into, then its own with exponential backoff and jitter
+func runWithBackoff(ctx context.Context, prompt string, model string, progressWriter io.Writer) (string, error) {
+	for attempt := 0; ; attempt++ {
+		if wait := time.Until(sharedRateLimit()); wait > 0 {
+			debuglog.Log("waiting for a rate limit hit by another run", "wait", wait)
+			if err := pause(ctx, wait); err != nil {
+				return "", err
+			}
+		}
+		output, err := runModel(ctx, prompt, model, progressWriter)
+		if err == nil || !rateLimited.MatchString(err.Error()) || attempt >= RateLimitRetries {
+			return output, err
+		}
+		wait := backoff(attempt, err)
+		debuglog.Log("claude rate limited", "model", model, "attempt", attempt+1, "wait", wait, "error", err)
+		shareRateLimit(time.Now().Add(wait))
+		if err := pause(ctx, wait); err != nil {
+			return "", err
+		}
+	}
+}
+
+// backoff is the wait before retry attempt+1: the wait the error asks for,
+// or an exponentially growing one, with up to 25% jitter so that waiting
+// runs don't all retry at once
+func backoff(attempt int, err error) time.Duration {
+	wait := backoffBase << attempt
+	if m := retryAfter.FindStringSubmatch(err.Error()); m != nil {
+		if seconds, convErr := strconv.Atoi(m[1]); convErr == nil {
+			wait = time.Duration(seconds) * time.Second
+		}
+	}
+	wait = min(wait, backoffMax)
+	return wait + time.Duration(rand.Int64N(int64(wait/4)+1))
+}
+
+// pause waits for d, or until ctx is done
+func pause(ctx context.Context, d time.Duration) error {
+	if OnRateLimit != nil {
+		return OnRateLimit(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sharedRateLimit returns when the rate limit recorded in RateLimitFile is
+// over (zero without one)
+func sharedRateLimit() time.Time {
+	if RateLimitFile == "" {
+		return time.Time{}
+	}
+	data, err := os.ReadFile(RateLimitFile)
+	if err != nil {
+		return time.Time{}
+	}
+	until, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}
+	}
+	return until
+}
+
+// shareRateLimit records in RateLimitFile that calls should wait until until
+func shareRateLimit(until time.Time) {
+	if RateLimitFile == "" || !until.After(sharedRateLimit()) {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(RateLimitFile), 0755); err != nil {
+		return
+	}
+	// Write and rename, so other processes never read half a file
+	tmp, err := os.CreateTemp(filepath.Dir(RateLimitFile), ".ratelimit-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.WriteString(until.Format(time.RFC3339Nano) + "\n")
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), RateLimitFile)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		debuglog.Log("writing the rate limit file", "error", err)
+	}
+}
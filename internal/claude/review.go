@@ -0,0 +1,89 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Review is the result of a review-only run
+type Review struct {
+	Risk        string    // low, medium, or high
+	Summary     string    // One or two sentences on what the changes do
+	Findings    []Finding // Bugs, security risks, and other problems
+	Suggestions []string  // Non-blocking improvements
+}
+
+// ReviewChanges asks Claude to review a diff without generating a commit message.
+func ReviewChanges(ctx context.Context, diff string, model string, useSummaryMode bool) (*Review, error) {
+	if diff == "" {
+		return nil, fmt.Errorf("no changes detected")
+	}
+
+	diffLabel := "Diff"
+	if useSummaryMode {
+		diffLabel = "Diff Summary (large changeset, showing changed files and line counts only)"
+	}
+
+	prompt := fmt.Sprintf(`Review the following git changes as a careful senior engineer would before a human code review.
+Look for bugs, security risks, missing error handling, and style problems.
+Each finding's <severity> is one of: critical (bugs, data loss, security risks), warning (likely problems), info (minor style notes).
+
+Respond in exactly this format and nothing else:
+RISK: <low|medium|high>
+SUMMARY: <one or two sentences on what the changes do>
+FINDINGS:
+- <severity>: <one problem per line, with file names where possible; write "- none" if there are none>
+SUGGESTIONS:
+- <one non-blocking improvement per line; write "- none" if there are none>
+%s
+%s:
+%s`, reviewInstructions(), diffLabel, diff)
+
+	result, err := runClaude(ctx, prompt, model, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	review := parseReview(result)
+	if review.Risk == "" && review.Summary == "" && len(review.Findings) == 0 && len(review.Suggestions) == 0 {
+		return nil, fmt.Errorf("could not parse review from Claude output: %s", strings.TrimSpace(result))
+	}
+	return review, nil
+}
+
+// parseReview extracts the RISK/SUMMARY/FINDINGS/SUGGESTIONS sections from Claude's output
+func parseReview(output string) *Review {
+	review := &Review{}
+	var section string
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		upper := strings.ToUpper(trimmed)
+		switch {
+		case strings.HasPrefix(upper, "RISK:"):
+			review.Risk = strings.ToLower(strings.TrimSpace(trimmed[len("RISK:"):]))
+			section = ""
+		case strings.HasPrefix(upper, "SUMMARY:"):
+			review.Summary = strings.TrimSpace(trimmed[len("SUMMARY:"):])
+			section = ""
+		case strings.HasPrefix(upper, "FINDINGS:"), strings.HasPrefix(upper, "ISSUES:"):
+			section = "findings"
+		case strings.HasPrefix(upper, "SUGGESTIONS:"):
+			section = "suggestions"
+		default:
+			item := strings.TrimSpace(strings.TrimLeft(trimmed, "-*•"))
+			if item == "" || strings.EqualFold(item, "none") {
+				continue
+			}
+			switch section {
+			case "findings":
+				review.Findings = append(review.Findings, parseFinding(item))
+			case "suggestions":
+				review.Suggestions = append(review.Suggestions, item)
+			}
+		}
+	}
+
+	return review
+}
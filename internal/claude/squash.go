@@ -0,0 +1,44 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+)
+
+// SquashMessage asks Claude for the one commit message that replaces a
+// branch's commits in a squash merge, from their messages (oldest first)
+// and the combined diff
+func SquashMessage(ctx context.Context, log string, diff string, model string, useSummaryMode bool, format MessageFormat) (string, error) {
+	if diff == "" {
+		return "", fmt.Errorf("no changes detected")
+	}
+
+	diffLabel := "Diff"
+	if useSummaryMode {
+		diffLabel = "Diff Summary (large changeset, showing changed files and line counts only)"
+	}
+
+	prompt := fmt.Sprintf(`The following commits of a branch are squash merged into one commit. Write its commit message.
+Describe what the branch as a whole changes, based on the combined diff: leave out steps that later commits
+undid or fixed (e.g. "fix typo", "address review", wip commits). Follow the Conventional Commits
+specification (e.g., feat: ..., fix: ..., chore: ...).
+%s
+Return only the commit message, with no explanations or code fences.
+
+Commits:
+%s
+
+%s:
+%s`, format.instructions(), log, diffLabel, diff)
+
+	output, err := runClaude(ctx, prompt, model, nil)
+	if err != nil {
+		return "", err
+	}
+
+	message := format.postProcess(output)
+	if err := ValidateMessage(message, format); err != nil {
+		return "", fmt.Errorf("invalid commit message from Claude: %w", err)
+	}
+	return message, nil
+}
@@ -0,0 +1,242 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Command is a single `cc <name>` subcommand
+type Command struct {
+	Name    string
+	Aliases []string
+	Usage   string // Argument synopsis shown after the command name, e.g. "[--base main]"
+	Short   string // One-line description for the command list
+	Long    string // Optional extra help text shown by --help
+
+	// Flags registers the command's flags on fs
+	Flags func(fs *flag.FlagSet)
+	// Run executes the command with the remaining positional arguments
+	Run func(args []string) error
+
+	// NeedsRepo marks commands that operate on the current git repository
+	NeedsRepo bool
+}
+
+// App dispatches command-line arguments to commands
+type App struct {
+	Name        string
+	Description string
+	Commands    []*Command
+	// Default is the command run when the first argument is not a command name
+	Default string
+	// GlobalFlags registers flags accepted by every command
+	GlobalFlags func(fs *flag.FlagSet)
+	// Before runs after flags are parsed and before the command runs
+	Before func(cmd *Command) error
+
+	Output io.Writer
+}
+
+// Find returns the command with the given name or alias
+func (a *App) Find(name string) *Command {
+	for _, cmd := range a.Commands {
+		if cmd.Name == name {
+			return cmd
+		}
+		for _, alias := range cmd.Aliases {
+			if alias == name {
+				return cmd
+			}
+		}
+	}
+	return nil
+}
+
+// Run parses args (without the program name) and runs the selected command.
+// Flags may appear before or after positional arguments; everything after
+// "--" is passed through as positional arguments.
+func (a *App) Run(args []string) error {
+	if a.Output == nil {
+		a.Output = os.Stdout
+	}
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "help":
+			if len(args) > 1 {
+				if cmd := a.Find(args[1]); cmd != nil {
+					a.printCommandHelp(cmd, a.newFlagSet(cmd))
+					return nil
+				}
+				return fmt.Errorf("unknown command: %s", args[1])
+			}
+			a.PrintHelp()
+			return nil
+		case "-h", "--help":
+			a.PrintHelp()
+			return nil
+		}
+	}
+
+	// The command is the first non-flag argument, so "cc -f plan" works like "cc plan -f"
+	cmd := a.Find(a.Default)
+	if cmd == nil {
+		a.PrintHelp()
+		return fmt.Errorf("no command given")
+	}
+	if i := a.commandIndex(a.newFlagSet(cmd), args); i >= 0 {
+		cmd = a.Find(args[i])
+		args = append(append([]string(nil), args[:i]...), args[i+1:]...)
+	}
+
+	fs := a.newFlagSet(cmd)
+	positional, err := parseInterleaved(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		a.printCommandHelp(cmd, fs)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%v (run '%s %s --help' for usage)", err, a.Name, cmd.Name)
+	}
+
+	if a.Before != nil {
+		if err := a.Before(cmd); err != nil {
+			return err
+		}
+	}
+
+	return cmd.Run(positional)
+}
+
+// commandIndex returns the index of the command name in args, or -1 when
+// the first positional argument is not one. The values of flags in fs (the
+// global flags and those of the default command) are skipped, so in
+// "cc --profile work version" the command is "version", not "work".
+func (a *App) commandIndex(fs *flag.FlagSet, args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return -1
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if a.Find(arg) != nil {
+				return i
+			}
+			return -1
+		}
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		if f := fs.Lookup(name); f != nil {
+			if boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !boolFlag.IsBoolFlag() {
+				i++
+			}
+		}
+	}
+	return -1
+}
+
+func (a *App) newFlagSet(cmd *Command) *flag.FlagSet {
+	fs := flag.NewFlagSet(a.Name+" "+cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if a.GlobalFlags != nil {
+		a.GlobalFlags(fs)
+	}
+	if cmd.Flags != nil {
+		cmd.Flags(fs)
+	}
+	return fs
+}
+
+// parseInterleaved parses flags anywhere in args and returns the positional arguments
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	var passthrough []string
+	for i, arg := range args {
+		if arg == "--" {
+			passthrough = append(passthrough, args[i+1:]...)
+			args = args[:i]
+			break
+		}
+	}
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	return append(positional, passthrough...), nil
+}
+
+// PrintHelp prints the list of commands
+func (a *App) PrintHelp() {
+	fmt.Fprintf(a.Output, "%s - %s\n\n", a.Name, a.Description)
+	fmt.Fprintf(a.Output, "Usage:\n  %s [command] [flags]\n\n", a.Name)
+	fmt.Fprintln(a.Output, "Commands:")
+
+	width := 0
+	for _, cmd := range a.Commands {
+		if len(cmd.Name) > width {
+			width = len(cmd.Name)
+		}
+	}
+	for _, cmd := range a.Commands {
+		short := cmd.Short
+		if cmd.Name == a.Default {
+			short += " (default)"
+		}
+		fmt.Fprintf(a.Output, "  %-*s  %s\n", width, cmd.Name, short)
+	}
+
+	fmt.Fprintf(a.Output, "\nRun '%s <command> --help' for details about a command.\n", a.Name)
+}
+
+func (a *App) printCommandHelp(cmd *Command, fs *flag.FlagSet) {
+	usage := fmt.Sprintf("%s %s", a.Name, cmd.Name)
+	if cmd.Usage != "" {
+		usage += " " + cmd.Usage
+	}
+	fmt.Fprintf(a.Output, "Usage:\n  %s\n\n%s\n", usage, cmd.Short)
+	if len(cmd.Aliases) > 0 {
+		fmt.Fprintf(a.Output, "\nAliases: %s\n", strings.Join(cmd.Aliases, ", "))
+	}
+	if cmd.Long != "" {
+		fmt.Fprintf(a.Output, "\n%s\n", strings.TrimSpace(cmd.Long))
+	}
+
+	var names, usages []string
+	width := 0
+	fs.VisitAll(func(f *flag.Flag) {
+		name := "--" + f.Name
+		if len(f.Name) == 1 {
+			name = "-" + f.Name
+		}
+		if boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !boolFlag.IsBoolFlag() {
+			name += " <value>"
+		}
+		if len(name) > width {
+			width = len(name)
+		}
+		names = append(names, name)
+		usages = append(usages, f.Usage)
+	})
+
+	if len(names) > 0 {
+		fmt.Fprintln(a.Output, "\nFlags:")
+		for i, name := range names {
+			fmt.Fprintf(a.Output, "  %-*s  %s\n", width, name, usages[i])
+		}
+	}
+}
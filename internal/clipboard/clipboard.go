@@ -0,0 +1,59 @@
+package clipboard
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Copy places text on the system clipboard using the platform's clipboard tool
+func Copy(text string) error {
+	name, args, err := clipboardCommand()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(text)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w, stderr: %s", name, err, stderr.String())
+	}
+	return nil
+}
+
+// clipboardCommand picks the clipboard tool available on this system
+func clipboardCommand() (string, []string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return "pbcopy", nil, nil
+	case "windows":
+		return "clip", nil, nil
+	}
+
+	// Linux/BSD: prefer Wayland, then X11 tools, then WSL's clip.exe
+	candidates := []struct {
+		name string
+		args []string
+	}{
+		{"wl-copy", nil},
+		{"xclip", []string{"-selection", "clipboard"}},
+		{"xsel", []string{"--clipboard", "--input"}},
+		{"clip.exe", nil},
+	}
+	for _, c := range candidates {
+		if c.name == "wl-copy" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			continue
+		}
+		if _, err := exec.LookPath(c.name); err == nil {
+			return c.name, c.args, nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("no clipboard tool found (install wl-clipboard, xclip, or xsel)")
+}
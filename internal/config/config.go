@@ -2,20 +2,526 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	Model string `json:"model"`
+
+	// MessageBody asks Claude for a body below the subject line
+	MessageBody bool `json:"message_body,omitempty"`
+	// SubjectLanguage and BodyLanguage select the language of each part of
+	// the message (e.g. "English" subject, "Japanese" body). Setting
+	// BodyLanguage implies MessageBody.
+	SubjectLanguage string `json:"subject_language,omitempty"`
+	BodyLanguage    string `json:"body_language,omitempty"`
+
+	// BlockSeverity is the lowest finding severity (info, warning, critical)
+	// that blocks a commit unless --force is given
+	BlockSeverity string `json:"block_severity,omitempty"`
+
+	// BranchPattern shapes names created by `cc branch`; {type} is replaced
+	// with feat/fix/... and {name} with the kebab-case summary
+	BranchPattern string `json:"branch_pattern,omitempty"`
+
+	// Edit opens the generated message in the editor before committing
+	Edit bool `json:"edit,omitempty"`
+
+	// NoPush commits without pushing unless --no-push=false is given
+	NoPush bool `json:"no_push,omitempty"`
+	// PullBeforePush fetches and rebases onto the remote branch before
+	// pushing, so the push doesn't fail as a non-fast-forward
+	PullBeforePush bool `json:"pull_before_push,omitempty"`
+	// SetUpstream pushes branches without an upstream to origin and tracks
+	// them without asking first
+	SetUpstream bool `json:"set_upstream,omitempty"`
+	// PushRemote and PushTo push to another remote (e.g. a fork) or remote
+	// branch than the upstream. Empty values keep the upstream's.
+	PushRemote string `json:"push_remote,omitempty"`
+	PushTo     string `json:"push_to,omitempty"`
+	// ProtectedBranches are branch names or patterns (e.g. "release/*") that
+	// --force-push refuses to overwrite. Empty means the remote's default
+	// branch, main, and master.
+	ProtectedBranches []string `json:"protected_branches,omitempty"`
+	// NoReviewNotes stops cc from attaching the review of each commit as a
+	// git note under refs/notes/cc; PushReviewNotes pushes that ref along
+	// with the branch
+	NoReviewNotes   bool `json:"no_review_notes,omitempty"`
+	PushReviewNotes bool `json:"push_review_notes,omitempty"`
+	// Ignore lists pathspec patterns (e.g. "*.lock", "vendor/") left out of
+	// the diff sent to Claude. Matching files are still committed.
+	Ignore []string `json:"ignore,omitempty"`
+	// Collapse adjusts the lockfiles and generated files (e.g. go.sum,
+	// "*.pb.go") whose diff is replaced by a one-line summary: patterns are
+	// added to the built-in list, "!pattern" removes one, and "!*" clears it
+	Collapse []string `json:"collapse,omitempty"`
+
+	// DiffContext is the number of unchanged lines shown around each change
+	// in the diff sent to Claude (git diff -U<n>): fewer saves tokens on huge
+	// diffs, more helps the review understand the surrounding code. Empty
+	// keeps git's default of 3.
+	DiffContext string `json:"diff_context,omitempty"`
+
+	// SummaryTokens is the estimated size of the full diff (in tokens)
+	// above which Claude gets a stat summary instead. Empty keeps the
+	// default of 20000; "0" means no size limit.
+	SummaryTokens string `json:"summary_tokens,omitempty"`
+	// SummaryThreshold is the number of changed files from which Claude gets
+	// the stat summary however small the diff is. Empty or "0" only goes by
+	// size.
+	SummaryThreshold string `json:"summary_threshold,omitempty"`
+	// FileContext sends small modified files whole next to the diff, so the
+	// review understands the functions being changed
+	FileContext FileContextConfig `json:"file_context,omitempty"`
+
+	// IgnoreWhitespace leaves whitespace changes out of the diff sent to
+	// Claude; SkipWhitespaceOnly (which implies it) also leaves files whose
+	// changes are all whitespace out of the commit
+	IgnoreWhitespace   bool `json:"ignore_whitespace,omitempty"`
+	SkipWhitespaceOnly bool `json:"skip_whitespace_only,omitempty"`
+
+	// LargeFileLimit is the size (e.g. "10MB", "500KB") above which a changed
+	// file needs confirmation before it is staged. "0" disables the check.
+	LargeFileLimit string `json:"large_file_limit,omitempty"`
+
+	// GitBackend is "exec" (the default, runs git) or "go-git" (in-process,
+	// in builds with the gogit tag)
+	GitBackend string `json:"git_backend,omitempty"`
+
+	// CostLimit is the estimated cost in USD (e.g. "0.25") of a single review
+	// above which cc asks first, or switches to summary mode when
+	// CostLimitAction is "summary". Empty or "0" disables the check.
+	CostLimit       string `json:"cost_limit,omitempty"`
+	CostLimitAction string `json:"cost_limit_action,omitempty"`
+
+	// NoOfflineFallback fails instead of writing a rule-based message when
+	// the claude CLI is missing or can't reach the API
+	NoOfflineFallback bool `json:"no_offline_fallback,omitempty"`
+	// OfflineFallback also writes the rule-based message without a terminal
+	// (CI, --yes, --json), where it otherwise fails
+	OfflineFallback bool `json:"offline_fallback,omitempty"`
+
+	// UILanguage is the language of cc's own output: en, zh, ja, es, or
+	// "auto" to follow the locale. Commit messages use SubjectLanguage.
+	UILanguage string `json:"ui_language,omitempty"`
+
+	// Telemetry sends anonymous usage events (opt-in, see `cc telemetry`)
+	Telemetry bool `json:"telemetry,omitempty"`
+
+	// NoHistory turns off the run log in ~/.claude-commit/history.jsonl
+	NoHistory bool `json:"no_history,omitempty"`
+
+	// WIPModel writes the one-line descriptions of `cc wip` (default haiku)
+	WIPModel string `json:"wip_model,omitempty"`
+	// Models picks a model per task; tasks without one use Model
+	Models ModelsConfig `json:"models,omitempty"`
+	// FallbackModels are tried in order when the model is overloaded or the
+	// API fails with a server error, e.g. ["haiku"]
+	FallbackModels []string `json:"fallback_models,omitempty"`
+
+	// UpdateChannel is the release channel `cc update` follows: "stable"
+	// (the default) or "beta", which includes pre-releases
+	UpdateChannel string `json:"update_channel,omitempty"`
+
+	// SignOff adds a Signed-off-by trailer (git commit --signoff)
+	SignOff bool `json:"sign_off,omitempty"`
+	// Trailers are appended to every commit message, e.g. "Reviewed-by: Jane <jane@example.com>"
+	Trailers []string `json:"trailers,omitempty"`
+	// Attribution adds a Commit-Message-Generated-By trailer naming cc and
+	// the model to generated messages, for teams that disclose AI-written text
+	Attribution bool `json:"attribution,omitempty"`
+
+	// Checklist are the team's review rules, e.g. "all new endpoints need
+	// authz"; Claude reports each violation as a finding
+	Checklist []string `json:"checklist,omitempty"`
+
+	// DebugPatterns are regular expressions for leftover debugging
+	// statements in added lines, by file extension (e.g. "go", "js"). Each
+	// extension replaces cc's built-in patterns; an empty list turns it off.
+	DebugPatterns map[string][]string `json:"debug_patterns,omitempty"`
+
+	// Todos is the policy for new TODO/FIXME/HACK comments
+	Todos TodosConfig `json:"todos,omitempty"`
+
+	// TestsRequired flags source changes that come without test changes
+	TestsRequired TestsRequiredConfig `json:"tests_required,omitempty"`
+
+	// Rewrites are find/replace rules applied to every generated message,
+	// in order, after cc's own cleanup
+	Rewrites []RewriteRule `json:"rewrites,omitempty"`
+
+	// Packages maps package names to their directories (relative to the
+	// repository root) for --per-package, instead of the go.work modules
+	// and package.json workspaces
+	Packages map[string]string `json:"packages,omitempty"`
+
+	// IgnoreConventions stops cc from following the commitlint config,
+	// commit template, and CONTRIBUTING.md guidelines found in a repository
+	IgnoreConventions bool `json:"ignore_conventions,omitempty"`
+
+	// RepoMap shows the model an outline of the repository (languages,
+	// frameworks, top-level directories, key files), so messages use the
+	// right component names in monorepos and unfamiliar layouts
+	RepoMap bool `json:"repo_map,omitempty"`
+
+	// Examples shows the model recent commit subjects to imitate
+	Examples ExamplesConfig `json:"examples,omitempty"`
+
+	// Commands are shell commands cc runs around a commit
+	Commands CommandsConfig `json:"commands,omitempty"`
+	// Checks are named commands (format, vet, tests) run before the review;
+	// the first that fails stops the commit
+	Checks []CheckConfig `json:"checks,omitempty"`
+
+	PullRequest PullRequestConfig `json:"pull_request,omitempty"`
+	Issues      IssuesConfig      `json:"issues,omitempty"`
+	Network     NetworkConfig     `json:"network,omitempty"`
+	Timeouts    TimeoutConfig     `json:"timeouts,omitempty"`
+	Watch       WatchConfig       `json:"watch,omitempty"`
+
+	// Profile selects one of Profiles by default; --profile and CC_PROFILE override it
+	Profile string `json:"profile,omitempty"`
+	// Profiles are named sets of settings (e.g. "work", "oss") merged over
+	// the rest of the config when selected
+	Profiles map[string]json.RawMessage `json:"profiles,omitempty"`
+}
+
+// ExamplesConfig picks the recent commit subjects shown to the model, so
+// generated messages follow the repository's voice, types, and scopes.
+// Merges, reverts, fixups, WIP checkpoints, and very short or long subjects
+// are never used.
+type ExamplesConfig struct {
+	Count string `json:"count,omitempty"` // How many subjects (default 10); "0" turns examples off
+	Match string `json:"match,omitempty"` // Regular expression a subject must match, e.g. "^(feat|fix)"
+}
+
+// ContextLines parses DiffContext, returning -1 for git's default
+func (c *Config) ContextLines() (int, error) {
+	if c.DiffContext == "" {
+		return -1, nil
+	}
+	n, err := strconv.Atoi(c.DiffContext)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid diff_context %q (use a number of lines, e.g. 3)", c.DiffContext)
+	}
+	return n, nil
+}
+
+// SummaryTokenLimit parses SummaryTokens, returning -1 for the default
+func (c *Config) SummaryTokenLimit() (int, error) {
+	if c.SummaryTokens == "" {
+		return -1, nil
+	}
+	n, err := strconv.Atoi(c.SummaryTokens)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid summary_tokens %q (use a number of tokens, or 0 for no limit)", c.SummaryTokens)
+	}
+	return n, nil
+}
+
+// SummaryFiles parses SummaryThreshold, where empty means 0
+func (c *Config) SummaryFiles() (int, error) {
+	if c.SummaryThreshold == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(c.SummaryThreshold)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid summary_threshold %q (use a number of files, or 0 to go by size only)", c.SummaryThreshold)
+	}
+	return n, nil
+}
+
+// DefaultExampleCount is the number of example subjects unless configured
+const DefaultExampleCount = 10
+
+// CountValue parses Count, falling back to the default
+func (e ExamplesConfig) CountValue() (int, error) {
+	if e.Count == "" {
+		return DefaultExampleCount, nil
+	}
+	n, err := strconv.Atoi(e.Count)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid examples.count %q (use a number; 0 turns examples off)", e.Count)
+	}
+	return n, nil
+}
+
+// FileContextConfig controls which modified files are sent whole
+type FileContextConfig struct {
+	Lines  string `json:"lines,omitempty"`  // Files up to this many lines are sent whole; empty or "0" turns it off
+	Tokens string `json:"tokens,omitempty"` // Cap on the estimated size of all of them (default 4000)
+}
+
+// LineLimit parses Lines, where empty means 0
+func (f FileContextConfig) LineLimit() (int, error) {
+	if f.Lines == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(f.Lines)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid file_context.lines %q (use a number of lines, or 0 to turn it off)", f.Lines)
+	}
+	return n, nil
+}
+
+// TokenLimit parses Tokens, returning -1 for the default
+func (f FileContextConfig) TokenLimit() (int, error) {
+	if f.Tokens == "" {
+		return -1, nil
+	}
+	n, err := strconv.Atoi(f.Tokens)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid file_context.tokens %q (use a number of tokens)", f.Tokens)
+	}
+	return n, nil
+}
+
+// ModelsConfig are the models of each task, e.g. haiku for small changes
+// and opus for security reviews. Empty fields fall back to Model.
+type ModelsConfig struct {
+	Message  string `json:"message,omitempty"`  // Text without a review: checkpoints, branch names, PR descriptions, message rewrites, and small commits
+	Review   string `json:"review,omitempty"`   // Commits and `cc review`
+	Security string `json:"security,omitempty"` // --security reviews (default Review)
+	// SmallLines is the number of changed lines up to which a commit is
+	// small enough for the Message model. Empty means commits always use
+	// the Review model.
+	SmallLines string `json:"small_lines,omitempty"`
+}
+
+// SmallLineCount parses SmallLines, returning -1 when it is empty
+func (m ModelsConfig) SmallLineCount() (int, error) {
+	if m.SmallLines == "" {
+		return -1, nil
+	}
+	n, err := strconv.Atoi(m.SmallLines)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid models.small_lines %q (use a number of changed lines)", m.SmallLines)
+	}
+	return n, nil
+}
+
+// TodosConfig controls what happens with the TODO/FIXME/HACK comments a
+// change adds; they are always listed
+type TodosConfig struct {
+	Action  string `json:"action,omitempty"`  // "block" stops the commit unless --force; empty only lists them
+	Context bool   `json:"context,omitempty"` // Tell Claude about them
 }
 
+// TestsRequiredConfig is the policy that changed source files come with
+// changed tests. A violation is reported as a finding: a warning with
+// "warn" and a critical finding, which blocks the commit, with "block".
+type TestsRequiredConfig struct {
+	Action string     `json:"action,omitempty"` // "warn" or "block"; empty turns the check off
+	Rules  []TestRule `json:"rules,omitempty"`  // Replace the built-in rules for Go, JavaScript/TypeScript, and Python
+}
+
+// TestRule maps source files to their tests. Globs match paths from the
+// repository root, with ** for any number of directories; in Tests, {dir},
+// {name}, and {ext} stand for the directory, base name without extension,
+// and extension of the source file.
+type TestRule struct {
+	Source string   `json:"source"` // e.g. "src/**/*.ts"
+	Tests  []string `json:"tests"`  // e.g. "test/**/{name}.test.ts"
+}
+
+// RewriteRule replaces the matches of a regular expression in generated
+// messages, e.g. {"find": "^bugfix", "replace": "fix"}
+type RewriteRule struct {
+	Find    string `json:"find"`           // Go regular expression; (?m) makes ^ and $ match at each line
+	Replace string `json:"replace"`        // Replacement, with $1 or ${name} for groups
+	Case    string `json:"case,omitempty"` // "lower" or "upper" changes the case of the replacement
+}
+
+// CommandsConfig lists shell commands run from the repository root. A
+// failing before_review command stops the commit unless --force is given;
+// after_push failures only warn.
+type CommandsConfig struct {
+	BeforeReview []string `json:"before_review,omitempty"` // e.g. "go test ./...", "npm run lint"
+	AfterPush    []string `json:"after_push,omitempty"`    // e.g. a script that posts to Slack
+}
+
+// CheckConfig is a named check, run with the shell from the repository root
+type CheckConfig struct {
+	Name string `json:"name"` // e.g. "vet"
+	Run  string `json:"run"`  // e.g. "go vet ./..."
+}
+
+// TimeoutConfig holds per-stage timeouts as duration strings (e.g. "30s", "2m").
+// Empty values use the defaults below; "0" disables the timeout.
+type TimeoutConfig struct {
+	Diff     string `json:"diff,omitempty"`     // Collecting changed files and diffs
+	Provider string `json:"provider,omitempty"` // Each call to the model
+	Commit   string `json:"commit,omitempty"`   // git commit, including hooks
+	Push     string `json:"push,omitempty"`     // git push
+}
+
+// Default per-stage timeouts
+const (
+	DefaultDiffTimeout     = time.Minute
+	DefaultProviderTimeout = 3 * time.Minute
+	DefaultCommitTimeout   = 2 * time.Minute
+	DefaultPushTimeout     = 2 * time.Minute
+)
+
+// Set overrides the timeout of the named stage, reporting whether the stage exists
+func (t *TimeoutConfig) Set(stage string, value string) bool {
+	switch stage {
+	case "diff":
+		t.Diff = value
+	case "provider":
+		t.Provider = value
+	case "commit":
+		t.Commit = value
+	case "push":
+		t.Push = value
+	default:
+		return false
+	}
+	return true
+}
+
+// Durations parses the configured timeouts, falling back to the defaults
+func (t TimeoutConfig) Durations() (diff, provider, commit, push time.Duration, err error) {
+	parse := func(name, value string, def time.Duration) time.Duration {
+		if err != nil || value == "" {
+			return def
+		}
+		d, parseErr := time.ParseDuration(value)
+		if value == "0" {
+			d, parseErr = 0, nil
+		}
+		if parseErr != nil || d < 0 {
+			err = fmt.Errorf("invalid %s timeout %q (use a duration like 30s or 2m)", name, value)
+			return def
+		}
+		return d
+	}
+
+	diff = parse("diff", t.Diff, DefaultDiffTimeout)
+	provider = parse("provider", t.Provider, DefaultProviderTimeout)
+	commit = parse("commit", t.Commit, DefaultCommitTimeout)
+	push = parse("push", t.Push, DefaultPushTimeout)
+	return diff, provider, commit, push, err
+}
+
+// DefaultLargeFileLimit is used when large_file_limit is empty
+const DefaultLargeFileLimit = 10 << 20
+
+// LargeFileBytes parses LargeFileLimit, falling back to the default
+func (c *Config) LargeFileBytes() (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(c.LargeFileLimit))
+	if text == "" {
+		return DefaultLargeFileLimit, nil
+	}
+
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		bytes  int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if number, found := strings.CutSuffix(text, unit.suffix); found {
+			text, multiplier = strings.TrimSpace(number), unit.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid large_file_limit %q (use a size like 10MB or 500KB, or 0 to disable)", c.LargeFileLimit)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// CostLimitUSD parses CostLimit; zero means no limit
+func (c *Config) CostLimitUSD() (float64, error) {
+	text := strings.TrimPrefix(strings.TrimSpace(c.CostLimit), "$")
+	if text == "" {
+		return 0, nil
+	}
+	limit, err := strconv.ParseFloat(text, 64)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("invalid cost_limit %q (use an amount in USD like 0.25, or 0 to disable)", c.CostLimit)
+	}
+	return limit, nil
+}
+
+// NetworkConfig routes the update check, the GitHub and GitLab APIs, and
+// the claude CLI through a proxy, and trusts an extra CA (e.g. a corporate
+// TLS-inspecting proxy). Empty values keep HTTPS_PROXY/NO_PROXY from the
+// environment and the system CAs.
+type NetworkConfig struct {
+	Proxy    string `json:"proxy,omitempty"`     // e.g. "http://proxy.example.com:8080"
+	NoProxy  string `json:"no_proxy,omitempty"`  // Comma-separated hosts that bypass the proxy
+	CABundle string `json:"ca_bundle,omitempty"` // PEM file trusted on top of the system CAs
+}
+
+// WatchConfig controls the checkpoint commits of `cc watch`
+type WatchConfig struct {
+	QuietPeriod string `json:"quiet_period,omitempty"` // How long the tree must stay unchanged, e.g. "30s"
+	Branch      string `json:"branch,omitempty"`       // Side branch; {branch} is the current branch
+}
+
+// Defaults for cc watch
 const (
-	DefaultModel   = "haiku"
-	ConfigDirName  = ".claude-commit"
-	ConfigFileName = "config.json"
+	DefaultWatchQuietPeriod = 30 * time.Second
+	DefaultWatchBranch      = "cc/checkpoints/{branch}"
 )
 
+// QuietPeriodDuration parses QuietPeriod, falling back to the default
+func (w WatchConfig) QuietPeriodDuration() (time.Duration, error) {
+	if w.QuietPeriod == "" {
+		return DefaultWatchQuietPeriod, nil
+	}
+	d, err := time.ParseDuration(w.QuietPeriod)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid watch.quiet_period %q (use a duration like 30s or 2m)", w.QuietPeriod)
+	}
+	return d, nil
+}
+
+// PullRequestConfig controls opening a GitHub pull request or GitLab merge
+// request after pushing. The integration is picked from the origin remote's host.
+type PullRequestConfig struct {
+	Enabled     bool     `json:"enabled,omitempty"`      // Open a pull request after every push
+	Base        string   `json:"base,omitempty"`         // Target branch (default: the remote's default branch)
+	Draft       bool     `json:"draft,omitempty"`        // Open pull requests as drafts
+	Reviewers   []string `json:"reviewers,omitempty"`    // Users (or GitHub "org/team" slugs) to request review from
+	GitHubToken string   `json:"github_token,omitempty"` // Falls back to GITHUB_TOKEN, GH_TOKEN, or `gh auth token`
+	GitLabToken string   `json:"gitlab_token,omitempty"` // Falls back to GITLAB_TOKEN
+	GitLabURL   string   `json:"gitlab_url,omitempty"`   // Base URL of a self-hosted GitLab, e.g. https://gitlab.example.com
+}
+
+// IssuesConfig controls fetching the issue a change works on (from
+// --issue or the branch name) from GitHub Issues or Linear
+type IssuesConfig struct {
+	Enabled     bool   `json:"enabled,omitempty"`      // Look for an issue reference in the branch name
+	Provider    string `json:"provider,omitempty"`     // github or linear; empty guesses from the reference
+	LinearToken string `json:"linear_token,omitempty"` // Falls back to LINEAR_API_KEY
+	Keyword     string `json:"keyword,omitempty"`      // Word of the closing reference (default Closes); "none" leaves it out
+}
+
+const (
+	DefaultModel         = "haiku"
+	DefaultBranchPattern = "{type}/{name}"
+	DefaultBlockSeverity = "critical"
+	ConfigDirName        = ".claude-commit"
+	ConfigFileName       = "config.json"
+	RepoConfigFileName   = ".claude-commit.json"
+)
+
+// ReadOnly makes Save fail instead of writing. Used by demo mode.
+var ReadOnly bool
+
+// Default returns the configuration used when no config file exists
+func Default() *Config {
+	return &Config{Model: DefaultModel, BlockSeverity: DefaultBlockSeverity}
+}
+
 func GetConfigDir() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -24,6 +530,16 @@ func GetConfigDir() (string, error) {
 	return filepath.Join(home, ConfigDirName), nil
 }
 
+// Exists reports whether the global config file has been created
+func Exists() bool {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(configDir, ConfigFileName))
+	return err == nil
+}
+
 func Load() (*Config, error) {
 	configDir, err := GetConfigDir()
 	if err != nil {
@@ -34,7 +550,7 @@ func Load() (*Config, error) {
 
 	// If file doesn't exist, return default config
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return &Config{Model: DefaultModel}, nil
+		return Default(), nil
 	}
 
 	data, err := os.ReadFile(configPath)
@@ -50,11 +566,41 @@ func Load() (*Config, error) {
 	if config.Model == "" {
 		config.Model = DefaultModel
 	}
+	if config.BlockSeverity == "" {
+		config.BlockSeverity = DefaultBlockSeverity
+	}
 
 	return &config, nil
 }
 
+// ApplyProfile merges the named profile over cfg
+func ApplyProfile(cfg *Config, name string) error {
+	raw, ok := cfg.Profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown profile %q (defined: %s)", name, strings.Join(ProfileNames(cfg), ", "))
+	}
+	if err := json.Unmarshal(raw, cfg); err != nil {
+		return fmt.Errorf("profile %s: %w", name, err)
+	}
+	cfg.Profile = name
+	return nil
+}
+
+// ProfileNames returns the names of the configured profiles, sorted
+func ProfileNames(cfg *Config) []string {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func Save(config *Config) error {
+	if ReadOnly {
+		return fmt.Errorf("refusing to save config: read-only mode")
+	}
+
 	configDir, err := GetConfigDir()
 	if err != nil {
 		return err
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Keys returns every settable key in dotted form (e.g. "pull_request.draft"), sorted
+func Keys() []string {
+	var keys []string
+	collectKeys(reflect.TypeOf(Config{}), "", &keys)
+	sort.Strings(keys)
+	return keys
+}
+
+func collectKeys(t reflect.Type, prefix string, keys *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonName(field)
+		if name == "" || field.Type.Kind() == reflect.Map {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			collectKeys(field.Type, prefix+name+".", keys)
+			continue
+		}
+		*keys = append(*keys, prefix+name)
+	}
+}
+
+// IsSecret reports whether a dotted key holds a credential, such as
+// pull_request.github_token, which listings must not print
+func IsSecret(key string) bool {
+	return strings.HasSuffix(key, "_token")
+}
+
+// Get returns the value of a dotted key as a string
+func Get(cfg *Config, key string) (string, error) {
+	value, err := lookup(cfg, key)
+	if err != nil {
+		return "", err
+	}
+	return formatValue(value), nil
+}
+
+// Set parses value according to the type of the key's field and stores it.
+// Lists are given as comma-separated values.
+func Set(cfg *Config, key string, value string) error {
+	field, err := lookup(cfg, key)
+	if err != nil {
+		return err
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s expects true or false, got %q", key, value)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s expects a whole number, got %q", key, value)
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s expects a number, got %q", key, value)
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("%s cannot be set from the command line", key)
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("%s cannot be set from the command line", key)
+	}
+	return nil
+}
+
+// Unset resets a key to its zero value (which means "use the default")
+func Unset(cfg *Config, key string) error {
+	field, err := lookup(cfg, key)
+	if err != nil {
+		return err
+	}
+	field.Set(reflect.Zero(field.Type()))
+	return nil
+}
+
+// GitConfigName returns the git config variable for a dotted key, lowercased
+// the way `git config --get-regexp` prints it. Git doesn't allow underscores
+// in variable names, so "subject_language" becomes claude-commit.subjectlanguage
+// (set it as claude-commit.subjectLanguage) and "pull_request.draft" becomes
+// claude-commit.pull_request.draft.
+func GitConfigName(key string) string {
+	parts := strings.Split(key, ".")
+	last := len(parts) - 1
+	parts[last] = strings.ToLower(strings.ReplaceAll(parts[last], "_", ""))
+	return "claude-commit." + strings.Join(parts, ".")
+}
+
+// lookup returns the settable field for a dotted key
+func lookup(cfg *Config, key string) (reflect.Value, error) {
+	value := reflect.ValueOf(cfg).Elem()
+	for _, part := range strings.Split(key, ".") {
+		if value.Kind() != reflect.Struct {
+			return reflect.Value{}, unknownKey(key)
+		}
+		found := false
+		for i := 0; i < value.NumField(); i++ {
+			if jsonName(value.Type().Field(i)) == part {
+				value = value.Field(i)
+				found = true
+				break
+			}
+		}
+		if !found || value.Kind() == reflect.Map {
+			return reflect.Value{}, unknownKey(key)
+		}
+	}
+	if value.Kind() == reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("%s is a section; use one of its keys (see 'cc config list')", key)
+	}
+	return value, nil
+}
+
+func unknownKey(key string) error {
+	return fmt.Errorf("unknown config key %q (see 'cc config list' for valid keys)", key)
+}
+
+// jsonName returns the JSON name of a struct field, or "" if it isn't serialized
+func jsonName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	name := strings.Split(tag, ",")[0]
+	if name == "-" || !field.IsExported() {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func formatValue(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.String {
+			return strings.Join(value.Interface().([]string), ",")
+		}
+	}
+	data, err := json.Marshal(value.Interface())
+	if err != nil {
+		return fmt.Sprint(value.Interface())
+	}
+	return string(data)
+}
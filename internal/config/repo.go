@@ -0,0 +1,186 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+)
+
+// repoForbiddenKeys are never taken from .claude-commit.json: a cloned
+// repository must not route the user's credentials through its own proxy,
+// nor opt the user in to telemetry
+var repoForbiddenKeys = []string{"network", "telemetry"}
+
+// repoSafeKeys are the settings a repository may set without asking: they
+// shape the review, the message, and the commit, not where credentials or
+// data go. Commands and checks have their own trust prompt before they run.
+var repoSafeKeys = map[string]bool{
+	"model": true, "wip_model": true, "models": true, "fallback_models": true,
+	"message_body": true, "subject_language": true, "body_language": true, "ui_language": true,
+	"block_severity": true, "branch_pattern": true, "protected_branches": true,
+	"edit": true, "no_push": true, "pull_before_push": true, "set_upstream": true,
+	"no_review_notes": true, "no_history": true, "no_offline_fallback": true,
+	"ignore": true, "collapse": true, "diff_context": true, "summary_tokens": true,
+	"summary_threshold": true, "file_context": true, "ignore_whitespace": true,
+	"skip_whitespace_only": true, "large_file_limit": true, "cost_limit": true,
+	"cost_limit_action": true, "sign_off": true, "trailers": true, "attribution": true,
+	"checklist": true, "debug_patterns": true, "todos": true, "tests_required": true,
+	"rewrites": true, "packages": true, "ignore_conventions": true, "repo_map": true,
+	"examples": true, "timeouts": true, "watch": true, "profile": true,
+	"commands": true, "checks": true,
+}
+
+// repoSafeSubkeys are the safe keys of sections that also hold tokens or
+// the hosts tokens are sent to
+var repoSafeSubkeys = map[string]map[string]bool{
+	"pull_request": {"enabled": true, "base": true, "draft": true, "reviewers": true},
+	"issues":       {"enabled": true, "provider": true, "keyword": true},
+}
+
+// RepoConfig is a .claude-commit.json split by what a repository may set
+type RepoConfig struct {
+	Safe       map[string]json.RawMessage // Applied as is
+	Restricted map[string]json.RawMessage // Applied once the user trusts them
+	Dropped    []string                   // Dotted keys that are never applied
+}
+
+// ReadRepo reads the per-repository .claude-commit.json in root. A missing
+// file gives an empty RepoConfig.
+func ReadRepo(root string) (*RepoConfig, error) {
+	repo := &RepoConfig{Safe: map[string]json.RawMessage{}, Restricted: map[string]json.RawMessage{}}
+	data, err := os.ReadFile(filepath.Join(root, RepoConfigFileName))
+	if os.IsNotExist(err) {
+		return repo, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", RepoConfigFileName, err)
+	}
+	if repo.Dropped, err = dropForbidden(raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", RepoConfigFileName, err)
+	}
+
+	known := topLevelKeys()
+	for key, value := range raw {
+		switch {
+		case !known[key]:
+			// Unknown keys never did anything
+		case repoSafeKeys[key]:
+			repo.Safe[key] = value
+		case repoSafeSubkeys[key] != nil:
+			var section map[string]json.RawMessage
+			if err := json.Unmarshal(value, &section); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", RepoConfigFileName, key, err)
+			}
+			safe, restricted := map[string]json.RawMessage{}, map[string]json.RawMessage{}
+			for subkey, subvalue := range section {
+				if repoSafeSubkeys[key][subkey] {
+					safe[subkey] = subvalue
+				} else {
+					restricted[subkey] = subvalue
+				}
+			}
+			if len(safe) > 0 {
+				repo.Safe[key], _ = json.Marshal(safe)
+			}
+			if len(restricted) > 0 {
+				repo.Restricted[key], _ = json.Marshal(restricted)
+			}
+		default:
+			repo.Restricted[key] = value
+		}
+	}
+	return repo, nil
+}
+
+// RestrictedKeys returns the dotted keys of the restricted settings, sorted
+func (r *RepoConfig) RestrictedKeys() []string {
+	var keys []string
+	for _, key := range sortedNames(r.Restricted) {
+		if repoSafeSubkeys[key] == nil {
+			keys = append(keys, key)
+			continue
+		}
+		var section map[string]json.RawMessage
+		_ = json.Unmarshal(r.Restricted[key], &section)
+		for _, subkey := range sortedNames(section) {
+			keys = append(keys, key+"."+subkey)
+		}
+	}
+	return keys
+}
+
+// Merge sets the keys of raw on cfg, leaving the others as they are
+func Merge(cfg *Config, raw map[string]json.RawMessage) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("%s: %w", RepoConfigFileName, err)
+	}
+	return nil
+}
+
+// dropForbidden removes repoForbiddenKeys from a repository config, also
+// inside the profiles it defines, and returns the dotted keys it removed
+func dropForbidden(raw map[string]json.RawMessage) ([]string, error) {
+	var dropped []string
+	for _, key := range repoForbiddenKeys {
+		if _, ok := raw[key]; ok {
+			delete(raw, key)
+			dropped = append(dropped, key)
+		}
+	}
+	if data, ok := raw["profiles"]; ok {
+		var profiles map[string]map[string]json.RawMessage
+		if err := json.Unmarshal(data, &profiles); err != nil {
+			return nil, fmt.Errorf("profiles: %w", err)
+		}
+		for _, name := range sortedNames(profiles) {
+			for _, key := range repoForbiddenKeys {
+				if _, ok := profiles[name][key]; ok {
+					delete(profiles[name], key)
+					dropped = append(dropped, "profiles."+name+"."+key)
+				}
+			}
+		}
+		data, err := json.Marshal(profiles)
+		if err != nil {
+			return nil, err
+		}
+		raw["profiles"] = data
+	}
+	return dropped, nil
+}
+
+// topLevelKeys returns the JSON names of the fields of Config
+func topLevelKeys() map[string]bool {
+	t := reflect.TypeOf(Config{})
+	keys := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// sortedNames returns the keys of m in order
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,196 @@
+// Package conventions finds the commit message rules a project already
+// documents: a commitlint config, a commit message template, or a section
+// of CONTRIBUTING.md.
+package conventions
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxGuidance caps each piece of free-form guidance quoted in the prompt
+const maxGuidance = 2000
+
+// Conventions are the commit message rules of a project
+type Conventions struct {
+	Types           []string // Allowed Conventional Commits types
+	Scopes          []string // Allowed scopes
+	MaxHeaderLength int      // Longest subject line; 0 means no rule
+	Guidance        string   // Free-form rules for the prompt
+	Sources         []string // Files the rules came from, relative to the root
+}
+
+// Empty reports whether no conventions were found
+func (c *Conventions) Empty() bool {
+	return len(c.Sources) == 0
+}
+
+// commitlintFiles are the config files commitlint looks for, in its order
+// (package.json only counts with a "commitlint" key)
+var commitlintFiles = []string{
+	".commitlintrc", ".commitlintrc.json", ".commitlintrc.yaml", ".commitlintrc.yml",
+	".commitlintrc.js", ".commitlintrc.cjs", ".commitlintrc.mjs", ".commitlintrc.ts",
+	"commitlint.config.js", "commitlint.config.cjs", "commitlint.config.mjs", "commitlint.config.ts",
+	"package.json",
+}
+
+// contributingFiles are the usual places of contribution guidelines
+var contributingFiles = []string{"CONTRIBUTING.md", ".github/CONTRIBUTING.md", "docs/CONTRIBUTING.md"}
+
+// conventionalTypes are the types of @commitlint/config-conventional
+var conventionalTypes = []string{"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"}
+
+// Detect collects the conventions of the repository at root. template is
+// the commit.template from git config ("" for none, or a path relative to
+// root or starting with ~); without one, a .gitmessage at root is used.
+func Detect(root string, template string) *Conventions {
+	c := &Conventions{}
+	c.readCommitlint(root)
+	c.readTemplate(root, template)
+	c.readContributing(root)
+	return c
+}
+
+// readCommitlint picks the type, scope, and header length rules out of a
+// commitlint config. The rules are matched as text, so JSON, JavaScript,
+// and YAML flow lists ("type-enum: [2, always, [feat, fix]]") all work.
+func (c *Conventions) readCommitlint(root string) {
+	for _, name := range commitlintFiles {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			continue
+		}
+		text := string(data)
+		if name == "package.json" && !strings.Contains(text, `"commitlint"`) {
+			continue
+		}
+
+		c.Sources = append(c.Sources, name)
+		c.Types = enumRule(text, "type-enum")
+		c.Scopes = enumRule(text, "scope-enum")
+		c.MaxHeaderLength = lengthRule(text, "header-max-length")
+		if strings.Contains(text, "config-conventional") {
+			if c.Types == nil {
+				c.Types = conventionalTypes
+			}
+			if c.MaxHeaderLength == 0 {
+				c.MaxHeaderLength = 100
+			}
+		}
+		return
+	}
+}
+
+// enumRule returns the values of an enabled "always" enum rule, e.g.
+// 'type-enum': [2, 'always', ['feat', 'fix']]
+func enumRule(text, rule string) []string {
+	re := regexp.MustCompile(`['"]?` + regexp.QuoteMeta(rule) + `['"]?\s*:\s*\[\s*([0-2])\s*,\s*['"]?always['"]?\s*,\s*\[([^\]]*)\]`)
+	m := re.FindStringSubmatch(text)
+	if m == nil || m[1] == "0" {
+		return nil
+	}
+	var values []string
+	for _, item := range strings.Split(m[2], ",") {
+		if item = strings.Trim(strings.TrimSpace(item), `'"`+"`"); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// lengthRule returns the limit of an enabled "always" length rule, e.g.
+// 'header-max-length': [2, 'always', 72], or 0
+func lengthRule(text, rule string) int {
+	re := regexp.MustCompile(`['"]?` + regexp.QuoteMeta(rule) + `['"]?\s*:\s*\[\s*([0-2])\s*,\s*['"]?always['"]?\s*,\s*(\d+)`)
+	m := re.FindStringSubmatch(text)
+	if m == nil || m[1] == "0" {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[2])
+	return n
+}
+
+// readTemplate quotes the commit message template, whose comments usually
+// explain the expected format
+func (c *Conventions) readTemplate(root string, template string) {
+	path := template
+	switch {
+	case path == "":
+		path = ".gitmessage"
+	case strings.HasPrefix(path, "~/"):
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	full := path
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(root, path)
+	}
+	data, err := os.ReadFile(full)
+	if err != nil || strings.TrimSpace(string(data)) == "" {
+		return
+	}
+	c.Sources = append(c.Sources, path)
+	c.addGuidance("Commit message template of the project", string(data))
+}
+
+// readContributing quotes the section of the contribution guidelines about
+// commit messages
+func (c *Conventions) readContributing(root string) {
+	for _, name := range contributingFiles {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			continue
+		}
+		if section := commitSection(string(data)); section != "" {
+			c.Sources = append(c.Sources, name)
+			c.addGuidance("Commit guidelines from "+name, section)
+		}
+		return
+	}
+}
+
+// commitSection returns the first Markdown section whose heading mentions
+// commits, up to the next heading of the same or a higher level
+func commitSection(markdown string) string {
+	lines := strings.Split(markdown, "\n")
+	start, level := -1, 0
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(line, "```") {
+			inFence = !inFence
+		}
+		if inFence {
+			continue // Shell comments in code blocks aren't headings
+		}
+		depth := len(line) - len(strings.TrimLeft(line, "#"))
+		if depth == 0 || depth > 6 || !strings.HasPrefix(line[depth:], " ") {
+			continue
+		}
+		if start >= 0 && depth <= level {
+			return strings.TrimSpace(strings.Join(lines[start:i], "\n"))
+		}
+		if start < 0 && strings.Contains(strings.ToLower(line), "commit") {
+			start, level = i, depth
+		}
+	}
+	if start < 0 {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join(lines[start:], "\n"))
+}
+
+// addGuidance appends a titled piece of free-form text, shortened to maxGuidance
+func (c *Conventions) addGuidance(title, text string) {
+	text = strings.TrimSpace(text)
+	if runes := []rune(text); len(runes) > maxGuidance {
+		text = string(runes[:maxGuidance]) + "\n[...]"
+	}
+	if c.Guidance != "" {
+		c.Guidance += "\n\n"
+	}
+	c.Guidance += title + ":\n" + text
+}
@@ -0,0 +1,57 @@
+package debuglog
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileName is the log file written in the config dir when CC_DEBUG=file
+const FileName = "debug.log"
+
+var logger *slog.Logger
+
+// Enable sends debug logs to w as structured key=value lines
+func Enable(w io.Writer) {
+	logger = slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// EnableFromEnv enables logging according to CC_DEBUG: "file" appends to
+// debug.log in configDir, any other truthy value logs to stderr. It returns
+// the log file path when logging to a file.
+func EnableFromEnv(configDir string) (string, error) {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv("CC_DEBUG")))
+	switch value {
+	case "", "0", "false", "off", "no":
+		return "", nil
+	case "file":
+		if err := os.MkdirAll(configDir, 0755); err != nil {
+			return "", err
+		}
+		path := filepath.Join(configDir, FileName)
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return "", err
+		}
+		Enable(file)
+		return path, nil
+	default:
+		Enable(os.Stderr)
+		return "", nil
+	}
+}
+
+// Enabled reports whether debug logging is on
+func Enabled() bool {
+	return logger != nil
+}
+
+// Log writes a debug event with structured attributes (key, value pairs)
+func Log(msg string, args ...any) {
+	if logger == nil {
+		return
+	}
+	logger.Debug(msg, args...)
+}
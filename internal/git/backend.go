@@ -0,0 +1,62 @@
+package git
+
+import (
+	"context"
+	"fmt"
+)
+
+// nativeBackend runs the operations of the commit pipeline in-process,
+// without the git binary. Everything else still runs git and degrades
+// gracefully (e.g. no LFS or binary detection) when it isn't installed.
+type nativeBackend interface {
+	RepoRoot() (string, error)
+	CurrentBranch() (string, error)
+	HeadSHA() (string, error)
+	IsDetached() bool
+	Upstream() string
+	ConfigValue(key string) string
+
+	ChangedFiles() ([]string, error)
+	StagedFiles() ([]string, error)
+	UntrackedFiles() ([]string, error)
+	Diff(omit []string) (string, error)
+	DiffSummary(omit []string) (string, error)
+
+	StageAll() error
+	// Add stages the changes of one file, given relative to the repository root
+	Add(path string) error
+	// Unstage resets one file in the index to HEAD, keeping the working tree
+	Unstage(path string) error
+	Commit(message string, signOff bool) error
+	// Push pushes local to branch on remote. An empty remote means the
+	// current branch's upstream; setUpstream records remote/branch as it.
+	Push(ctx context.Context, remote, branch string, forceWithLease, setUpstream bool) error
+}
+
+// native is the selected in-process backend, or nil to run git
+var native nativeBackend
+
+// openGoGit opens the repository with go-git; it is only set in builds
+// with the gogit tag
+var openGoGit func() (nativeBackend, error)
+
+// SetBackend selects how the core operations run: "exec" (the default)
+// runs the git binary, "go-git" uses the go-git library
+func SetBackend(name string) error {
+	switch name {
+	case "", "exec":
+		native = nil
+		return nil
+	case "go-git":
+		if openGoGit == nil {
+			return fmt.Errorf("this cc was built without go-git support (rebuild with -tags gogit)")
+		}
+		backend, err := openGoGit()
+		if err != nil {
+			return fmt.Errorf("opening repository with go-git: %w", err)
+		}
+		native = backend
+		return nil
+	}
+	return fmt.Errorf("unknown git_backend %q (use exec or go-git)", name)
+}
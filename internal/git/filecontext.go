@@ -0,0 +1,116 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/debuglog"
+)
+
+// FileContextLines is the length (in lines) up to which a modified file is
+// sent to the model whole, next to the diff, so the review sees the code
+// around each hunk. Zero turns file context off.
+var FileContextLines = 0
+
+// DefaultFileContextTokens is FileContextTokens unless configured
+const DefaultFileContextTokens = 4000
+
+// FileContextTokens caps the estimated size of the file context (in tokens
+// of about four characters); the smallest files are included first
+var FileContextTokens = DefaultFileContextTokens
+
+// FileContext returns the current content of the modified files among
+// files that are at most FileContextLines long, as many as fit in
+// FileContextTokens. With staged, the content comes from the index instead
+// of the working tree. New files are left out, since their diff already has
+// every line.
+func FileContext(ctx context.Context, files []string, staged bool) string {
+	if FileContextLines <= 0 || FileContextTokens <= 0 || len(files) == 0 {
+		return ""
+	}
+	inHead, err := filesInHead(ctx, files)
+	if err != nil {
+		debuglog.Log("listing files for the file context", "error", err)
+		return ""
+	}
+	root, err := GetRepoRoot(ctx)
+	if err != nil {
+		return ""
+	}
+
+	type fileText struct{ path, text string }
+	var candidates []fileText
+	for _, file := range files {
+		if !inHead[file] || IsCollapsed(file) {
+			continue
+		}
+		var text string
+		if staged {
+			text, err = runGitCommandTimeout(ctx, Timeouts.Diff, "show", ":"+file)
+		} else if looksBinary(filepath.Join(root, file)) {
+			continue
+		} else {
+			var data []byte
+			data, err = os.ReadFile(filepath.Join(root, file))
+			text = string(data)
+		}
+		if err != nil || strings.ContainsRune(text, 0) {
+			// Deleted, or binary
+			continue
+		}
+		if strings.Count(text, "\n") >= FileContextLines {
+			continue
+		}
+		candidates = append(candidates, fileText{file, text})
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return len(candidates[a].text) < len(candidates[b].text)
+	})
+
+	var b strings.Builder
+	included, budget := 0, FileContextTokens*4
+	for _, f := range candidates {
+		section := numberLines(f.path, f.text)
+		if b.Len()+len(section) > budget {
+			break
+		}
+		b.WriteString(section)
+		included++
+	}
+	if included == 0 {
+		return ""
+	}
+	debuglog.Log("file context", "files", included, "of", len(candidates), "bytes", b.Len())
+	return fmt.Sprintf("\n--- FILE CONTEXT (current content of %d modified file(s), for reference only) ---\n%s", included, b.String())
+}
+
+// numberLines formats a file for the file context with its line numbers,
+// which also keeps its lines from reading as diff lines
+func numberLines(path, text string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== %s ===\n", path)
+	for i, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		fmt.Fprintf(&b, "%d| %s\n", i+1, line)
+	}
+	return b.String()
+}
+
+// filesInHead reports which of files exist in the HEAD commit
+func filesInHead(ctx context.Context, files []string) (map[string]bool, error) {
+	args := append([]string{"ls-tree", "-r", "--name-only", "--full-tree", "HEAD", "--"}, files...)
+	output, err := runGitCommandTimeout(ctx, Timeouts.Diff, args...)
+	if err != nil {
+		return nil, err
+	}
+	inHead := make(map[string]bool)
+	for _, path := range strings.Split(output, "\n") {
+		if path != "" {
+			inHead[path] = true
+		}
+	}
+	return inHead, nil
+}
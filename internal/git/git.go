@@ -2,51 +2,116 @@ package git
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/quaywin/claude-commit/internal/debuglog"
 )
 
-const FileSummaryThreshold = 10
+// DefaultSummaryTokens is SummaryTokens unless configured
+const DefaultSummaryTokens = 20000
 
-// GetDiff returns the combined diff of staged, unstaged, and untracked changes
-func GetDiff() (string, error) {
-	// Get unstaged changes
-	unstaged, err := runGitCommand("diff")
-	if err != nil {
-		return "", err
-	}
+// SummaryTokens is the estimated size of a full diff (in tokens of about
+// four characters) above which the model gets a stat summary instead. Zero
+// means no size limit.
+var SummaryTokens = DefaultSummaryTokens
 
-	// Get staged changes
-	staged, err := runGitCommand("diff", "--cached")
+// FileSummaryThreshold is the number of changed files from which the model
+// gets a stat summary however small the diff is. Zero only goes by
+// SummaryTokens.
+var FileSummaryThreshold = 0
+
+// UseSummary reports whether a changeset of files changed files is described
+// by a stat summary without looking at its size
+func UseSummary(files int) bool {
+	return FileSummaryThreshold > 0 && files >= FileSummaryThreshold
+}
+
+// TooLarge reports whether a full diff is above SummaryTokens
+func TooLarge(diff string) bool {
+	return SummaryTokens > 0 && len(diff)/4 > SummaryTokens
+}
+
+// Timeouts bounds how long the git commands of each stage may run. Zero means no limit.
+var Timeouts struct {
+	Diff   time.Duration // Collecting changed files and diffs
+	Commit time.Duration // git commit, including hooks
+	Push   time.Duration // git push
+}
+
+// Exclude lists pathspec patterns left out of the working-tree diff and file
+// list. Excluded files are still staged by StageAll.
+var Exclude []string
+
+// IgnoreWhitespace leaves whitespace changes out of the diffs sent to the
+// model (git diff -w). Only the exec backend supports it.
+var IgnoreWhitespace bool
+
+// ContextLines is the number of context lines in the diffs sent to the
+// model (git diff -U<n>); -1 keeps git's default. Only the exec backend
+// supports it.
+var ContextLines = -1
+
+// Paths limits the working-tree diff, file list, staging, and commit to
+// these pathspecs, which must not depend on the directory git runs in
+// (e.g. ":(top)src/api"). Empty means the whole repository. Only the exec
+// backend supports it.
+var Paths []string
+
+// Collapse lists glob patterns (matched against the whole path or the file
+// name) of lockfiles and generated files, whose diff is replaced by a line
+// count so the prompt is spent on real code
+var Collapse = DefaultCollapse
+
+// DefaultCollapse are the lockfiles and generated files collapsed unless the
+// config says otherwise
+var DefaultCollapse = []string{
+	"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml",
+	"go.sum", "Cargo.lock", "Gemfile.lock", "composer.lock", "poetry.lock",
+	"Pipfile.lock", "uv.lock", "mix.lock", "pubspec.lock", "Podfile.lock",
+	"flake.lock", "packages.lock.json",
+	"*.pb.go", "*.pb.gw.go", "*_pb2.py", "*_pb2_grpc.py", "*_generated.go",
+	"zz_generated.*.go", "*.min.js", "*.min.css", "*.js.map", "*.css.map",
+}
+
+// ReadOnly makes every command that would modify the repository fail instead
+// of running. Used by demo mode to guarantee nothing is written.
+var ReadOnly bool
+
+// errReadOnly is returned by write operations while ReadOnly is set
+func errReadOnly(action string) error {
+	return fmt.Errorf("refusing to %s: repository is in read-only mode", action)
+}
+
+// GetDiff returns the combined diff of staged, unstaged, and untracked
+// changes, leaving out the omit paths
+func GetDiff(ctx context.Context, omit ...string) (string, error) {
+	if native != nil {
+		return native.Diff(omit)
+	}
+	// Get unstaged and untracked changes
+	unstaged, untrackedDiff, err := diffWorkingTree(ctx, omit)
 	if err != nil {
 		return "", err
 	}
 
-	// Get untracked changes
-	untracked, err := runGitCommand("ls-files", "--others", "--exclude-standard")
+	// Get staged changes
+	staged, err := runGitCommandTimeout(ctx, Timeouts.Diff, omitting(withPathspec(reviewDiff("--cached")...), omit)...)
 	if err != nil {
 		return "", err
 	}
 
-	untrackedDiff := ""
-	if untracked != "" {
-		for _, file := range strings.Split(untracked, "\n") {
-			if file != "" {
-				// Use git diff --no-index /dev/null <file> to show new file content
-				// Note: git diff --no-index returns exit code 1 if there are differences
-				cmd := exec.Command("git", "diff", "--no-index", "/dev/null", file)
-				var stdout bytes.Buffer
-				cmd.Stdout = &stdout
-				_ = cmd.Run() // Ignore error as exit 1 is expected for differences
-				diff := strings.TrimSpace(stdout.String())
-				if diff != "" {
-					untrackedDiff += diff + "\n"
-				}
-			}
-		}
-	}
-
 	if unstaged == "" && staged == "" && untrackedDiff == "" {
 		return "", nil
 	}
@@ -54,22 +119,36 @@ func GetDiff() (string, error) {
 	return fmt.Sprintf("--- UNSTAGED CHANGES ---\n%s\n--- STAGED CHANGES ---\n%s\n--- UNTRACKED FILES ---\n%s", unstaged, staged, untrackedDiff), nil
 }
 
-// GetDiffSummary returns a summary of changed files with line counts (for large changesets)
-func GetDiffSummary() (string, error) {
+// GetCommittedDiff is GetDiff without leaving anything out: files matched by
+// Exclude are still staged and committed, so checks that must see every
+// committed line (e.g. for conflict markers) read this diff
+func GetCommittedDiff(ctx context.Context) (string, error) {
+	exclude := Exclude
+	Exclude = nil
+	defer func() { Exclude = exclude }()
+	return GetDiff(ctx)
+}
+
+// GetDiffSummary returns a summary of changed files with line counts (for
+// large changesets), leaving out the omit paths
+func GetDiffSummary(ctx context.Context, omit ...string) (string, error) {
+	if native != nil {
+		return native.DiffSummary(omit)
+	}
 	// Get unstaged changes summary
-	unstaged, err := runGitCommand("diff", "--stat")
+	unstaged, err := runGitCommandTimeout(ctx, Timeouts.Diff, omitting(withPathspec(reviewDiff("--stat")...), omit)...)
 	if err != nil {
 		return "", err
 	}
 
 	// Get staged changes summary
-	staged, err := runGitCommand("diff", "--cached", "--stat")
+	staged, err := runGitCommandTimeout(ctx, Timeouts.Diff, omitting(withPathspec(reviewDiff("--cached", "--stat")...), omit)...)
 	if err != nil {
 		return "", err
 	}
 
 	// Get untracked files
-	untracked, err := runGitCommand("ls-files", "--others", "--exclude-standard")
+	untracked, err := runGitCommandTimeout(ctx, Timeouts.Diff, untrackedArgs(omit)...)
 	if err != nil {
 		return "", err
 	}
@@ -94,21 +173,24 @@ func GetDiffSummary() (string, error) {
 }
 
 // GetChangedFiles returns a list of files that have been changed (staged, unstaged, and untracked)
-func GetChangedFiles() ([]string, error) {
+func GetChangedFiles(ctx context.Context) ([]string, error) {
+	if native != nil {
+		return native.ChangedFiles()
+	}
 	// Get unstaged files
-	unstaged, err := runGitCommand("diff", "--name-only")
+	unstaged, err := runGitCommandTimeout(ctx, Timeouts.Diff, withPathspec("diff", "--name-only")...)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get staged files
-	staged, err := runGitCommand("diff", "--cached", "--name-only")
+	staged, err := runGitCommandTimeout(ctx, Timeouts.Diff, withPathspec("diff", "--cached", "--name-only")...)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get untracked files
-	untracked, err := runGitCommand("ls-files", "--others", "--exclude-standard")
+	untracked, err := runGitCommandTimeout(ctx, Timeouts.Diff, untrackedArgs(nil)...)
 	if err != nil {
 		return nil, err
 	}
@@ -139,47 +221,1416 @@ func GetChangedFiles() ([]string, error) {
 	return files, nil
 }
 
-// StageAll stages all changes in the repository
-func StageAll() error {
-	_, err := runGitCommand("add", ".")
+// GetUntrackedFiles returns the untracked, non-ignored files relative to the repository root
+func GetUntrackedFiles(ctx context.Context) ([]string, error) {
+	if native != nil {
+		return native.UntrackedFiles()
+	}
+	output, err := runGitCommandTimeout(ctx, Timeouts.Diff, untrackedArgs(nil)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, file := range strings.Split(output, "\n") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// GetTrackedFiles returns every file in the index, relative to the
+// repository root
+func GetTrackedFiles(ctx context.Context) ([]string, error) {
+	output, err := runGitCommandTimeout(ctx, Timeouts.Diff, "ls-files", "-z", "--full-name", "--", ":/")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, file := range strings.Split(output, "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// GetStagedFiles returns the files in the index that differ from HEAD
+func GetStagedFiles(ctx context.Context) ([]string, error) {
+	if native != nil {
+		return native.StagedFiles()
+	}
+	output, err := runGitCommandTimeout(ctx, Timeouts.Diff, withPathspec("diff", "--cached", "--name-only")...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, file := range strings.Split(output, "\n") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// GetStagedDiff returns the diff of the index against HEAD, which is exactly
+// what `git commit` will record, leaving out the omit paths. With summary
+// set, only a --stat summary is returned.
+func GetStagedDiff(ctx context.Context, summary bool, omit ...string) (string, error) {
+	if summary {
+		return runGitCommandTimeout(ctx, Timeouts.Diff, omitting(withPathspec(reviewDiff("--cached", "--stat")...), omit)...)
+	}
+	return runGitCommandTimeout(ctx, Timeouts.Diff, omitting(withPathspec(reviewDiff("--cached")...), omit)...)
+}
+
+// FileStatus is a changed file and whether its changes are in the index,
+// the working tree, or both
+type FileStatus struct {
+	Path      string
+	Staged    bool // Has changes in the index
+	Unstaged  bool // Has changes in the working tree (or is untracked)
+	Untracked bool
+}
+
+// GetStatus returns every changed file with its staging state
+func GetStatus(ctx context.Context) ([]FileStatus, error) {
+	// Porcelain v2 never starts with a space, which output trimming would eat
+	output, err := runGitCommandTimeout(ctx, Timeouts.Diff, "status", "--porcelain=v2", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []FileStatus
+	entries := strings.Split(output, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 3 {
+			continue
+		}
+
+		// Field counts before the path: "1 XY sub mH mI mW hH hI path",
+		// "2 XY ... X<score> path<NUL>orig", "u XY ... h1 h2 h3 path", "? path"
+		var fields []string
+		switch entry[0] {
+		case '?':
+			files = append(files, FileStatus{Path: entry[2:], Unstaged: true, Untracked: true})
+			continue
+		case '1':
+			fields = strings.SplitN(entry, " ", 9)
+		case '2':
+			fields = strings.SplitN(entry, " ", 10)
+			i++ // Skip the original path of the rename or copy
+		case 'u':
+			fields = strings.SplitN(entry, " ", 11)
+		default:
+			continue
+		}
+		if len(fields) < 2 || len(fields[1]) != 2 {
+			continue
+		}
+		files = append(files, FileStatus{
+			Path:     fields[len(fields)-1],
+			Staged:   fields[1][0] != '.',
+			Unstaged: fields[1][1] != '.',
+		})
+	}
+	return files, nil
+}
+
+// FileChange is a file that the next commit of everything would change
+type FileChange struct {
+	Status  byte   // A, M, D, R (renamed), or T (type change)
+	Path    string // New path for renames
+	OldPath string // Set for renames
+}
+
+// GetFileChanges lists what committing every change (untracked files
+// included) would do to each file, with renames detected. Like
+// diffWorkingTree, it works on a throwaway copy of the index.
+func GetFileChanges(ctx context.Context) ([]FileChange, error) {
+	root, err := GetRepoRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := tempIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp)
+
+	env := []string{"GIT_INDEX_FILE=" + tmp}
+	if _, err := runGitCommandEnv(ctx, Timeouts.Diff, env, "-C", root, "add", "--all", "--", ":/"); err != nil {
+		return nil, err
+	}
+	base := "HEAD"
+	if !RefExists(ctx, "HEAD") {
+		base = EmptyTree
+	}
+	output, err := runGitCommandEnv(ctx, Timeouts.Diff, env, withPathspec("-C", root, "diff", "--cached", "--name-status", "-z", "-M", base)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FileChange
+	fields := strings.Split(output, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		status := fields[i]
+		if status == "" {
+			continue
+		}
+		change := FileChange{Status: status[0], Path: fields[i+1]}
+		if (change.Status == 'R' || change.Status == 'C') && i+2 < len(fields) {
+			change.OldPath, change.Path = fields[i+1], fields[i+2]
+			i++
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// GetFileDiff returns the staged and unstaged changes of a single file
+// against HEAD, or its whole content if it is untracked
+func GetFileDiff(ctx context.Context, file FileStatus) (string, error) {
+	if file.Untracked {
+		root, err := GetRepoRoot(ctx)
+		if err != nil {
+			return "", err
+		}
+		return diffNewFile(ctx, root, file.Path)
+	}
+	if _, err := runGitCommand(ctx, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		// No commits yet: everything is compared with the empty tree
+		return runGitCommandTimeout(ctx, Timeouts.Diff, "diff", EmptyTree, "--", file.Path)
+	}
+	return runGitCommandTimeout(ctx, Timeouts.Diff, "diff", "HEAD", "--", file.Path)
+}
+
+// diffWorkingTree returns the unstaged changes, and the diff of every
+// untracked file as a new file, in two git diffs instead of one process per
+// file. The untracked files are added with intent-to-add (git add -N) to a
+// throwaway copy of the index, so the real index is left untouched, even in
+// read-only mode, and a file moved without git mv shows up as a rename.
+func diffWorkingTree(ctx context.Context, omit []string) (unstaged, untracked string, err error) {
+	root, err := GetRepoRoot(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	tmp, err := tempIndex(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	defer os.Remove(tmp)
+
+	env := []string{"GIT_INDEX_FILE=" + tmp}
+	if _, err := runGitCommandEnv(ctx, Timeouts.Diff, env, "-C", root, "add", "--intent-to-add", "--ignore-removal", "--", ":/"); err != nil {
+		return "", "", err
+	}
+	in := func(args ...string) []string {
+		return omitting(withPathspec(append([]string{"-C", root}, reviewDiff(args...)...)...), omit)
+	}
+	unstaged, err = runGitCommandEnv(ctx, Timeouts.Diff, env, in("--diff-filter=a")...)
+	if err != nil {
+		return "", "", err
+	}
+	// Intent-to-add entries are the only additions between index and working
+	// tree; those paired with a deleted file are renames in unstaged
+	untracked, err = runGitCommandEnv(ctx, Timeouts.Diff, env, in("--diff-filter=A")...)
+	return unstaged, untracked, err
+}
+
+// tempIndex copies the index to a temporary file for commands that must not
+// touch the real one. The caller removes the file.
+func tempIndex(ctx context.Context) (string, error) {
+	indexPath, err := GetGitPath(ctx, "index")
+	if err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp("", "cc-index-*")
+	if err != nil {
+		return "", err
+	}
+	index, err := os.Open(indexPath)
+	if err == nil {
+		_, err = io.Copy(tmp, index)
+		index.Close()
+	} else if errors.Is(err, os.ErrNotExist) {
+		// No index yet: git starts a fresh one, but not in an empty file
+		err = os.Remove(tmp.Name())
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// SnapshotTree writes the whole working tree, untracked files included, as
+// a tree object and returns its hash. The real index is left untouched.
+func SnapshotTree(ctx context.Context) (string, error) {
+	root, err := GetRepoRoot(ctx)
+	if err != nil {
+		return "", err
+	}
+	tmp, err := tempIndex(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp)
+
+	env := []string{"GIT_INDEX_FILE=" + tmp}
+	if _, err := runGitCommandEnv(ctx, Timeouts.Diff, env, "-C", root, "add", "--all", "--", ":/"); err != nil {
+		return "", err
+	}
+	return runGitCommandEnv(ctx, Timeouts.Diff, env, "write-tree")
+}
+
+// GetTree returns the tree hash of a commit
+func GetTree(ctx context.Context, rev string) (string, error) {
+	return runGitCommand(ctx, "rev-parse", "--verify", "--quiet", rev+"^{tree}")
+}
+
+// CommitTree creates a commit of tree on top of parent (none if empty)
+// without touching HEAD, the index, or the working tree, and returns its hash
+func CommitTree(ctx context.Context, tree, parent, message string) (string, error) {
+	if ReadOnly {
+		return "", errReadOnly("commit")
+	}
+	args := []string{"commit-tree", tree, "-m", message}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	return runGitCommandTimeout(ctx, Timeouts.Commit, args...)
+}
+
+// UpdateBranch points branch at sha, failing if it no longer points at old
+// (empty when the branch must not exist yet)
+func UpdateBranch(ctx context.Context, branch, sha, old string) error {
+	if ReadOnly {
+		return errReadOnly("update a branch")
+	}
+	if old == "" {
+		old = strings.Repeat("0", len(sha))
+	}
+	_, err := runGitCommand(ctx, "update-ref", "-m", "cc: checkpoint", "refs/heads/"+branch, sha, old)
+	return err
+}
+
+// diffNewFile shows an untracked file (relative to root) as a new file by
+// diffing it against the null device: /dev/null, or NUL on Windows
+func diffNewFile(ctx context.Context, root, file string) (string, error) {
+	// git diff --no-index exits with 1 when the files differ, so only a
+	// timeout counts as a failure
+	diff, err := runGitCommandTimeout(ctx, Timeouts.Diff, "-C", root, "diff", "--no-index", os.DevNull, file)
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return "", err
+	}
+	return diff, nil
+}
+
+// StageFile adds a single file's changes to the index
+func StageFile(ctx context.Context, path string) error {
+	if ReadOnly {
+		return errReadOnly("stage changes")
+	}
+	if native != nil {
+		return native.Add(path)
+	}
+	_, err := runGitCommand(ctx, "add", "--", path)
 	return err
 }
 
-// Commit creates a commit with the given message
-func Commit(message string) error {
-	_, err := runGitCommand("commit", "-m", message)
+// UnstageFile removes a single file's changes from the index, keeping them in the working tree
+func UnstageFile(ctx context.Context, path string) error {
+	if ReadOnly {
+		return errReadOnly("unstage changes")
+	}
+	if native != nil {
+		return native.Unstage(path)
+	}
+	_, err := runGitCommand(ctx, "reset", "-q", "--", path)
 	return err
 }
 
-// Push pushes the current branch to the remote
-func Push() error {
-	// Try regular push first
-	_, err := runGitCommand("push")
+// withPathspec limits a working-tree command to Paths (or the whole
+// repository) and appends the Exclude patterns as ":(top,exclude)"
+// pathspecs, so they match from the repository root
+func withPathspec(args ...string) []string {
+	if len(Exclude) == 0 && len(Paths) == 0 {
+		return args
+	}
+	args = append(args, "--")
+	if len(Paths) > 0 {
+		args = append(args, Paths...)
+	} else {
+		args = append(args, ":/")
+	}
+	for _, pattern := range Exclude {
+		args = append(args, ":(top,exclude)"+pattern)
+	}
+	return args
+}
+
+// reviewDiff builds a git diff command for the diffs sent to the model.
+// Renames and copies are detected (whatever diff.renames says), so a moved
+// file costs a few lines instead of its whole content twice.
+func reviewDiff(args ...string) []string {
+	diff := []string{"diff", "-M", "-C"}
+	if IgnoreWhitespace {
+		diff = append(diff, "-w")
+	}
+	if ContextLines >= 0 {
+		diff = append(diff, "-U"+strconv.Itoa(ContextLines))
+	}
+	return append(diff, args...)
+}
+
+// untrackedArgs lists untracked files in the whole repository, with paths
+// relative to the root like the other diff commands, even from a subdirectory
+func untrackedArgs(omit []string) []string {
+	args := omitting(withPathspec("ls-files", "--others", "--exclude-standard", "--full-name"), omit)
+	if !slices.Contains(args, "--") {
+		args = append(args, "--", ":/")
+	}
+	return args
+}
+
+// omitting appends literal paths to leave out to a command built by withPathspec
+func omitting(args []string, paths []string) []string {
+	if len(paths) == 0 {
+		return args
+	}
+	if !slices.Contains(args, "--") {
+		args = append(args, "--", ":/")
+	}
+	for _, path := range paths {
+		args = append(args, ":(top,literal,exclude)"+path)
+	}
+	return args
+}
+
+// GetLFSFiles returns the paths among files (relative to the repository
+// root) that Git LFS manages, going by the filter attribute
+func GetLFSFiles(ctx context.Context, files []string) ([]string, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	root, err := GetRepoRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	output, err := runGitCommand(ctx, append([]string{"-C", root, "check-attr", "-z", "filter", "--"}, files...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	// NUL-separated triples of path, attribute, and value
+	fields := strings.Split(output, "\x00")
+	var lfs []string
+	for i := 0; i+2 < len(fields); i += 3 {
+		if fields[i+2] == "lfs" {
+			lfs = append(lfs, fields[i])
+		}
+	}
+	return lfs, nil
+}
+
+// GetBinaryFiles returns the changed files (relative to the repository root)
+// whose content git treats as binary: tracked files going by --numstat, and
+// untracked files by a NUL byte near the start, like git's own check
+func GetBinaryFiles(ctx context.Context) ([]string, error) {
+	var binary []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if path != "" && !seen[path] {
+			seen[path] = true
+			binary = append(binary, path)
+		}
+	}
+
+	for _, args := range [][]string{{"diff", "--numstat", "-z"}, {"diff", "--cached", "--numstat", "-z"}} {
+		output, err := runGitCommandTimeout(ctx, Timeouts.Diff, withPathspec(args...)...)
+		if err != nil {
+			return nil, err
+		}
+		// Records are "added\tdeleted\tpath\0", or "added\tdeleted\t\0from\0to\0"
+		// for renames; binary files have "-" counts
+		fields := strings.Split(output, "\x00")
+		for i := 0; i < len(fields); i++ {
+			parts := strings.SplitN(fields[i], "\t", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			path := parts[2]
+			if path == "" && i+2 < len(fields) {
+				path = fields[i+2]
+				i += 2
+			}
+			if parts[0] == "-" && parts[1] == "-" {
+				add(path)
+			}
+		}
+	}
+
+	untracked, err := runGitCommandTimeout(ctx, Timeouts.Diff, untrackedArgs(nil)...)
 	if err != nil {
-		// If it fails because of missing upstream, try to set it
-		if strings.Contains(err.Error(), "has no upstream branch") {
-			branch, branchErr := runGitCommand("rev-parse", "--abbrev-ref", "HEAD")
-			if branchErr != nil {
-				return err // Return original error if we can't even get branch name
+		return nil, err
+	}
+	if untracked != "" {
+		root, err := GetRepoRoot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range strings.Split(untracked, "\n") {
+			if file != "" && looksBinary(filepath.Join(root, file)) {
+				add(file)
 			}
-			_, pushErr := runGitCommand("push", "--set-upstream", "origin", branch)
-			return pushErr
 		}
-		return err
 	}
-	return nil
+	return binary, nil
 }
 
-func runGitCommand(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+// IsCollapsed reports whether file (relative to the repository root)
+// matches one of the Collapse patterns
+func IsCollapsed(file string) bool {
+	for _, pattern := range Collapse {
+		if matched, _ := path.Match(pattern, file); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, path.Base(file)); matched {
+			return true
+		}
+	}
+	return false
+}
 
-	err := cmd.Run()
+// LineCount is the number of lines a change adds and deletes
+type LineCount struct {
+	Added   int
+	Deleted int
+}
+
+// GetLineCounts returns the lines added and deleted in each of files
+// (relative to the repository root): against HEAD, or only in the index when
+// staged. Untracked files count all their lines as added.
+func GetLineCounts(ctx context.Context, files []string, staged bool) (map[string]LineCount, error) {
+	counts := make(map[string]LineCount)
+	if len(files) == 0 {
+		return counts, nil
+	}
+	args := []string{"diff", "--cached", "--numstat", "--no-renames", "-z"}
+	if !staged {
+		if _, err := runGitCommand(ctx, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+			args = []string{"diff", "HEAD", "--numstat", "--no-renames", "-z"}
+		}
+	}
+	args = append(args, "--")
+	for _, file := range files {
+		args = append(args, ":(top,literal)"+file)
+	}
+	output, err := runGitCommandTimeout(ctx, Timeouts.Diff, args...)
 	if err != nil {
-		return "", fmt.Errorf("git command failed: %w, stderr: %s", err, stderr.String())
+		return nil, err
+	}
+	// Records are "added\tdeleted\tpath\0"
+	for _, record := range strings.Split(output, "\x00") {
+		parts := strings.SplitN(record, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		added, _ := strconv.Atoi(parts[0])
+		deleted, _ := strconv.Atoi(parts[1])
+		counts[parts[2]] = LineCount{Added: added, Deleted: deleted}
+	}
+	if staged {
+		return counts, nil
 	}
 
-	return strings.TrimSpace(stdout.String()), nil
+	root, err := GetRepoRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if _, ok := counts[file]; ok {
+			continue
+		}
+		if data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(file))); err == nil {
+			lines := bytes.Count(data, []byte("\n"))
+			if len(data) > 0 && data[len(data)-1] != '\n' {
+				lines++
+			}
+			counts[file] = LineCount{Added: lines}
+		}
+	}
+	return counts, nil
+}
+
+// looksBinary reports whether the first 8000 bytes of a file contain a NUL byte
+func looksBinary(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	buf := make([]byte, 8000)
+	n, _ := io.ReadFull(f, buf)
+	return bytes.IndexByte(buf[:n], 0) >= 0
+}
+
+// StageAll stages all changes in the repository, or under Paths
+func StageAll(ctx context.Context) error {
+	if ReadOnly {
+		return errReadOnly("stage changes")
+	}
+	if len(Paths) > 0 {
+		_, err := runGitCommand(ctx, append([]string{"add", "--all", "--"}, Paths...)...)
+		return err
+	}
+	_, err := runGitCommand(ctx, "add", ".")
+	return err
+}
+
+// MergeSquash runs `git merge --squash branch`, which stages the branch's
+// changes without committing them
+func MergeSquash(ctx context.Context, branch string) error {
+	if ReadOnly {
+		return errReadOnly("merge")
+	}
+	_, err := runGitCommand(ctx, "merge", "--squash", branch)
+	return err
+}
+
+// GetUnmergedFiles returns the files left with merge conflicts
+func GetUnmergedFiles(ctx context.Context) ([]string, error) {
+	output, err := runGitCommand(ctx, "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, file := range strings.Split(output, "\n") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// StashPush stashes all changes, untracked files included (or those under
+// Paths), with the given message
+func StashPush(ctx context.Context, message string) error {
+	if ReadOnly {
+		return errReadOnly("stash changes")
+	}
+	args := []string{"stash", "push", "--include-untracked", "-m", message}
+	if len(Paths) > 0 {
+		args = append(append(args, "--"), Paths...)
+	}
+	_, err := runGitCommand(ctx, args...)
+	return err
+}
+
+// Commit creates a commit with the given message, adding a Signed-off-by
+// trailer when signOff is set. With Paths, only the changes under them are
+// committed.
+func Commit(ctx context.Context, message string, signOff bool) error {
+	if ReadOnly {
+		return errReadOnly("commit")
+	}
+	if native != nil {
+		return native.Commit(message, signOff)
+	}
+	args := []string{"commit", "-m", message}
+	if signOff {
+		args = append(args, "--signoff")
+	}
+	if len(Paths) > 0 {
+		// Only Paths are committed; other staged changes stay staged
+		args = append(append(args, "--only", "--"), Paths...)
+	}
+	_, err := runGitCommandTimeout(ctx, Timeouts.Commit, args...)
+	return err
+}
+
+// AmendMessage replaces the message of the HEAD commit, leaving staged
+// changes out of it
+func AmendMessage(ctx context.Context, message string) error {
+	if ReadOnly {
+		return errReadOnly("amend the commit")
+	}
+	_, err := runGitCommandTimeout(ctx, Timeouts.Commit, "commit", "--amend", "--only", "-m", message)
+	return err
+}
+
+// CommitFixup commits the staged changes as a "fixup! <subject>" commit
+// for sha, for `git rebase --autosquash` to fold into it
+func CommitFixup(ctx context.Context, sha string, signOff bool) error {
+	if ReadOnly {
+		return errReadOnly("commit")
+	}
+	args := []string{"commit", "--fixup", sha}
+	if signOff {
+		args = append(args, "--signoff")
+	}
+	_, err := runGitCommandTimeout(ctx, Timeouts.Commit, args...)
+	return err
+}
+
+// AddNote attaches text to sha as a git note under ref, replacing any
+// note it already has there
+func AddNote(ctx context.Context, ref, sha, text string) error {
+	if ReadOnly {
+		return errReadOnly("add a note")
+	}
+	_, err := runGitCommand(ctx, "notes", "--ref", ref, "add", "--force", "-m", text, sha)
+	return err
+}
+
+// GetNote returns the git note attached to rev under ref, or "" when there is none
+func GetNote(ctx context.Context, ref, rev string) (string, error) {
+	sha := ResolveCommit(ctx, rev)
+	if sha == "" {
+		return "", fmt.Errorf("unknown commit %q", rev)
+	}
+	// `git notes list` prints nothing (and fails) for a commit without a note
+	if object, _ := runGitCommand(ctx, "notes", "--ref", ref, "list", sha); object == "" {
+		return "", nil
+	}
+	return runGitCommand(ctx, "notes", "--ref", ref, "show", sha)
+}
+
+// GetCommitMessage returns the full message of the commit rev names
+func GetCommitMessage(ctx context.Context, rev string) (string, error) {
+	return runGitCommand(ctx, "log", "--max-count=1", "--format=%B", rev)
+}
+
+// Push pushes the current branch to its upstream. With forceWithLease,
+// the remote branch is overwritten unless it moved since the last fetch.
+func Push(ctx context.Context, forceWithLease bool) error {
+	if native != nil && !ReadOnly {
+		branch, err := native.CurrentBranch()
+		if err != nil {
+			return err
+		}
+		return native.Push(ctx, "", branch, forceWithLease, false)
+	}
+	return push(ctx, forceWithLease)
+}
+
+// PushSetUpstream pushes branch to remote and makes it the branch's upstream
+func PushSetUpstream(ctx context.Context, remote, branch string, forceWithLease bool) error {
+	if native != nil && !ReadOnly {
+		return native.Push(ctx, remote, branch, forceWithLease, true)
+	}
+	return push(ctx, forceWithLease, "--set-upstream", remote, branch)
+}
+
+// PushTo pushes HEAD to branch on remote without changing the upstream
+func PushTo(ctx context.Context, remote, branch string, forceWithLease bool) error {
+	if native != nil && !ReadOnly {
+		return native.Push(ctx, remote, branch, forceWithLease, false)
+	}
+	return push(ctx, forceWithLease, remote, "HEAD:refs/heads/"+branch)
+}
+
+func push(ctx context.Context, forceWithLease bool, args ...string) error {
+	if ReadOnly {
+		return errReadOnly("push")
+	}
+	if forceWithLease {
+		args = append([]string{"--force-with-lease"}, args...)
+	}
+	_, err := runGitCommandTimeout(ctx, Timeouts.Push, append([]string{"push"}, args...)...)
+	return err
+}
+
+// PushRef pushes a ref other than a branch, such as a notes ref, to the
+// same name on remote
+func PushRef(ctx context.Context, remote, ref string) error {
+	return push(ctx, false, remote, ref+":"+ref)
+}
+
+// Fetch updates the remote-tracking branches of remote
+func Fetch(ctx context.Context, remote string) error {
+	_, err := runGitCommandTimeout(ctx, Timeouts.Push, "fetch", "--quiet", remote)
+	return err
+}
+
+// FetchBranch updates the remote-tracking branch of a single remote branch
+func FetchBranch(ctx context.Context, remote, branch string) error {
+	_, err := runGitCommandTimeout(ctx, Timeouts.Push, "fetch", "--quiet", remote, "refs/heads/"+branch+":refs/remotes/"+remote+"/"+branch)
+	return err
+}
+
+// CountCommits returns the number of commits in a revision range such as "a..b"
+func CountCommits(ctx context.Context, revRange string) (int, error) {
+	out, err := runGitCommand(ctx, "rev-list", "--count", revRange)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(out)
+}
+
+// RefExists reports whether ref names a commit
+func RefExists(ctx context.Context, ref string) bool {
+	_, err := runGitCommand(ctx, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	return err == nil
+}
+
+// ResolveCommit returns the hash of the commit ref names, or "" if there is none
+func ResolveCommit(ctx context.Context, ref string) string {
+	sha, err := runGitCommand(ctx, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return ""
+	}
+	return sha
+}
+
+// Rebase replays the current branch's commits onto upstream. If the rebase
+// stops (e.g. on conflicts), it is aborted so the branch is left as it was.
+func Rebase(ctx context.Context, upstream string) error {
+	if ReadOnly {
+		return errReadOnly("rebase")
+	}
+	if _, err := runGitCommandTimeout(ctx, Timeouts.Commit, "rebase", "--autostash", upstream); err != nil {
+		// Abort even after Ctrl+C, so the branch isn't left mid-rebase
+		runGitCommand(context.WithoutCancel(ctx), "rebase", "--abort")
+		return err
+	}
+	return nil
+}
+
+// GetDefaultBranch returns the default branch of the origin remote, falling back to "main"
+func GetDefaultBranch(ctx context.Context) string {
+	ref, err := runGitCommand(ctx, "symbolic-ref", "--short", "refs/remotes/origin/HEAD")
+	if err != nil || ref == "" {
+		return "main"
+	}
+	return strings.TrimPrefix(ref, "origin/")
+}
+
+// ResolveBase returns a ref for the given base branch, using the origin
+// remote-tracking branch when there is no local branch with that name
+func ResolveBase(ctx context.Context, base string) (string, error) {
+	if _, err := runGitCommand(ctx, "rev-parse", "--verify", "--quiet", base); err == nil {
+		return base, nil
+	}
+	remote := "origin/" + base
+	if _, err := runGitCommand(ctx, "rev-parse", "--verify", "--quiet", remote); err == nil {
+		return remote, nil
+	}
+	return "", fmt.Errorf("base branch %q not found", base)
+}
+
+// GetBranchChangedFiles returns the files changed on the current branch since it diverged from base
+func GetBranchChangedFiles(ctx context.Context, base string) ([]string, error) {
+	output, err := runGitCommandTimeout(ctx, Timeouts.Diff, "diff", "--name-only", base+"...HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, file := range strings.Split(output, "\n") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// GetBranchDiff returns the diff of the current branch against base.
+// With summary set, only a --stat summary is returned.
+func GetBranchDiff(ctx context.Context, base string, summary bool) (string, error) {
+	if summary {
+		return runGitCommandTimeout(ctx, Timeouts.Diff, "diff", "-M", "-C", "--stat", base+"...HEAD")
+	}
+	return runGitCommandTimeout(ctx, Timeouts.Diff, "diff", "-M", "-C", base+"...HEAD")
+}
+
+// EmptyTree is the hash of git's empty tree, used as the base of a range with no parent
+const EmptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// GetRangeChangedFiles returns the files that differ between two commits
+func GetRangeChangedFiles(ctx context.Context, from, to string) ([]string, error) {
+	output, err := runGitCommandTimeout(ctx, Timeouts.Diff, withPathspec("diff", "--name-only", from, to)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, file := range strings.Split(output, "\n") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// MergeBase returns the best common ancestor of two commits
+func MergeBase(ctx context.Context, a, b string) (string, error) {
+	return runGitCommandTimeout(ctx, Timeouts.Diff, "merge-base", a, b)
+}
+
+// GetRangeMessages returns the full messages of the commits in from..to,
+// oldest first, as "- subject" lines with their bodies indented below
+func GetRangeMessages(ctx context.Context, from, to string) (string, error) {
+	output, err := runGitCommandTimeout(ctx, Timeouts.Diff, "log", "-z", "--reverse", "--no-merges", "--format=%B", from+".."+to)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, message := range strings.Split(output, "\x00") {
+		subject, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+		if subject == "" {
+			continue
+		}
+		b.WriteString("- " + subject + "\n")
+		for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				b.WriteString("  " + line + "\n")
+			}
+		}
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// GetRangeDiff returns the diff between two commits.
+// With summary set, only a --stat summary is returned.
+func GetRangeDiff(ctx context.Context, from, to string, summary bool) (string, error) {
+	if summary {
+		return runGitCommandTimeout(ctx, Timeouts.Diff, withPathspec("diff", "-M", "-C", "--stat", from, to)...)
+	}
+	return runGitCommandTimeout(ctx, Timeouts.Diff, withPathspec("diff", "-M", "-C", from, to)...)
+}
+
+// UnpushedBase returns the commit that the commits of sha not yet on remote
+// build on: the parent of the oldest unpushed commit, EmptyTree if that
+// commit has no parent, or sha itself if everything has been pushed
+func UnpushedBase(ctx context.Context, sha, remote string) (string, error) {
+	output, err := runGitCommand(ctx, "rev-list", "--reverse", sha, "--not", "--remotes="+remote)
+	if err != nil {
+		return "", err
+	}
+	oldest, _, _ := strings.Cut(output, "\n")
+	if oldest == "" {
+		return sha, nil
+	}
+	if parent, err := runGitCommand(ctx, "rev-parse", "--verify", "--quiet", oldest+"^"); err == nil {
+		return parent, nil
+	}
+	return EmptyTree, nil
+}
+
+// CommitInfo is a commit on the current branch
+type CommitInfo struct {
+	SHA     string
+	Parents []string
+	Message string
+}
+
+// GetCommits returns up to limit commits reachable from HEAD, newest first
+func GetCommits(ctx context.Context, limit int) ([]CommitInfo, error) {
+	output, err := runGitCommand(ctx, "log", "-z", "--max-count="+strconv.Itoa(limit), "--format=%H %P%n%B", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	var commits []CommitInfo
+	for _, entry := range strings.Split(output, "\x00") {
+		header, message, _ := strings.Cut(strings.TrimLeft(entry, "\n"), "\n")
+		fields := strings.Fields(header)
+		if len(fields) == 0 {
+			continue
+		}
+		commits = append(commits, CommitInfo{SHA: fields[0], Parents: fields[1:], Message: strings.TrimSpace(message)})
+	}
+	return commits, nil
+}
+
+// GetUnpushedCommits returns the commits of HEAD that are on no remote-tracking branch
+func GetUnpushedCommits(ctx context.Context) ([]string, error) {
+	output, err := runGitCommand(ctx, "rev-list", "HEAD", "--not", "--remotes")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(output), nil
+}
+
+// ResetSoft moves the current branch to rev, keeping the index and working tree
+func ResetSoft(ctx context.Context, rev string) error {
+	if ReadOnly {
+		return errReadOnly("reset")
+	}
+	_, err := runGitCommand(ctx, "reset", "--soft", rev)
+	return err
+}
+
+// WriteTree writes the index as a tree object and returns its hash
+func WriteTree(ctx context.Context) (string, error) {
+	return runGitCommand(ctx, "write-tree")
+}
+
+// ReadTree replaces the index with tree, leaving the working tree alone
+func ReadTree(ctx context.Context, tree string) error {
+	if ReadOnly {
+		return errReadOnly("change the index")
+	}
+	if _, err := runGitCommand(ctx, "read-tree", tree); err != nil {
+		return err
+	}
+	// Refresh the stat info read-tree dropped, so unchanged files don't look modified
+	runGitCommand(ctx, "update-index", "-q", "--refresh")
+	return nil
+}
+
+// IsPushed reports whether sha is on any remote-tracking branch
+func IsPushed(ctx context.Context, sha string) bool {
+	out, err := runGitCommand(ctx, "branch", "--remotes", "--contains", sha)
+	return err == nil && out != ""
+}
+
+// Revert creates a commit that undoes sha
+func Revert(ctx context.Context, sha string) error {
+	if ReadOnly {
+		return errReadOnly("revert")
+	}
+	_, err := runGitCommandTimeout(ctx, Timeouts.Commit, "revert", "--no-edit", sha)
+	return err
+}
+
+// GetBranchLog returns the subjects of commits on the current branch that are not in base
+func GetBranchLog(ctx context.Context, base string) (string, error) {
+	return runGitCommandTimeout(ctx, Timeouts.Diff, "log", "--reverse", "--pretty=format:- %s", base+"..HEAD")
+}
+
+// GetRangeLog returns the subjects of the commits after from up to to,
+// oldest first, as "- subject" lines. From EmptyTree, only to is listed.
+func GetRangeLog(ctx context.Context, from, to string) (string, error) {
+	if from == EmptyTree {
+		return runGitCommandTimeout(ctx, Timeouts.Diff, "log", "--max-count=1", "--pretty=format:- %s", to)
+	}
+	return runGitCommandTimeout(ctx, Timeouts.Diff, "log", "--reverse", "--pretty=format:- %s", from+".."+to)
+}
+
+// GetWhitespaceOnlyFiles returns the changed tracked files (staged or not)
+// whose changes against HEAD are all whitespace
+func GetWhitespaceOnlyFiles(ctx context.Context) ([]string, error) {
+	if _, err := runGitCommand(ctx, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return nil, nil
+	}
+	changed, err := runGitCommandTimeout(ctx, Timeouts.Diff, withPathspec("diff", "HEAD", "--no-renames", "--name-only", "-z")...)
+	if err != nil {
+		return nil, err
+	}
+	// --name-only ignores -w, but --numstat leaves out files without changes
+	significant, err := runGitCommandTimeout(ctx, Timeouts.Diff, withPathspec("diff", "HEAD", "-w", "--no-renames", "--numstat", "-z")...)
+	if err != nil {
+		return nil, err
+	}
+	kept := map[string]bool{}
+	for _, entry := range strings.Split(significant, "\x00") {
+		if fields := strings.SplitN(entry, "\t", 3); len(fields) == 3 {
+			kept[fields[2]] = true
+		}
+	}
+	var files []string
+	for _, path := range strings.Split(changed, "\x00") {
+		if path != "" && !kept[path] {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// UnstagePaths removes the changes of paths (relative to the repository
+// root) from the index, keeping them in the working tree
+func UnstagePaths(ctx context.Context, paths []string) error {
+	if ReadOnly {
+		return errReadOnly("unstage changes")
+	}
+	args := []string{"reset", "-q", "--"}
+	for _, path := range paths {
+		args = append(args, ":(top,literal)"+path)
+	}
+	_, err := runGitCommand(ctx, args...)
+	return err
+}
+
+// IsKnownPath reports whether path exists in the working tree or the index,
+// so a deleted file still counts
+func IsKnownPath(ctx context.Context, path string) bool {
+	output, err := runGitCommand(ctx, "ls-files", "--", path)
+	return err == nil && output != ""
+}
+
+// GetRecentSubjects returns the subjects of the last limit commits reachable
+// from HEAD, newest first, leaving out merges. A repository without commits
+// has none.
+func GetRecentSubjects(ctx context.Context, limit int) ([]string, error) {
+	if _, err := runGitCommand(ctx, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return nil, nil
+	}
+	output, err := runGitCommandTimeout(ctx, Timeouts.Diff, "log", "--no-merges", "--max-count="+strconv.Itoa(limit), "--format=%s", "HEAD")
+	if err != nil || output == "" {
+		return nil, err
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+// CreateBranch creates a new branch from HEAD and switches to it,
+// carrying over any uncommitted changes
+func CreateBranch(ctx context.Context, name string) error {
+	if ReadOnly {
+		return errReadOnly("create a branch")
+	}
+	if _, err := runGitCommand(ctx, "check-ref-format", "--branch", name); err != nil {
+		return fmt.Errorf("invalid branch name %q", name)
+	}
+	if _, err := runGitCommand(ctx, "rev-parse", "--verify", "--quiet", "refs/heads/"+name); err == nil {
+		return fmt.Errorf("branch %q already exists", name)
+	}
+	_, err := runGitCommand(ctx, "checkout", "-b", name)
+	return err
+}
+
+// GetHeadSHA returns the full SHA of the HEAD commit
+func GetHeadSHA(ctx context.Context) (string, error) {
+	if native != nil {
+		return native.HeadSHA()
+	}
+	return runGitCommand(ctx, "rev-parse", "HEAD")
+}
+
+// GetCurrentBranch returns the name of the checked out branch
+func GetCurrentBranch(ctx context.Context) (string, error) {
+	if native != nil {
+		return native.CurrentBranch()
+	}
+	return runGitCommand(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+}
+
+// IsDetached reports whether HEAD points at a commit rather than a branch
+func IsDetached(ctx context.Context) bool {
+	if native != nil {
+		return native.IsDetached()
+	}
+	_, err := runGitCommand(ctx, "symbolic-ref", "--quiet", "HEAD")
+	return err != nil
+}
+
+// Operations git can be in the middle of, with the file in the git
+// directory that marks each one
+var operationMarkers = []struct{ name, path string }{
+	{"rebase", "rebase-merge"},
+	{"rebase", "rebase-apply"},
+	{"merge", "MERGE_HEAD"},
+	{"cherry-pick", "CHERRY_PICK_HEAD"},
+	{"revert", "REVERT_HEAD"},
+	{"bisect", "BISECT_LOG"},
+}
+
+// GetInProgressOperation returns the operation the repository is in the
+// middle of ("rebase", "merge", "cherry-pick", "revert", or "bisect"), or ""
+func GetInProgressOperation(ctx context.Context) string {
+	for _, marker := range operationMarkers {
+		path, err := runGitCommand(ctx, "rev-parse", "--git-path", marker.path)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			return marker.name
+		}
+	}
+	return ""
+}
+
+// GetUpstream returns the upstream branch of the current branch (e.g. "origin/main"),
+// or "" if none is configured
+func GetUpstream(ctx context.Context) string {
+	if native != nil {
+		return native.Upstream()
+	}
+	upstream, err := runGitCommand(ctx, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
+	if err != nil {
+		return ""
+	}
+	return upstream
+}
+
+// GetRemoteURL returns the URL of the given remote
+func GetRemoteURL(ctx context.Context, remote string) (string, error) {
+	return runGitCommand(ctx, "remote", "get-url", remote)
+}
+
+// ParseRemoteURL splits a remote URL (https://host/path.git, git@host:path.git,
+// ssh://git@host:port/path) into its host and repository path
+func ParseRemoteURL(remoteURL string) (host, path string, err error) {
+	remoteURL = strings.TrimSpace(remoteURL)
+	rest := remoteURL
+
+	if i := strings.Index(rest, "://"); i >= 0 {
+		rest = rest[i+3:]
+		slash := strings.Index(rest, "/")
+		if slash < 0 {
+			return "", "", fmt.Errorf("cannot parse remote URL %q", remoteURL)
+		}
+		host, path = rest[:slash], rest[slash+1:]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		if colon := strings.Index(host, ":"); colon >= 0 {
+			host = host[:colon]
+		}
+	} else {
+		// scp-like syntax: [user@]host:path
+		colon := strings.Index(rest, ":")
+		if colon < 0 {
+			return "", "", fmt.Errorf("cannot parse remote URL %q", remoteURL)
+		}
+		host, path = rest[:colon], rest[colon+1:]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return "", "", fmt.Errorf("cannot parse remote URL %q", remoteURL)
+	}
+	return host, path, nil
+}
+
+// GetConfigValue returns the value of a git config key (repository config
+// takes precedence over global), or "" if the key is not set
+func GetConfigValue(ctx context.Context, key string) string {
+	if native != nil {
+		return native.ConfigValue(key)
+	}
+	value, err := runGitCommand(ctx, "config", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// SetLocalConfigValue sets a git config key in the repository's own config
+// (.git/config), which unlike the working tree can't be changed by a pull
+func SetLocalConfigValue(ctx context.Context, key, value string) error {
+	if ReadOnly {
+		return errReadOnly("change git config")
+	}
+	_, err := runGitCommand(ctx, "config", "--local", key, value)
+	return err
+}
+
+// GetConfigSection returns every git config entry under section (e.g.
+// "claude-commit"), keyed by name as git prints it: section and variable
+// lowercased, subsections as written
+func GetConfigSection(ctx context.Context, section string) map[string]string {
+	entries := make(map[string]string)
+	output, err := runGitCommand(ctx, "config", "--get-regexp", "^"+regexp.QuoteMeta(section)+`\.`)
+	if err != nil {
+		return entries
+	}
+	for _, line := range strings.Split(output, "\n") {
+		name, value, _ := strings.Cut(line, " ")
+		if name != "" {
+			entries[name] = value
+		}
+	}
+	return entries
+}
+
+// GetGitPath returns the absolute path of name inside the repository's git
+// directory, e.g. "index" (per worktree in linked worktrees)
+func GetGitPath(ctx context.Context, name string) (string, error) {
+	path, err := runGitCommand(ctx, "rev-parse", "--git-path", name)
+	if err != nil {
+		return "", err
+	}
+	return absPath(ctx, path)
+}
+
+// GetHooksDir returns the directory git runs hooks from. --git-path honors
+// core.hooksPath, resolving a relative one (e.g. ".husky") against the
+// worktree root like git does when it runs hooks.
+func GetHooksDir(ctx context.Context) (string, error) {
+	dir, err := runGitCommand(ctx, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	return absPath(ctx, dir)
+}
+
+// GetEditor returns the editor git would use for commit messages
+// (GIT_EDITOR, core.editor, VISUAL, EDITOR, then git's default)
+func GetEditor(ctx context.Context) (string, error) {
+	return runGitCommand(ctx, "var", "GIT_EDITOR")
+}
+
+// IsInsideWorkTree reports whether the current directory is inside a git
+// working tree. The error is only set when git itself can't run.
+func IsInsideWorkTree(ctx context.Context) (bool, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return false, err
+	}
+	out, err := runGitCommand(ctx, "rev-parse", "--is-inside-work-tree")
+	return err == nil && out == "true", nil
+}
+
+// Version returns the installed git version, e.g. "git version 2.43.0"
+func Version(ctx context.Context) (string, error) {
+	return runGitCommand(ctx, "--version")
+}
+
+// CheckRemote contacts remote the way a push would, without changing
+// anything, and fails if it can't be reached or authentication fails.
+// Credential prompts are disabled so it doesn't wait for input.
+func CheckRemote(ctx context.Context, remote string, timeout time.Duration) error {
+	_, err := runGitCommandEnv(ctx, timeout, []string{"GIT_TERMINAL_PROMPT=0"}, "ls-remote", "--heads", remote)
+	return err
+}
+
+// Init creates an empty repository in the current directory
+func Init(ctx context.Context) error {
+	if ReadOnly {
+		return errReadOnly("create a repository")
+	}
+	_, err := runGitCommand(ctx, "init")
+	return err
+}
+
+// GetRepoRoot returns the top-level directory of the current repository
+func GetRepoRoot(ctx context.Context) (string, error) {
+	if native != nil {
+		return native.RepoRoot()
+	}
+	return runGitCommand(ctx, "rev-parse", "--show-toplevel")
+}
+
+// GetPrefix returns the path of the current directory relative to the
+// repository root, with a trailing slash ("" at the root)
+func GetPrefix(ctx context.Context) (string, error) {
+	return runGitCommand(ctx, "rev-parse", "--show-prefix")
+}
+
+// GetRepoRootOf returns the top-level directory of the repository containing dir
+func GetRepoRootOf(ctx context.Context, dir string) (string, error) {
+	return runGitCommand(ctx, "-C", dir, "rev-parse", "--show-toplevel")
+}
+
+// GetWorktree returns the top-level directory of the current working tree
+// and whether it is a linked worktree (from `git worktree add`, where .git
+// is a file pointing into the main repository)
+func GetWorktree(ctx context.Context) (root string, linked bool) {
+	out, err := runGitCommand(ctx, "rev-parse", "--show-toplevel", "--git-dir", "--git-common-dir")
+	lines := strings.Split(out, "\n")
+	if err != nil || len(lines) != 3 {
+		return "", false
+	}
+	gitDir, _ := absPath(ctx, lines[1])
+	commonDir, _ := absPath(ctx, lines[2])
+	return lines[0], gitDir != commonDir
+}
+
+func runGitCommand(ctx context.Context, args ...string) (string, error) {
+	return runGitCommandTimeout(ctx, 0, args...)
+}
+
+// runGitCommandTimeout runs git with the given timeout (zero means no limit).
+// On a non-zero exit the trimmed stdout is still returned along with the error.
+func runGitCommandTimeout(ctx context.Context, timeout time.Duration, args ...string) (string, error) {
+	return runGitCommandEnv(ctx, timeout, nil, args...)
+}
+
+// runGitCommandEnv is runGitCommandTimeout with extra environment variables
+func runGitCommandEnv(ctx context.Context, timeout time.Duration, env []string, args ...string) (string, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dirOf(ctx)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Interrupt rather than kill, so git removes its lock files on the way out
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	// Hooks may keep the output pipes open after git is killed, and git
+	// itself is killed if it ignores the interrupt
+	cmd.WaitDelay = 5 * time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	debuglog.Log("git", "args", strings.Join(args, " "), "duration", time.Since(start), "stdout_bytes", stdout.Len(), "error", err)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", &TimeoutError{Command: "git " + subcommand(args), After: timeout}
+		}
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return "", fmt.Errorf("git %s was interrupted: %w", subcommand(args), context.Canceled)
+		}
+		return strings.TrimSpace(stdout.String()), fmt.Errorf("git command failed: %w, stderr: %s", err, stderr.String())
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// dirKey is the context key of the directory set by WithDir
+type dirKey struct{}
+
+// WithDir returns a context whose git commands run in dir instead of the
+// current directory, so one process can work on several repositories
+func WithDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, dirKey{}, dir)
+}
+
+// dirOf returns the directory set by WithDir, or "" for the current one
+func dirOf(ctx context.Context) string {
+	dir, _ := ctx.Value(dirKey{}).(string)
+	return dir
+}
+
+// absPath makes a path printed by git absolute; relative paths are relative
+// to the directory git ran in
+func absPath(ctx context.Context, path string) (string, error) {
+	if dir := dirOf(ctx); dir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return filepath.Abs(path)
+}
+
+// subcommand returns the git subcommand of args, skipping "-C <dir>"
+func subcommand(args []string) string {
+	for len(args) > 2 && args[0] == "-C" {
+		args = args[2:]
+	}
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+// TimeoutError is returned when a git command exceeds its stage timeout
+type TimeoutError struct {
+	Command string
+	After   time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %s", e.Command, e.After)
 }
@@ -0,0 +1,527 @@
+//go:build gogit
+
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+func init() {
+	openGoGit = func() (nativeBackend, error) {
+		repo, err := gogit.PlainOpenWithOptions(".", &gogit.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+		if err != nil {
+			return nil, err
+		}
+		wt, err := repo.Worktree()
+		if err != nil {
+			return nil, err
+		}
+		return &goGitRepo{repo: repo, wt: wt}, nil
+	}
+}
+
+// goGitRepo implements nativeBackend with go-git. Hooks don't run, and
+// pushing only authenticates through the SSH agent.
+type goGitRepo struct {
+	repo *gogit.Repository
+	wt   *gogit.Worktree
+}
+
+func (g *goGitRepo) RepoRoot() (string, error) {
+	return g.wt.Filesystem.Root(), nil
+}
+
+func (g *goGitRepo) CurrentBranch() (string, error) {
+	head, err := g.repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return "", err
+	}
+	if head.Type() == plumbing.SymbolicReference {
+		return head.Target().Short(), nil
+	}
+	return "HEAD", nil // Detached, like git rev-parse --abbrev-ref HEAD
+}
+
+func (g *goGitRepo) HeadSHA() (string, error) {
+	head, err := g.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return head.Hash().String(), nil
+}
+
+func (g *goGitRepo) IsDetached() bool {
+	head, err := g.repo.Storer.Reference(plumbing.HEAD)
+	return err == nil && head.Type() == plumbing.HashReference
+}
+
+func (g *goGitRepo) Upstream() string {
+	branch, err := g.CurrentBranch()
+	if err != nil {
+		return ""
+	}
+	cfg, err := g.repo.Config()
+	if err != nil {
+		return ""
+	}
+	b, ok := cfg.Branches[branch]
+	if !ok || b.Remote == "" || b.Merge == "" {
+		return ""
+	}
+	return b.Remote + "/" + b.Merge.Short()
+}
+
+// ConfigValue reads "section.name" or "section.subsection.name" from the
+// repository and global config
+func (g *goGitRepo) ConfigValue(key string) string {
+	cfg, err := g.repo.ConfigScoped(config.GlobalScope)
+	if err != nil {
+		return ""
+	}
+	first, last := strings.Index(key, "."), strings.LastIndex(key, ".")
+	if first < 0 {
+		return ""
+	}
+	section := cfg.Raw.Section(key[:first])
+	if first == last {
+		return section.Option(key[last+1:])
+	}
+	return section.Subsection(key[first+1 : last]).Option(key[last+1:])
+}
+
+// status returns the changed files, leaving out the Exclude patterns
+func (g *goGitRepo) status() (gogit.Status, error) {
+	status, err := g.wt.Status()
+	if err != nil {
+		return nil, err
+	}
+	for file, s := range status {
+		if excluded(file) || (s.Staging == gogit.Unmodified && s.Worktree == gogit.Unmodified) {
+			delete(status, file)
+		}
+	}
+	return status, nil
+}
+
+// excluded approximates the ":(exclude)" pathspecs built from Exclude:
+// glob patterns match the whole path or the file name, and "dir/" matches
+// everything below dir
+func excluded(file string) bool {
+	for _, pattern := range Exclude {
+		if strings.HasSuffix(pattern, "/") && strings.HasPrefix(file, pattern) {
+			return true
+		}
+		if matched, _ := path.Match(pattern, file); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, path.Base(file)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// files returns the sorted paths in status that match keep
+func files(status gogit.Status, keep func(*gogit.FileStatus) bool) []string {
+	var paths []string
+	for file, s := range status {
+		if keep(s) {
+			paths = append(paths, file)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func isUntracked(s *gogit.FileStatus) bool { return s.Worktree == gogit.Untracked }
+
+func isStaged(s *gogit.FileStatus) bool {
+	return s.Staging != gogit.Unmodified && s.Staging != gogit.Untracked
+}
+
+func isUnstaged(s *gogit.FileStatus) bool {
+	return s.Worktree != gogit.Unmodified && s.Worktree != gogit.Untracked
+}
+
+func (g *goGitRepo) ChangedFiles() ([]string, error) {
+	status, err := g.status()
+	if err != nil {
+		return nil, err
+	}
+	return files(status, func(*gogit.FileStatus) bool { return true }), nil
+}
+
+func (g *goGitRepo) StagedFiles() ([]string, error) {
+	status, err := g.status()
+	if err != nil {
+		return nil, err
+	}
+	return files(status, isStaged), nil
+}
+
+func (g *goGitRepo) UntrackedFiles() ([]string, error) {
+	status, err := g.status()
+	if err != nil {
+		return nil, err
+	}
+	return files(status, isUntracked), nil
+}
+
+// Diff builds the same three sections as the git version: unstaged changes
+// (index to working tree), staged changes (HEAD to index), and untracked files
+func (g *goGitRepo) Diff(omit []string) (string, error) {
+	unstaged, staged, untracked, err := g.patches(omit)
+	if err != nil {
+		return "", err
+	}
+	if len(unstaged) == 0 && len(staged) == 0 && len(untracked) == 0 {
+		return "", nil
+	}
+
+	sections := make([]string, 3)
+	for i, patches := range [][]fdiff.FilePatch{unstaged, staged, untracked} {
+		var buf bytes.Buffer
+		if err := fdiff.NewUnifiedEncoder(&buf, fdiff.DefaultContextLines).Encode(patch(patches)); err != nil {
+			return "", err
+		}
+		sections[i] = strings.TrimSpace(buf.String())
+	}
+	return fmt.Sprintf("--- UNSTAGED CHANGES ---\n%s\n--- STAGED CHANGES ---\n%s\n--- UNTRACKED FILES ---\n%s", sections[0], sections[1], sections[2]), nil
+}
+
+// DiffSummary lists each changed file with its added and deleted line counts
+func (g *goGitRepo) DiffSummary(omit []string) (string, error) {
+	unstaged, staged, untracked, err := g.patches(omit)
+	if err != nil {
+		return "", err
+	}
+	if len(unstaged) == 0 && len(staged) == 0 && len(untracked) == 0 {
+		return "", nil
+	}
+
+	stat := func(patches []fdiff.FilePatch) string {
+		var b strings.Builder
+		for _, p := range patches {
+			from, to := p.Files()
+			name := ""
+			if to != nil {
+				name = to.Path()
+			} else {
+				name = from.Path()
+			}
+			if p.IsBinary() {
+				fmt.Fprintf(&b, " %s | Bin\n", name)
+				continue
+			}
+			added, deleted := 0, 0
+			for _, c := range p.Chunks() {
+				lines := strings.Count(c.Content(), "\n")
+				switch c.Type() {
+				case fdiff.Add:
+					added += lines
+				case fdiff.Delete:
+					deleted += lines
+				}
+			}
+			fmt.Fprintf(&b, " %s | %d +%d -%d\n", name, added+deleted, added, deleted)
+		}
+		return b.String()
+	}
+
+	untrackedSummary := ""
+	if len(untracked) > 0 {
+		untrackedSummary = fmt.Sprintf("%d untracked files", len(untracked))
+	}
+	return fmt.Sprintf("--- UNSTAGED CHANGES ---\n%s\n--- STAGED CHANGES ---\n%s\n--- UNTRACKED FILES ---\n%s", stat(unstaged), stat(staged), untrackedSummary), nil
+}
+
+// patches compares HEAD, the index, and the working tree for every changed file
+func (g *goGitRepo) patches(omit []string) (unstaged, staged, untracked []fdiff.FilePatch, err error) {
+	status, err := g.status()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	idx, err := g.repo.Storer.Index()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for _, file := range files(status, func(*gogit.FileStatus) bool { return true }) {
+		if slices.Contains(omit, file) {
+			continue
+		}
+		s := status[file]
+		if isUntracked(s) {
+			to, err := g.worktreeVersion(file)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			untracked = append(untracked, newFilePatch(nil, to))
+			continue
+		}
+		if isStaged(s) {
+			from, err := g.headVersion(file)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			to, err := g.indexVersion(idx, file)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			staged = append(staged, newFilePatch(from, to))
+		}
+		if isUnstaged(s) {
+			from, err := g.indexVersion(idx, file)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			to, err := g.worktreeVersion(file)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			unstaged = append(unstaged, newFilePatch(from, to))
+		}
+	}
+	return unstaged, staged, untracked, nil
+}
+
+// fileVersion is one side of a file patch; a nil *fileVersion means the
+// file doesn't exist on that side
+type fileVersion struct {
+	path    string
+	hash    plumbing.Hash
+	mode    filemode.FileMode
+	content string
+}
+
+func (f *fileVersion) Hash() plumbing.Hash     { return f.hash }
+func (f *fileVersion) Mode() filemode.FileMode { return f.mode }
+func (f *fileVersion) Path() string            { return f.path }
+
+func (g *goGitRepo) headVersion(file string) (*fileVersion, error) {
+	head, err := g.repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil // No commits yet
+	}
+	if err != nil {
+		return nil, err
+	}
+	commit, err := g.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	f, err := commit.File(file)
+	if err != nil {
+		return nil, nil // Not in HEAD
+	}
+	content, err := f.Contents()
+	if err != nil {
+		return nil, err
+	}
+	return &fileVersion{path: file, hash: f.Hash, mode: f.Mode, content: content}, nil
+}
+
+func (g *goGitRepo) indexVersion(idx *index.Index, file string) (*fileVersion, error) {
+	e, err := idx.Entry(file)
+	if err != nil {
+		return nil, nil // Not in the index
+	}
+	blob, err := g.repo.BlobObject(e.Hash)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(reader); err != nil {
+		return nil, err
+	}
+	return &fileVersion{path: file, hash: e.Hash, mode: e.Mode, content: buf.String()}, nil
+}
+
+func (g *goGitRepo) worktreeVersion(file string) (*fileVersion, error) {
+	full := filepath.Join(g.wt.Filesystem.Root(), filepath.FromSlash(file))
+	info, err := os.Lstat(full)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil // Deleted
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(full)
+	if err != nil {
+		return nil, err
+	}
+	mode := filemode.Regular
+	if info.Mode()&0111 != 0 {
+		mode = filemode.Executable
+	}
+	return &fileVersion{path: file, hash: plumbing.ComputeHash(plumbing.BlobObject, data), mode: mode, content: string(data)}, nil
+}
+
+// filePatch implements go-git's diff.FilePatch for the unified encoder
+type filePatch struct {
+	from, to *fileVersion
+	binary   bool
+	chunks   []fdiff.Chunk
+}
+
+func newFilePatch(from, to *fileVersion) *filePatch {
+	p := &filePatch{from: from, to: to}
+	var fromContent, toContent string
+	if from != nil {
+		fromContent = from.content
+	}
+	if to != nil {
+		toContent = to.content
+	}
+	if isBinaryContent(fromContent) || isBinaryContent(toContent) {
+		p.binary = true
+		return p
+	}
+	for _, d := range diff.Do(fromContent, toContent) {
+		op := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			op = fdiff.Add
+		case diffmatchpatch.DiffDelete:
+			op = fdiff.Delete
+		}
+		p.chunks = append(p.chunks, chunk{content: d.Text, op: op})
+	}
+	return p
+}
+
+func (p *filePatch) IsBinary() bool        { return p.binary }
+func (p *filePatch) Chunks() []fdiff.Chunk { return p.chunks }
+
+func (p *filePatch) Files() (from, to fdiff.File) {
+	// Typed nils would not compare equal to nil in the encoder
+	if p.from != nil {
+		from = p.from
+	}
+	if p.to != nil {
+		to = p.to
+	}
+	return from, to
+}
+
+type chunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c chunk) Content() string       { return c.content }
+func (c chunk) Type() fdiff.Operation { return c.op }
+
+type patch []fdiff.FilePatch
+
+func (p patch) FilePatches() []fdiff.FilePatch { return p }
+func (p patch) Message() string                { return "" }
+
+// isBinaryContent applies git's check: a NUL byte in the first 8000 bytes
+func isBinaryContent(content string) bool {
+	return strings.IndexByte(content[:min(len(content), 8000)], 0) >= 0
+}
+
+func (g *goGitRepo) StageAll() error {
+	return g.wt.AddWithOptions(&gogit.AddOptions{All: true})
+}
+
+func (g *goGitRepo) Add(file string) error {
+	_, err := g.wt.Add(file)
+	return err
+}
+
+func (g *goGitRepo) Unstage(file string) error {
+	return g.wt.Restore(&gogit.RestoreOptions{Staged: true, Files: []string{file}})
+}
+
+// trailerLine matches a "Key: value" trailer such as "Signed-off-by: ..."
+var trailerLine = regexp.MustCompile(`^[A-Za-z0-9-]+: `)
+
+func (g *goGitRepo) Commit(message string, signOff bool) error {
+	if signOff {
+		cfg, err := g.repo.ConfigScoped(config.GlobalScope)
+		if err != nil {
+			return err
+		}
+		// Join an existing trailer block, like git commit --signoff
+		lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+		separator := "\n\n"
+		if len(lines) > 1 && trailerLine.MatchString(lines[len(lines)-1]) {
+			separator = "\n"
+		}
+		message = strings.TrimRight(message, "\n") + separator + fmt.Sprintf("Signed-off-by: %s <%s>", cfg.User.Name, cfg.User.Email)
+	}
+	_, err := g.wt.Commit(message, &gogit.CommitOptions{})
+	return err
+}
+
+func (g *goGitRepo) Push(ctx context.Context, remote, branch string, forceWithLease, setUpstream bool) error {
+	local, err := g.CurrentBranch()
+	if err != nil {
+		return err
+	}
+	cfg, err := g.repo.Config()
+	if err != nil {
+		return err
+	}
+	if remote == "" {
+		b, ok := cfg.Branches[local]
+		if !ok || b.Remote == "" || b.Merge == "" {
+			return fmt.Errorf("branch %s has no upstream branch", local)
+		}
+		remote, branch = b.Remote, b.Merge.Short()
+	}
+
+	if Timeouts.Push > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, Timeouts.Push)
+		defer cancel()
+	}
+	opts := &gogit.PushOptions{
+		RemoteName: remote,
+		RefSpecs:   []config.RefSpec{config.RefSpec("refs/heads/" + local + ":refs/heads/" + branch)},
+	}
+	if forceWithLease {
+		opts.ForceWithLease = &gogit.ForceWithLease{}
+	}
+	if err := g.repo.PushContext(ctx, opts); err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return &TimeoutError{Command: "git push", After: Timeouts.Push}
+		}
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return fmt.Errorf("git push was interrupted: %w", context.Canceled)
+		}
+		return err
+	}
+
+	if setUpstream {
+		cfg.Branches[local] = &config.Branch{Name: local, Remote: remote, Merge: plumbing.NewBranchReferenceName(branch)}
+		return g.repo.SetConfig(cfg)
+	}
+	return nil
+}
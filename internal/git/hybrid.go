@@ -0,0 +1,154 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/debuglog"
+)
+
+// DiffMode is how much of a changeset's diff the model gets
+type DiffMode int
+
+const (
+	FullDiff    DiffMode = iota
+	HybridDiff           // Full diffs of the smaller files, line counts of the oversized ones
+	SummaryDiff          // A stat summary of every file
+)
+
+// ChooseDiff fetches the diff of a changeset of files changed files with
+// get: the full diff when it fits in SummaryTokens, else the full diffs of
+// as many of the smallest files as fit with line counts for the rest, else
+// the stat summary. Too many files (FileSummaryThreshold) go straight to the
+// summary.
+func ChooseDiff(files int, get func(summary bool) (string, error)) (string, DiffMode, error) {
+	if UseSummary(files) {
+		diff, err := get(true)
+		return diff, SummaryDiff, err
+	}
+	diff, err := get(false)
+	if err != nil || !TooLarge(diff) {
+		return diff, FullDiff, err
+	}
+	if hybrid, ok := hybridDiff(diff, SummaryTokens*4); ok {
+		debuglog.Log("diff too large, using the hybrid diff", "bytes", len(diff), "hybrid_bytes", len(hybrid), "summary_tokens", SummaryTokens)
+		return hybrid, HybridDiff, nil
+	}
+	debuglog.Log("diff too large, using the summary", "bytes", len(diff), "summary_tokens", SummaryTokens)
+	diff, err = get(true)
+	return diff, SummaryDiff, err
+}
+
+// diffSection is the diff of one file, or the text between files (such as
+// the "--- STAGED CHANGES ---" headers), which is always kept
+type diffSection struct {
+	path string // Empty for text between files
+	text string
+}
+
+// hybridDiff keeps the diffs of the smallest files that fit in budget bytes
+// together, in their original order, and lists the others with their line
+// counts at the end. It fails unless at least one file is kept and one left
+// out.
+func hybridDiff(diff string, budget int) (string, bool) {
+	sections := splitDiff(diff)
+	var files []int
+	used := 0
+	for i, section := range sections {
+		if section.path == "" {
+			used += len(section.text)
+		} else {
+			files = append(files, i)
+		}
+	}
+	sort.SliceStable(files, func(a, b int) bool {
+		return len(sections[files[a]].text) < len(sections[files[b]].text)
+	})
+
+	kept := make(map[int]bool)
+	for _, i := range files {
+		// Leave room for a line about each file that doesn't fit
+		if used+len(sections[i].text)+80*(len(files)-len(kept)-1) > budget {
+			break
+		}
+		kept[i] = true
+		used += len(sections[i].text)
+	}
+	if len(kept) == 0 || len(kept) == len(files) {
+		return "", false
+	}
+
+	var b, summarized strings.Builder
+	for i, section := range sections {
+		if section.path == "" || kept[i] {
+			b.WriteString(section.text)
+			continue
+		}
+		added, deleted := countDiffLines(section.text)
+		fmt.Fprintf(&summarized, "%s | +%d -%d\n", section.path, added, deleted)
+	}
+	fmt.Fprintf(&b, "\n--- OVERSIZED FILES (%d; diff too large to show, line counts only) ---\n%s", len(files)-len(kept), summarized.String())
+	return b.String(), true
+}
+
+// diffHeaders are the lines GetDiff puts between its parts
+var diffHeaders = map[string]bool{
+	"--- UNSTAGED CHANGES ---": true,
+	"--- STAGED CHANGES ---":   true,
+	"--- UNTRACKED FILES ---":  true,
+}
+
+// splitDiff cuts a diff into sections at each "diff --git" line and
+// GetDiff header
+func splitDiff(diff string) []diffSection {
+	var sections []diffSection
+	current := diffSection{}
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			current.text = text.String()
+			sections = append(sections, current)
+		}
+		text.Reset()
+	}
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			current = diffSection{path: diffPath(line)}
+		case diffHeaders[strings.TrimSpace(line)]:
+			flush()
+			current = diffSection{}
+		}
+		text.WriteString(line)
+	}
+	flush()
+	return sections
+}
+
+// diffPath returns the new path of a "diff --git a/old b/new" line
+func diffPath(line string) string {
+	line = strings.TrimSpace(strings.TrimPrefix(line, "diff --git "))
+	if i := strings.LastIndex(line, " b/"); i >= 0 {
+		return line[i+len(" b/"):]
+	}
+	return line
+}
+
+// countDiffLines counts the added and deleted lines in the diff of a file
+func countDiffLines(text string) (added, deleted int) {
+	inHunk := false
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk:
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			deleted++
+		}
+	}
+	return added, deleted
+}
@@ -0,0 +1,268 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/network"
+)
+
+const apiURL = "https://api.github.com"
+
+// Client is a minimal GitHub REST API client
+type Client struct {
+	Token     string
+	UserAgent string
+	HTTP      *http.Client
+}
+
+// PullRequest describes a pull request to create
+type PullRequest struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	Head  string `json:"head"`
+	Base  string `json:"base"`
+	Draft bool   `json:"draft"`
+}
+
+// CreatedPullRequest is the subset of the API response we use
+type CreatedPullRequest struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Issue is the subset of an issue we use
+type Issue struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+}
+
+// NewClient returns a client authenticated with token
+func NewClient(token string, userAgent string) *Client {
+	return &Client{Token: token, UserAgent: userAgent, HTTP: network.Client()}
+}
+
+// ResolveToken returns the configured token, or falls back to the
+// GITHUB_TOKEN/GH_TOKEN environment variables and `gh auth token`
+func ResolveToken(configured string) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+	for _, env := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token := os.Getenv(env); token != "" {
+			return token, nil
+		}
+	}
+
+	if _, err := exec.LookPath("gh"); err == nil {
+		output, err := exec.Command("gh", "auth", "token").Output()
+		if err == nil && strings.TrimSpace(string(output)) != "" {
+			return strings.TrimSpace(string(output)), nil
+		}
+	}
+
+	return "", fmt.Errorf("no GitHub token found (set github_token in config, GITHUB_TOKEN, or run `gh auth login`)")
+}
+
+var remotePattern = regexp.MustCompile(`github\.com[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// ParseRemote extracts the owner and repository name from a GitHub remote URL
+// (https://github.com/owner/repo.git, git@github.com:owner/repo.git, ...)
+func ParseRemote(remoteURL string) (owner, repo string, err error) {
+	matches := remotePattern.FindStringSubmatch(strings.TrimSpace(remoteURL))
+	if matches == nil {
+		return "", "", fmt.Errorf("remote %q is not a GitHub repository", remoteURL)
+	}
+	return matches[1], matches[2], nil
+}
+
+// CreatePullRequest opens a pull request in owner/repo
+func (c *Client) CreatePullRequest(owner, repo string, pr PullRequest) (*CreatedPullRequest, error) {
+	var created CreatedPullRequest
+	path := fmt.Sprintf("/repos/%s/%s/pulls", owner, repo)
+	if err := c.do("POST", path, pr, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// FindPullRequest returns the open pull request of owner/repo from head
+// ("owner:branch") into base, or nil when there is none
+func (c *Client) FindPullRequest(owner, repo, head, base string) (*CreatedPullRequest, error) {
+	var found []CreatedPullRequest
+	path := fmt.Sprintf("/repos/%s/%s/pulls?state=open&head=%s&base=%s", owner, repo, url.QueryEscape(head), url.QueryEscape(base))
+	if err := c.do("GET", path, nil, &found); err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+	return &found[0], nil
+}
+
+// GetIssue fetches issue number of owner/repo
+func (c *Client) GetIssue(owner, repo string, number int) (*Issue, error) {
+	var issue Issue
+	path := fmt.Sprintf("/repos/%s/%s/issues/%d", owner, repo, number)
+	if err := c.do("GET", path, nil, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// Comment is the subset of an issue or pull request comment we use
+type Comment struct {
+	ID      int64  `json:"id"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+}
+
+// ListComments returns the comments of issue or pull request number, up to
+// the first 100
+func (c *Client) ListComments(owner, repo string, number int) ([]Comment, error) {
+	var comments []Comment
+	path := fmt.Sprintf("/repos/%s/%s/issues/%d/comments?per_page=100", owner, repo, number)
+	if err := c.do("GET", path, nil, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// CreateComment comments on issue or pull request number
+func (c *Client) CreateComment(owner, repo string, number int, body string) (*Comment, error) {
+	var created Comment
+	path := fmt.Sprintf("/repos/%s/%s/issues/%d/comments", owner, repo, number)
+	if err := c.do("POST", path, map[string]string{"body": body}, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateComment replaces the body of comment id
+func (c *Client) UpdateComment(owner, repo string, id int64, body string) (*Comment, error) {
+	var updated Comment
+	path := fmt.Sprintf("/repos/%s/%s/issues/comments/%d", owner, repo, id)
+	if err := c.do("PATCH", path, map[string]string{"body": body}, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// CreateReview submits a pull request review. event is COMMENT,
+// REQUEST_CHANGES, or APPROVE.
+func (c *Client) CreateReview(owner, repo string, number int, commitID, body, event string) (string, error) {
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	payload := map[string]string{"commit_id": commitID, "body": body, "event": event}
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews", owner, repo, number)
+	if err := c.do("POST", path, payload, &created); err != nil {
+		return "", err
+	}
+	return created.HTMLURL, nil
+}
+
+// CreateStatus sets a commit status on sha. state is success, failure,
+// error, or pending; statusContext names the check.
+func (c *Client) CreateStatus(owner, repo, sha, state, statusContext, description string) error {
+	payload := map[string]string{"state": state, "context": statusContext, "description": description}
+	path := fmt.Sprintf("/repos/%s/%s/statuses/%s", owner, repo, sha)
+	return c.do("POST", path, payload, nil)
+}
+
+// RequestReviewers asks the given users for review. Entries of the form
+// "org/team" are requested as team reviewers.
+func (c *Client) RequestReviewers(owner, repo string, number int, reviewers []string) error {
+	payload := struct {
+		Reviewers     []string `json:"reviewers,omitempty"`
+		TeamReviewers []string `json:"team_reviewers,omitempty"`
+	}{}
+	for _, reviewer := range reviewers {
+		if idx := strings.Index(reviewer, "/"); idx >= 0 {
+			payload.TeamReviewers = append(payload.TeamReviewers, reviewer[idx+1:])
+		} else {
+			payload.Reviewers = append(payload.Reviewers, reviewer)
+		}
+	}
+
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/requested_reviewers", owner, repo, number)
+	return c.do("POST", path, payload, nil)
+}
+
+// do sends a JSON request to the API and decodes the JSON response into out
+func (c *Client) do(method, path string, in interface{}, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, apiURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.Token != "" {
+		// Public repositories can be read without a token
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return network.Explain(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("GitHub API %s %s: HTTP %d: %s", method, path, resp.StatusCode, apiErrorMessage(data))
+	}
+
+	if out != nil {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
+
+// apiErrorMessage pulls the human-readable message out of a GitHub error response
+func apiErrorMessage(data []byte) string {
+	var apiErr struct {
+		Message string `json:"message"`
+		Errors  []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &apiErr); err != nil || apiErr.Message == "" {
+		return strings.TrimSpace(string(data))
+	}
+
+	message := apiErr.Message
+	for _, e := range apiErr.Errors {
+		if e.Message != "" {
+			message += ": " + e.Message
+		}
+	}
+	return message
+}
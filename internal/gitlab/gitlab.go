@@ -0,0 +1,169 @@
+package gitlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/network"
+)
+
+// Client is a minimal GitLab REST API (v4) client
+type Client struct {
+	BaseURL   string // e.g. https://gitlab.com or https://gitlab.example.com
+	Token     string
+	UserAgent string
+	HTTP      *http.Client
+}
+
+// MergeRequest describes a merge request to create
+type MergeRequest struct {
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	ReviewerIDs  []int  `json:"reviewer_ids,omitempty"`
+}
+
+// CreatedMergeRequest is the subset of the API response we use
+type CreatedMergeRequest struct {
+	IID    int    `json:"iid"`
+	WebURL string `json:"web_url"`
+}
+
+// NewClient returns a client for the GitLab instance at baseURL
+func NewClient(baseURL string, token string, userAgent string) *Client {
+	return &Client{
+		BaseURL:   strings.TrimRight(baseURL, "/"),
+		Token:     token,
+		UserAgent: userAgent,
+		HTTP:      network.Client(),
+	}
+}
+
+// ResolveToken returns the configured token, or falls back to the
+// GITLAB_TOKEN environment variable
+func ResolveToken(configured string) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+	if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+		return token, nil
+	}
+	return "", fmt.Errorf("no GitLab token found (set gitlab_token in config or GITLAB_TOKEN)")
+}
+
+// CreateMergeRequest opens a merge request in the project with the given
+// path (e.g. "group/subgroup/project"). Draft merge requests are marked
+// with the "Draft:" title prefix.
+func (c *Client) CreateMergeRequest(projectPath string, mr MergeRequest, draft bool) (*CreatedMergeRequest, error) {
+	if draft && !strings.HasPrefix(mr.Title, "Draft:") {
+		mr.Title = "Draft: " + mr.Title
+	}
+
+	var created CreatedMergeRequest
+	path := fmt.Sprintf("/projects/%s/merge_requests", url.PathEscape(projectPath))
+	if err := c.do("POST", path, mr, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// FindMergeRequest returns the open merge request of the project from
+// source into target, or nil when there is none
+func (c *Client) FindMergeRequest(projectPath, source, target string) (*CreatedMergeRequest, error) {
+	var found []CreatedMergeRequest
+	path := fmt.Sprintf("/projects/%s/merge_requests?state=opened&source_branch=%s&target_branch=%s",
+		url.PathEscape(projectPath), url.QueryEscape(source), url.QueryEscape(target))
+	if err := c.do("GET", path, nil, &found); err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+	return &found[0], nil
+}
+
+// UserIDs looks up the numeric IDs of the given usernames
+func (c *Client) UserIDs(usernames []string) ([]int, error) {
+	var ids []int
+	for _, username := range usernames {
+		var users []struct {
+			ID int `json:"id"`
+		}
+		path := "/users?username=" + url.QueryEscape(username)
+		if err := c.do("GET", path, nil, &users); err != nil {
+			return nil, err
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("GitLab user %q not found", username)
+		}
+		ids = append(ids, users[0].ID)
+	}
+	return ids, nil
+}
+
+// do sends a JSON request to the API and decodes the JSON response into out
+func (c *Client) do(method, path string, in interface{}, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.BaseURL+"/api/v4"+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.Token)
+	req.Header.Set("User-Agent", c.UserAgent)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return network.Explain(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("GitLab API %s %s: HTTP %d: %s", method, path, resp.StatusCode, apiErrorMessage(data))
+	}
+
+	if out != nil {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
+
+// apiErrorMessage pulls the human-readable message out of a GitLab error response
+func apiErrorMessage(data []byte) string {
+	var apiErr struct {
+		Message interface{} `json:"message"`
+		Error   string      `json:"error"`
+	}
+	if err := json.Unmarshal(data, &apiErr); err != nil {
+		return strings.TrimSpace(string(data))
+	}
+	if apiErr.Message != nil {
+		return fmt.Sprint(apiErr.Message)
+	}
+	if apiErr.Error != "" {
+		return apiErr.Error
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,109 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+)
+
+// FileName is the history file in the config dir, one JSON entry per line
+const FileName = "history.jsonl"
+
+// UsageFileName logs the token usage of every claude call, one JSON entry per line
+const UsageFileName = "usage.jsonl"
+
+// Entry records one cc run: what Claude saw and said, and what was committed
+type Entry struct {
+	Time      time.Time        `json:"time"`
+	Command   string           `json:"command"`
+	Repo      string           `json:"repo"`
+	Branch    string           `json:"branch,omitempty"`
+	Files     []string         `json:"files"`
+	Mode      string           `json:"mode,omitempty"`
+	Model     string           `json:"model"`
+	Findings  []claude.Finding `json:"findings"`
+	Message   string           `json:"message,omitempty"`
+	CommitSHA string           `json:"commit_sha,omitempty"`
+	Pushed    bool             `json:"pushed"`
+	DryRun    bool             `json:"dry_run,omitempty"`
+	Usage     *claude.Usage    `json:"usage,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	ExitCode  int              `json:"exit_code"`
+}
+
+// UsageEntry is the usage of one claude call
+type UsageEntry struct {
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	Model   string    `json:"model"`
+	claude.Usage
+}
+
+// Append adds entry to the history file at path
+func Append(path string, entry Entry) error {
+	return appendLine(path, entry)
+}
+
+// AppendUsage adds entry to the usage log at path
+func AppendUsage(path string, entry UsageEntry) error {
+	return appendLine(path, entry)
+}
+
+// appendLine writes v as one JSON line at the end of the file at path
+func appendLine(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Load reads every entry from the history file at path, oldest first. A
+// missing file is an empty history; lines that don't parse are skipped.
+func Load(path string) ([]Entry, error) {
+	return loadLines[Entry](path)
+}
+
+// LoadUsage reads every entry from the usage log at path, oldest first
+func LoadUsage(path string) ([]UsageEntry, error) {
+	return loadLines[UsageEntry](path)
+}
+
+// loadLines decodes a JSON-lines file, skipping lines that don't parse
+func loadLines[T any](path string) ([]T, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []T
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry T
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,83 @@
+package i18n
+
+// es is the Spanish catalog
+var es = map[string]string{
+	// Common
+	"❌ Error: %v\n":                                 "❌ Error: %v\n",
+	"❌ Error %v\n":                                  "❌ Error: %v\n",
+	"\n❓ %s (y/n): ":                                "\n❓ %s (y/n): ",
+	"\n❓ %s (y/n): y (--yes)\n":                     "\n❓ %s (y/n): y (--yes)\n",
+	"Run 'git init' here?":                          "¿Ejecutar 'git init' aquí?",
+	"✅ Initialized an empty git repository in %s\n": "✅ Repositorio git vacío creado en %s\n",
+	"🌿 Switched to new branch %s\n":                 "🌿 Cambiado a la nueva rama %s\n",
+
+	// Commit
+	"🔍 Checking for changes...":                                                        "🔍 Buscando cambios...",
+	"✅ No changes to commit.\n":                                                        "✅ No hay cambios para confirmar.\n",
+	"🤖 Claude is reviewing your changes":                                               "🤖 Claude está revisando tus cambios",
+	"📦 Leaving the content of %d Git LFS file(s) out of the review: %s\n":              "📦 Se omite de la revisión el contenido de %d archivo(s) de Git LFS: %s\n",
+	"🗂️  %d binary file(s) changed: %s\n":                                              "🗂️  %d archivo(s) binario(s) modificado(s): %s\n",
+	"\n🔎 Claude's findings (%d):\n":                                                    "\n🔎 Hallazgos de Claude (%d):\n",
+	"\n⚠️  %d finding(s) at or above %s severity would block this commit.\n":           "\n⚠️  %d hallazgo(s) de gravedad %s o superior bloquearían este commit.\n",
+	"\n⚠️  %d finding(s) at or above %s severity block this commit.\n":                 "\n⚠️  %d hallazgo(s) de gravedad %s o superior bloquean este commit.\n",
+	"Please fix these issues before committing. Use --force or -f to commit anyway.\n": "Corrige estos problemas antes de confirmar. Usa --force o -f para confirmar de todos modos.\n",
+	"\n⚠️  Force mode enabled. Proceeding with commit despite blocking findings.":      "\n⚠️  Modo forzado activado. Se confirma a pesar de los hallazgos bloqueantes.",
+	"\n📝 Commit message (rule-based, not AI): %s\n":                                    "\n📝 Mensaje de commit (por reglas, no por IA): %s\n",
+	"\n📝 Commit message: %s\n":                                                         "\n📝 Mensaje de commit: %s\n",
+	"\n📝 Commit message:\n%s\n":                                                        "\n📝 Mensaje de commit:\n%s\n",
+	"\n📝 Edited commit message:\n%s\n":                                                 "\n📝 Mensaje de commit editado:\n%s\n",
+	"\n📋 Commit message copied to clipboard.\n":                                        "\n📋 Mensaje de commit copiado al portapapeles.\n",
+	"\n🧪 Dry run: nothing was staged, committed, or pushed.\n":                         "\n🧪 Simulación: no se preparó, confirmó ni envió nada.\n",
+	"❌ Aborted: empty commit message. No changes were committed.\n":                    "❌ Cancelado: mensaje de commit vacío. No se confirmó ningún cambio.\n",
+	"❌ Aborted. No changes were committed.\n":                                          "❌ Cancelado. No se confirmó ningún cambio.\n",
+	"🚀 Staging all changes...":                                                         "🚀 Preparando todos los cambios...",
+	"💾 Committing...":                                                                  "💾 Confirmando...",
+	"📤 Pushing...":                                                                     "📤 Enviando...",
+	"\n🌳 Committed on %s in worktree %s\n":                                             "\n🌳 Confirmado en %s en el worktree %s\n",
+	"\n✨ Done! %s\n":                                                                   "\n✨ ¡Listo! %s\n",
+	"Your changes have been reviewed, committed, and pushed.":                          "Tus cambios se revisaron, confirmaron y enviaron.",
+	"Your changes have been reviewed and committed (not pushed).":                      "Tus cambios se revisaron y confirmaron (sin enviar).",
+	"Your changes have been committed and pushed, without an AI review.":               "Tus cambios se confirmaron y enviaron, sin revisión de IA.",
+	"Your changes have been committed (not pushed), without an AI review.":             "Tus cambios se confirmaron (sin enviar), sin revisión de IA.",
+	"\n⚠️  Claude is unavailable: %s\n":                                                "\n⚠️  Claude no está disponible: %s\n",
+	"🧰 Falling back to a rule-based message. These changes were NOT reviewed by AI.\n": "🧰 Se usa un mensaje basado en reglas. Estos cambios NO fueron revisados por IA.\n",
+	"\n⚠️  %d file(s) would need confirmation before staging: %s\n":                    "\n⚠️  %d archivo(s) necesitarían confirmación antes de prepararse: %s\n",
+	"Staging them anyway (--yes).":                                                     "Se preparan de todos modos (--yes).",
+	"❓ Stage and commit them anyway?":                                                  "❓ ¿Prepararlos y confirmarlos de todos modos?",
+
+	// Plan mode
+	"\n❓ Commit and push these changes? accept (--yes)\n":   "\n❓ ¿Confirmar y enviar estos cambios? aceptar (--yes)\n",
+	"\n❓ [a]ccept  [e]dit  [r]egenerate  [m]odel  [q]uit: ": "\n❓ [a]ceptar  [e]ditar  [r]egenerar  [m]odelo  [q] salir: ",
+	"⚠️  Empty message, keeping the previous one.":          "⚠️  Mensaje vacío, se mantiene el anterior.",
+	"💬 Hint for Claude (optional): ":                        "💬 Indicación para Claude (opcional): ",
+	"🤖 Model":                                               "🤖 Modelo",
+	"Please answer a, e, r, m, or q.":                       "Responde a, e, r, m o q.",
+
+	// Cost
+	"\n💸 This review would send ~%s tokens to %s (~%s), above cost_limit (%s).\n": "\n💸 Esta revisión enviaría ~%s tokens a %s (~%s), por encima de cost_limit (%s).\n",
+	"💸 Continuing anyway (--yes)\n":                                               "💸 Se continúa de todos modos (--yes)\n",
+	"❓ [c]ontinue  [q]uit: ":                                                      "❓ [c]ontinuar  [q] salir: ",
+	"❓ [c]ontinue  review a [s]ummary instead  [q]uit: ":                          "❓ [c]ontinuar  revisar un re[s]umen  [q] salir: ",
+	"Please answer c, s, or q.":                                                   "Responde c, s o q.",
+	"💸 Already in summary mode; continuing\n":                                     "💸 Ya está en modo resumen; se continúa\n",
+	"📉 Switched to summary mode: ~%s tokens (~%s)\n":                              "📉 Cambiado a modo resumen: ~%s tokens (~%s)\n",
+
+	// Push
+	"❓ Force-push anyway?": "❓ ¿Forzar el envío de todos modos?",
+	"\n❓ Branch %s has no upstream. Push it to %s and track it?": "\n❓ La rama %s no tiene upstream. ¿Enviarla a %s y seguirla?",
+	"🔗 Setting upstream to %s\n":                                 "🔗 Estableciendo upstream en %s\n",
+	"📤 Pushing %s to %s\n":                                       "📤 Enviando %s a %s\n",
+	"🔄 Fetching %s...\n":                                         "🔄 Obteniendo %s...\n",
+	"🔄 Rebasing onto %s...\n":                                    "🔄 Haciendo rebase sobre %s...\n",
+	"🔄 Will rebase onto %s before pushing (--yes)\n":             "🔄 Se hará rebase sobre %s antes de enviar (--yes)\n",
+
+	// Review
+	"✅ No changes to review.":      "✅ No hay cambios para revisar.",
+	"❌ Error calling Claude: %v\n": "❌ Error al llamar a Claude: %v\n",
+	"\n%s Risk: %s\n":              "\n%s Riesgo: %s\n",
+	"\n📋 Summary: %s\n":            "\n📋 Resumen: %s\n",
+	"\n✅ No issues found.":         "\n✅ No se encontraron problemas.",
+	"\n⚠️  Findings (%d):\n":       "\n⚠️  Hallazgos (%d):\n",
+	"\n💡 Suggestions (%d):\n":      "\n💡 Sugerencias (%d):\n",
+	"\nℹ️  Review only: nothing was staged, committed, or pushed.": "\nℹ️  Solo revisión: no se preparó, confirmó ni envió nada.",
+}
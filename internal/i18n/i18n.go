@@ -0,0 +1,81 @@
+// Package i18n translates the console output of cc. Messages are looked up
+// by their English text (format strings included), so anything without a
+// translation is printed in English as before.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Languages are the supported UI languages
+var Languages = []string{"en", "zh", "ja", "es"}
+
+// catalogs maps a language to its translations, keyed by the English text
+var catalogs = map[string]map[string]string{
+	"zh": zh,
+	"ja": ja,
+	"es": es,
+}
+
+// current is the selected language; English needs no catalog
+var current = "en"
+
+// SetLanguage selects the UI language: one of Languages, "auto" to follow
+// the locale (LC_ALL, LC_MESSAGES, LANG), or "" for English
+func SetLanguage(lang string) error {
+	if err := Validate(lang); err != nil {
+		return err
+	}
+	switch lang {
+	case "":
+		current = "en"
+	case "auto":
+		current = Detect()
+	default:
+		current = lang
+	}
+	return nil
+}
+
+// Validate checks that lang is a setting SetLanguage accepts
+func Validate(lang string) error {
+	if lang == "" || lang == "auto" || slices.Contains(Languages, lang) {
+		return nil
+	}
+	return fmt.Errorf("ui_language: unsupported language %q (use %s, or auto)", lang, strings.Join(Languages, ", "))
+}
+
+// Language returns the selected UI language
+func Language() string {
+	return current
+}
+
+// Detect returns the supported language of the locale, or "en"
+func Detect() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		locale := os.Getenv(name)
+		if locale == "" {
+			continue
+		}
+		// e.g. "zh_CN.UTF-8" or "ja-JP"
+		lang, _, _ := strings.Cut(strings.ToLower(locale), "_")
+		lang, _, _ = strings.Cut(lang, "-")
+		lang, _, _ = strings.Cut(lang, ".")
+		if _, ok := catalogs[lang]; ok {
+			return lang
+		}
+		return "en"
+	}
+	return "en"
+}
+
+// T returns the translation of text in the selected language, or text itself
+func T(text string) string {
+	if translated, ok := catalogs[current][text]; ok {
+		return translated
+	}
+	return text
+}
@@ -0,0 +1,83 @@
+package i18n
+
+// ja is the Japanese catalog
+var ja = map[string]string{
+	// Common
+	"❌ Error: %v\n":                                 "❌ エラー: %v\n",
+	"❌ Error %v\n":                                  "❌ エラー: %v\n",
+	"\n❓ %s (y/n): ":                                "\n❓ %s (y/n): ",
+	"\n❓ %s (y/n): y (--yes)\n":                     "\n❓ %s (y/n): y (--yes)\n",
+	"Run 'git init' here?":                          "ここで 'git init' を実行しますか？",
+	"✅ Initialized an empty git repository in %s\n": "✅ %s に空の git リポジトリを作成しました\n",
+	"🌿 Switched to new branch %s\n":                 "🌿 新しいブランチ %s に切り替えました\n",
+
+	// Commit
+	"🔍 Checking for changes...":                                                        "🔍 変更を確認しています...",
+	"✅ No changes to commit.\n":                                                        "✅ コミットする変更はありません。\n",
+	"🤖 Claude is reviewing your changes":                                               "🤖 Claude が変更をレビューしています",
+	"📦 Leaving the content of %d Git LFS file(s) out of the review: %s\n":              "📦 %d 個の Git LFS ファイルの内容はレビューから除外します: %s\n",
+	"🗂️  %d binary file(s) changed: %s\n":                                              "🗂️  %d 個のバイナリファイルが変更されました: %s\n",
+	"\n🔎 Claude's findings (%d):\n":                                                    "\n🔎 Claude の指摘 (%d):\n",
+	"\n⚠️  %d finding(s) at or above %s severity would block this commit.\n":           "\n⚠️  重大度 %[2]s 以上の指摘が %[1]d 件あるため、このコミットはブロックされます。\n",
+	"\n⚠️  %d finding(s) at or above %s severity block this commit.\n":                 "\n⚠️  重大度 %[2]s 以上の指摘が %[1]d 件あるため、このコミットをブロックしました。\n",
+	"Please fix these issues before committing. Use --force or -f to commit anyway.\n": "コミットする前にこれらの問題を修正してください。それでもコミットするには --force または -f を使ってください。\n",
+	"\n⚠️  Force mode enabled. Proceeding with commit despite blocking findings.":      "\n⚠️  強制モードです。ブロック対象の指摘がありますが、コミットを続けます。",
+	"\n📝 Commit message (rule-based, not AI): %s\n":                                    "\n📝 コミットメッセージ（ルールベース、AI ではありません）: %s\n",
+	"\n📝 Commit message: %s\n":                                                         "\n📝 コミットメッセージ: %s\n",
+	"\n📝 Commit message:\n%s\n":                                                        "\n📝 コミットメッセージ:\n%s\n",
+	"\n📝 Edited commit message:\n%s\n":                                                 "\n📝 編集後のコミットメッセージ:\n%s\n",
+	"\n📋 Commit message copied to clipboard.\n":                                        "\n📋 コミットメッセージをクリップボードにコピーしました。\n",
+	"\n🧪 Dry run: nothing was staged, committed, or pushed.\n":                         "\n🧪 ドライラン: ステージ、コミット、プッシュは行っていません。\n",
+	"❌ Aborted: empty commit message. No changes were committed.\n":                    "❌ 中止しました: コミットメッセージが空です。何もコミットしていません。\n",
+	"❌ Aborted. No changes were committed.\n":                                          "❌ 中止しました。何もコミットしていません。\n",
+	"🚀 Staging all changes...":                                                         "🚀 すべての変更をステージしています...",
+	"💾 Committing...":                                                                  "💾 コミットしています...",
+	"📤 Pushing...":                                                                     "📤 プッシュしています...",
+	"\n🌳 Committed on %s in worktree %s\n":                                             "\n🌳 ワークツリー %[2]s の %[1]s にコミットしました\n",
+	"\n✨ Done! %s\n":                                                                   "\n✨ 完了！%s\n",
+	"Your changes have been reviewed, committed, and pushed.":                          "変更をレビューし、コミットしてプッシュしました。",
+	"Your changes have been reviewed and committed (not pushed).":                      "変更をレビューしてコミットしました（プッシュはしていません）。",
+	"Your changes have been committed and pushed, without an AI review.":               "変更をコミットしてプッシュしました（AI レビューなし）。",
+	"Your changes have been committed (not pushed), without an AI review.":             "変更をコミットしました（プッシュなし、AI レビューなし）。",
+	"\n⚠️  Claude is unavailable: %s\n":                                                "\n⚠️  Claude を利用できません: %s\n",
+	"🧰 Falling back to a rule-based message. These changes were NOT reviewed by AI.\n": "🧰 ルールベースのメッセージを使います。これらの変更は AI のレビューを受けていません。\n",
+	"\n⚠️  %d file(s) would need confirmation before staging: %s\n":                    "\n⚠️  %d 個のファイルはステージ前に確認が必要です: %s\n",
+	"Staging them anyway (--yes).":                                                     "そのままステージします（--yes）。",
+	"❓ Stage and commit them anyway?":                                                  "❓ それでもステージしてコミットしますか？",
+
+	// Plan mode
+	"\n❓ Commit and push these changes? accept (--yes)\n":   "\n❓ この変更をコミットしてプッシュしますか？ 承認（--yes）\n",
+	"\n❓ [a]ccept  [e]dit  [r]egenerate  [m]odel  [q]uit: ": "\n❓ [a]承認  [e]編集  [r]再生成  [m]モデル  [q]終了: ",
+	"⚠️  Empty message, keeping the previous one.":          "⚠️  メッセージが空なので、前のメッセージのままにします。",
+	"💬 Hint for Claude (optional): ":                        "💬 Claude へのヒント（任意）: ",
+	"🤖 Model":                                               "🤖 モデル",
+	"Please answer a, e, r, m, or q.":                       "a、e、r、m、q のいずれかで答えてください。",
+
+	// Cost
+	"\n💸 This review would send ~%s tokens to %s (~%s), above cost_limit (%s).\n": "\n💸 このレビューでは %[2]s に約 %[1]s トークン（約 %[3]s）を送信し、cost_limit（%[4]s）を超えます。\n",
+	"💸 Continuing anyway (--yes)\n":                                               "💸 そのまま続けます（--yes）\n",
+	"❓ [c]ontinue  [q]uit: ":                                                      "❓ [c]続ける  [q]終了: ",
+	"❓ [c]ontinue  review a [s]ummary instead  [q]uit: ":                          "❓ [c]続ける  [s]代わりに要約をレビュー  [q]終了: ",
+	"Please answer c, s, or q.":                                                   "c、s、q のいずれかで答えてください。",
+	"💸 Already in summary mode; continuing\n":                                     "💸 すでに要約モードです。続けます\n",
+	"📉 Switched to summary mode: ~%s tokens (~%s)\n":                              "📉 要約モードに切り替えました: 約 %s トークン（約 %s）\n",
+
+	// Push
+	"❓ Force-push anyway?": "❓ それでも強制プッシュしますか？",
+	"\n❓ Branch %s has no upstream. Push it to %s and track it?": "\n❓ ブランチ %s にはアップストリームがありません。%s にプッシュして追跡しますか？",
+	"🔗 Setting upstream to %s\n":                                 "🔗 アップストリームを %s に設定します\n",
+	"📤 Pushing %s to %s\n":                                       "📤 %s を %s にプッシュしています\n",
+	"🔄 Fetching %s...\n":                                         "🔄 %s をフェッチしています...\n",
+	"🔄 Rebasing onto %s...\n":                                    "🔄 %s にリベースしています...\n",
+	"🔄 Will rebase onto %s before pushing (--yes)\n":             "🔄 プッシュ前に %s にリベースします（--yes）\n",
+
+	// Review
+	"✅ No changes to review.":      "✅ レビューする変更はありません。",
+	"❌ Error calling Claude: %v\n": "❌ Claude の呼び出しでエラー: %v\n",
+	"\n%s Risk: %s\n":              "\n%s リスク: %s\n",
+	"\n📋 Summary: %s\n":            "\n📋 概要: %s\n",
+	"\n✅ No issues found.":         "\n✅ 問題は見つかりませんでした。",
+	"\n⚠️  Findings (%d):\n":       "\n⚠️  指摘 (%d):\n",
+	"\n💡 Suggestions (%d):\n":      "\n💡 提案 (%d):\n",
+	"\nℹ️  Review only: nothing was staged, committed, or pushed.": "\nℹ️  レビューのみ: ステージ、コミット、プッシュは行っていません。",
+}
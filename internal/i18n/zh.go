@@ -0,0 +1,83 @@
+package i18n
+
+// zh is the Simplified Chinese catalog
+var zh = map[string]string{
+	// Common
+	"❌ Error: %v\n":                                 "❌ 错误：%v\n",
+	"❌ Error %v\n":                                  "❌ 错误：%v\n",
+	"\n❓ %s (y/n): ":                                "\n❓ %s (y/n)：",
+	"\n❓ %s (y/n): y (--yes)\n":                     "\n❓ %s (y/n)：y (--yes)\n",
+	"Run 'git init' here?":                          "要在这里运行 'git init' 吗？",
+	"✅ Initialized an empty git repository in %s\n": "✅ 已在 %s 初始化空的 git 仓库\n",
+	"🌿 Switched to new branch %s\n":                 "🌿 已切换到新分支 %s\n",
+
+	// Commit
+	"🔍 Checking for changes...":                                                        "🔍 正在检查改动...",
+	"✅ No changes to commit.\n":                                                        "✅ 没有需要提交的改动。\n",
+	"🤖 Claude is reviewing your changes":                                               "🤖 Claude 正在审查你的改动",
+	"📦 Leaving the content of %d Git LFS file(s) out of the review: %s\n":              "📦 审查时略过 %d 个 Git LFS 文件的内容：%s\n",
+	"🗂️  %d binary file(s) changed: %s\n":                                              "🗂️  %d 个二进制文件有改动：%s\n",
+	"\n🔎 Claude's findings (%d):\n":                                                    "\n🔎 Claude 发现的问题（%d）：\n",
+	"\n⚠️  %d finding(s) at or above %s severity would block this commit.\n":           "\n⚠️  有 %d 个 %s 及以上级别的问题，将会阻止本次提交。\n",
+	"\n⚠️  %d finding(s) at or above %s severity block this commit.\n":                 "\n⚠️  有 %d 个 %s 及以上级别的问题，已阻止本次提交。\n",
+	"Please fix these issues before committing. Use --force or -f to commit anyway.\n": "请先修复这些问题再提交。如需强制提交，请使用 --force 或 -f。\n",
+	"\n⚠️  Force mode enabled. Proceeding with commit despite blocking findings.":      "\n⚠️  已启用强制模式。尽管存在阻止性问题，仍继续提交。",
+	"\n📝 Commit message (rule-based, not AI): %s\n":                                    "\n📝 提交信息（基于规则，非 AI 生成）：%s\n",
+	"\n📝 Commit message: %s\n":                                                         "\n📝 提交信息：%s\n",
+	"\n📝 Commit message:\n%s\n":                                                        "\n📝 提交信息：\n%s\n",
+	"\n📝 Edited commit message:\n%s\n":                                                 "\n📝 编辑后的提交信息：\n%s\n",
+	"\n📋 Commit message copied to clipboard.\n":                                        "\n📋 提交信息已复制到剪贴板。\n",
+	"\n🧪 Dry run: nothing was staged, committed, or pushed.\n":                         "\n🧪 试运行：没有暂存、提交或推送任何内容。\n",
+	"❌ Aborted: empty commit message. No changes were committed.\n":                    "❌ 已中止：提交信息为空。没有提交任何改动。\n",
+	"❌ Aborted. No changes were committed.\n":                                          "❌ 已中止。没有提交任何改动。\n",
+	"🚀 Staging all changes...":                                                         "🚀 正在暂存所有改动...",
+	"💾 Committing...":                                                                  "💾 正在提交...",
+	"📤 Pushing...":                                                                     "📤 正在推送...",
+	"\n🌳 Committed on %s in worktree %s\n":                                             "\n🌳 已在工作树 %[2]s 的 %[1]s 分支上提交\n",
+	"\n✨ Done! %s\n":                                                                   "\n✨ 完成！%s\n",
+	"Your changes have been reviewed, committed, and pushed.":                          "你的改动已审查、提交并推送。",
+	"Your changes have been reviewed and committed (not pushed).":                      "你的改动已审查并提交（未推送）。",
+	"Your changes have been committed and pushed, without an AI review.":               "你的改动已提交并推送，未经 AI 审查。",
+	"Your changes have been committed (not pushed), without an AI review.":             "你的改动已提交（未推送），未经 AI 审查。",
+	"\n⚠️  Claude is unavailable: %s\n":                                                "\n⚠️  Claude 不可用：%s\n",
+	"🧰 Falling back to a rule-based message. These changes were NOT reviewed by AI.\n": "🧰 改用基于规则生成的提交信息。这些改动未经 AI 审查。\n",
+	"\n⚠️  %d file(s) would need confirmation before staging: %s\n":                    "\n⚠️  有 %d 个文件需要确认后才能暂存：%s\n",
+	"Staging them anyway (--yes).":                                                     "仍然暂存它们（--yes）。",
+	"❓ Stage and commit them anyway?":                                                  "❓ 仍然暂存并提交它们吗？",
+
+	// Plan mode
+	"\n❓ Commit and push these changes? accept (--yes)\n":   "\n❓ 提交并推送这些改动吗？接受（--yes）\n",
+	"\n❓ [a]ccept  [e]dit  [r]egenerate  [m]odel  [q]uit: ": "\n❓ [a]接受  [e]编辑  [r]重新生成  [m]模型  [q]退出：",
+	"⚠️  Empty message, keeping the previous one.":          "⚠️  信息为空，保留之前的信息。",
+	"💬 Hint for Claude (optional): ":                        "💬 给 Claude 的提示（可选）：",
+	"🤖 Model":                                               "🤖 模型",
+	"Please answer a, e, r, m, or q.":                       "请输入 a、e、r、m 或 q。",
+
+	// Cost
+	"\n💸 This review would send ~%s tokens to %s (~%s), above cost_limit (%s).\n": "\n💸 本次审查将向 %[2]s 发送约 %[1]s 个 token（约 %[3]s），超过了 cost_limit（%[4]s）。\n",
+	"💸 Continuing anyway (--yes)\n":                                               "💸 仍然继续（--yes）\n",
+	"❓ [c]ontinue  [q]uit: ":                                                      "❓ [c]继续  [q]退出：",
+	"❓ [c]ontinue  review a [s]ummary instead  [q]uit: ":                          "❓ [c]继续  [s]改为审查摘要  [q]退出：",
+	"Please answer c, s, or q.":                                                   "请输入 c、s 或 q。",
+	"💸 Already in summary mode; continuing\n":                                     "💸 已处于摘要模式；继续\n",
+	"📉 Switched to summary mode: ~%s tokens (~%s)\n":                              "📉 已切换到摘要模式：约 %s 个 token（约 %s）\n",
+
+	// Push
+	"❓ Force-push anyway?": "❓ 仍然强制推送吗？",
+	"\n❓ Branch %s has no upstream. Push it to %s and track it?": "\n❓ 分支 %s 没有上游分支。要推送到 %s 并跟踪它吗？",
+	"🔗 Setting upstream to %s\n":                                 "🔗 将上游分支设置为 %s\n",
+	"📤 Pushing %s to %s\n":                                       "📤 正在将 %s 推送到 %s\n",
+	"🔄 Fetching %s...\n":                                         "🔄 正在获取 %s...\n",
+	"🔄 Rebasing onto %s...\n":                                    "🔄 正在变基到 %s...\n",
+	"🔄 Will rebase onto %s before pushing (--yes)\n":             "🔄 推送前将变基到 %s（--yes）\n",
+
+	// Review
+	"✅ No changes to review.":      "✅ 没有需要审查的改动。",
+	"❌ Error calling Claude: %v\n": "❌ 调用 Claude 出错：%v\n",
+	"\n%s Risk: %s\n":              "\n%s 风险：%s\n",
+	"\n📋 Summary: %s\n":            "\n📋 摘要：%s\n",
+	"\n✅ No issues found.":         "\n✅ 未发现问题。",
+	"\n⚠️  Findings (%d):\n":       "\n⚠️  发现的问题（%d）：\n",
+	"\n💡 Suggestions (%d):\n":      "\n💡 建议（%d）：\n",
+	"\nℹ️  Review only: nothing was staged, committed, or pushed.": "\nℹ️  仅审查：没有暂存、提交或推送任何内容。",
+}
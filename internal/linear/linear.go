@@ -0,0 +1,106 @@
+// Package linear is a minimal client for the Linear GraphQL API
+package linear
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/network"
+)
+
+const apiURL = "https://api.linear.app/graphql"
+
+// Client is a minimal Linear API client
+type Client struct {
+	Token     string
+	UserAgent string
+	HTTP      *http.Client
+}
+
+// Issue is the subset of an issue we use
+type Issue struct {
+	Identifier  string `json:"identifier"` // e.g. ENG-123
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+}
+
+// NewClient returns a client authenticated with token
+func NewClient(token string, userAgent string) *Client {
+	return &Client{Token: token, UserAgent: userAgent, HTTP: network.Client()}
+}
+
+// ResolveToken returns the configured token, or falls back to the
+// LINEAR_API_KEY environment variable
+func ResolveToken(configured string) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+	if token := os.Getenv("LINEAR_API_KEY"); token != "" {
+		return token, nil
+	}
+	return "", fmt.Errorf("no Linear API key found (set issues.linear_token in config or LINEAR_API_KEY)")
+}
+
+// GetIssue fetches an issue by its identifier, e.g. "ENG-123"
+func (c *Client) GetIssue(identifier string) (*Issue, error) {
+	var out struct {
+		Issue *Issue `json:"issue"`
+	}
+	query := `query($id: String!) { issue(id: $id) { identifier title description url } }`
+	if err := c.query(query, map[string]any{"id": identifier}, &out); err != nil {
+		return nil, err
+	}
+	if out.Issue == nil {
+		return nil, fmt.Errorf("Linear issue %s not found", identifier)
+	}
+	return out.Issue, nil
+}
+
+// query sends a GraphQL query and decodes its data into out
+func (c *Client) query(query string, variables map[string]any, out any) error {
+	data, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", apiURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	// Personal API keys go without the Bearer prefix OAuth tokens use
+	req.Header.Set("Authorization", c.Token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return network.Explain(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("Linear API: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("Linear API: %s", result.Errors[0].Message)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Linear API: HTTP %d", resp.StatusCode)
+	}
+	return json.Unmarshal(result.Data, out)
+}
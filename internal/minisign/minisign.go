@@ -0,0 +1,86 @@
+// Package minisign verifies minisign signatures (https://jedisct1.github.io/minisign/),
+// the Ed25519 signatures release.sh puts on checksums.txt
+package minisign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Signature algorithms: Ed25519 over the message itself (legacy), or over
+// its BLAKE2b-512 hash (the default since minisign 0.10)
+const (
+	algLegacy   = "Ed"
+	algPrehash  = "ED"
+	keyIDLength = 8
+)
+
+// PublicKey is a minisign public key
+type PublicKey struct {
+	KeyID [keyIDLength]byte
+	Key   ed25519.PublicKey
+}
+
+// ParsePublicKey reads a public key as printed by `minisign -G`: the base64
+// line, optionally preceded by its "untrusted comment:" line
+func ParsePublicKey(text string) (*PublicKey, error) {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	encoded := strings.TrimSpace(lines[len(lines)-1])
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data) != 2+keyIDLength+ed25519.PublicKeySize || string(data[:2]) != algLegacy {
+		return nil, errors.New("not a minisign public key")
+	}
+	key := &PublicKey{Key: ed25519.PublicKey(data[2+keyIDLength:])}
+	copy(key.KeyID[:], data[2:2+keyIDLength])
+	return key, nil
+}
+
+// Verify checks that sigFile (the contents of a .minisig file) is a valid
+// signature of message by key, including the signature over its trusted
+// comment, which it returns
+func Verify(key *PublicKey, message []byte, sigFile []byte) (string, error) {
+	lines := strings.Split(strings.ReplaceAll(string(sigFile), "\r\n", "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[0], "untrusted comment:") {
+		return "", errors.New("malformed signature file")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sig) != 2+keyIDLength+ed25519.SignatureSize {
+		return "", errors.New("malformed signature")
+	}
+	alg, keyID, signature := string(sig[:2]), sig[2:2+keyIDLength], sig[2+keyIDLength:]
+	if !bytes.Equal(keyID, key.KeyID[:]) {
+		return "", fmt.Errorf("signed with key %X, expected %X", keyID, key.KeyID)
+	}
+
+	signed := message
+	switch alg {
+	case algLegacy:
+	case algPrehash:
+		hash := blake2b.Sum512(message)
+		signed = hash[:]
+	default:
+		return "", fmt.Errorf("unsupported signature algorithm %q", alg)
+	}
+	if !ed25519.Verify(key.Key, signed, signature) {
+		return "", errors.New("signature doesn't match")
+	}
+
+	comment, ok := strings.CutPrefix(lines[2], "trusted comment: ")
+	if !ok {
+		return "", errors.New("malformed trusted comment")
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return "", errors.New("malformed trusted comment signature")
+	}
+	if !ed25519.Verify(key.Key, append(append([]byte{}, signature...), comment...), globalSig) {
+		return "", errors.New("trusted comment signature doesn't match")
+	}
+	return comment, nil
+}
@@ -0,0 +1,112 @@
+// Package network applies the proxy and CA settings to the HTTP clients of
+// cc and to the claude CLI it runs
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// caPool holds the system roots plus the configured CA bundle; nil means
+// the system roots alone
+var caPool *x509.CertPool
+
+// Configure applies the settings. The proxy is exported as HTTPS_PROXY (and
+// noProxy as NO_PROXY), which the HTTP clients, git, and the claude CLI all
+// read; empty values keep the environment's. The CA bundle, a PEM file, is
+// trusted on top of the system roots, and passed to the claude CLI as
+// NODE_EXTRA_CA_CERTS.
+func Configure(proxy, noProxy, caBundle string) error {
+	if proxy != "" {
+		if _, err := url.Parse(proxy); err != nil {
+			return fmt.Errorf("network.proxy: %w", err)
+		}
+		for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+			os.Setenv(name, proxy)
+		}
+	}
+	if noProxy != "" {
+		os.Setenv("NO_PROXY", noProxy)
+		os.Setenv("no_proxy", noProxy)
+	}
+	if caBundle == "" {
+		return nil
+	}
+
+	pool, err := LoadCABundle(caBundle)
+	if err != nil {
+		return err
+	}
+	caPool = pool
+	os.Setenv("NODE_EXTRA_CA_CERTS", caBundle)
+	return nil
+}
+
+// LoadCABundle returns the system roots plus the certificates in the PEM
+// file at path
+func LoadCABundle(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("network.ca_bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("network.ca_bundle: no PEM certificates in %s", path)
+	}
+	return pool, nil
+}
+
+// Client returns an HTTP client that uses the proxy from the environment
+// and trusts the configured CA bundle
+func Client() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if caPool != nil {
+		transport.TLSClientConfig = &tls.Config{RootCAs: caPool}
+	}
+	return &http.Client{Transport: transport}
+}
+
+// Explain adds a hint to errors typical of corporate networks: TLS
+// interception by an unknown CA, and proxies that can't be reached or
+// refuse the connection
+func Explain(err error) error {
+	if err == nil {
+		return nil
+	}
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalid x509.CertificateInvalidError
+	var hostname x509.HostnameError
+	switch {
+	case errors.As(err, &unknownAuthority), errors.As(err, &invalid):
+		return fmt.Errorf("%w (if your network inspects TLS traffic, point network.ca_bundle at your company's CA certificate: cc config set network.ca_bundle /path/to/ca.pem)", err)
+	case errors.As(err, &hostname):
+		return fmt.Errorf("%w (a proxy may be intercepting the connection; check network.proxy or HTTPS_PROXY)", err)
+	case strings.Contains(err.Error(), "proxyconnect"):
+		return fmt.Errorf("%w (the proxy couldn't be reached; check network.proxy or HTTPS_PROXY)", err)
+	}
+	return err
+}
+
+// ExplainOutput returns a hint for the TLS and proxy errors the claude CLI
+// (Node.js) prints, or "" if output has none
+func ExplainOutput(output string) string {
+	switch {
+	case strings.Contains(output, "SELF_SIGNED_CERT_IN_CHAIN"), strings.Contains(output, "UNABLE_TO_GET_ISSUER_CERT"),
+		strings.Contains(output, "unable to get local issuer certificate"), strings.Contains(output, "self-signed certificate"):
+		return "if your network inspects TLS traffic, point network.ca_bundle at your company's CA certificate: cc config set network.ca_bundle /path/to/ca.pem"
+	case strings.Contains(output, "ECONNREFUSED"), strings.Contains(output, "ENOTFOUND"), strings.Contains(output, "ETIMEDOUT"),
+		strings.Contains(output, "Connection error"):
+		return "check your connection, and network.proxy or HTTPS_PROXY if you're behind a proxy"
+	}
+	return ""
+}
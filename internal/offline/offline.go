@@ -0,0 +1,184 @@
+package offline
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// maxSubjectLength keeps generated subjects within the usual limit
+const maxSubjectLength = 72
+
+// Message writes a rule-based Conventional Commits subject from the file
+// changes alone, for when no model can be reached, e.g.
+// "feat(parser): add lexer.go and tokens.go" or "refactor: rename a.go to b.go"
+func Message(changes []git.FileChange) string {
+	if len(changes) == 0 {
+		return "chore: update files"
+	}
+
+	commitType := commitType(changes)
+	scope := scope(changes)
+	if scope != "" {
+		scope = "(" + scope + ")"
+	}
+	subject := fmt.Sprintf("%s%s: %s", commitType, scope, describe(changes, commitType))
+	if runes := []rune(subject); len(runes) > maxSubjectLength {
+		subject = string(runes[:maxSubjectLength-1]) + "…"
+	}
+	return subject
+}
+
+// kind classifies a path as "test", "docs", "ci", "build", or "" for code
+func kind(file string) string {
+	base := path.Base(file)
+	lower := strings.ToLower(file)
+	switch {
+	case strings.HasSuffix(base, "_test.go"), strings.Contains(base, ".test."), strings.Contains(base, ".spec."),
+		strings.HasPrefix(base, "test_"), strings.HasPrefix(lower, "test/"), strings.HasPrefix(lower, "tests/"),
+		strings.Contains(lower, "/test/"), strings.Contains(lower, "/tests/"), strings.Contains(lower, "__tests__/"):
+		return "test"
+	case strings.HasSuffix(lower, ".md"), strings.HasSuffix(lower, ".rst"), strings.HasSuffix(lower, ".txt") && !strings.Contains(lower, "requirements"),
+		strings.HasPrefix(lower, "docs/"), strings.HasPrefix(lower, "doc/"), strings.HasPrefix(base, "LICENSE"):
+		return "docs"
+	case strings.HasPrefix(lower, ".github/workflows/"), strings.HasPrefix(lower, ".circleci/"),
+		base == ".gitlab-ci.yml", base == "Jenkinsfile", base == ".travis.yml":
+		return "ci"
+	case base == "go.mod", base == "go.sum", base == "package.json", base == "package-lock.json", base == "yarn.lock",
+		base == "pnpm-lock.yaml", base == "Cargo.toml", base == "Cargo.lock", base == "Makefile", base == "Dockerfile",
+		base == "requirements.txt", base == "pyproject.toml", base == "Gemfile", base == "Gemfile.lock":
+		return "build"
+	}
+	return ""
+}
+
+// commitType picks the type from what kind of files changed and how
+func commitType(changes []git.FileChange) string {
+	kinds := map[string]bool{}
+	added, removed, renamed := 0, 0, 0
+	for _, c := range changes {
+		kinds[kind(c.Path)] = true
+		switch c.Status {
+		case 'A':
+			added++
+		case 'D':
+			removed++
+		case 'R':
+			renamed++
+		}
+	}
+
+	if len(kinds) == 1 {
+		for k := range kinds {
+			switch k {
+			case "test", "docs", "ci", "build":
+				return k
+			}
+		}
+	}
+	// Code plus its tests reads as code
+	delete(kinds, "test")
+	switch {
+	case renamed == len(changes) || removed == len(changes):
+		return "refactor"
+	case added > 0 && kinds[""]:
+		return "feat"
+	}
+	return "chore"
+}
+
+// scope returns the directory all changed files share, by its last element
+func scope(changes []git.FileChange) string {
+	common := path.Dir(changes[0].Path)
+	for _, c := range changes[1:] {
+		for common != "." && !strings.HasPrefix(c.Path, common+"/") {
+			common = path.Dir(common)
+		}
+	}
+	if common == "." {
+		return ""
+	}
+	return path.Base(common)
+}
+
+// verbs describe each status
+var verbs = map[byte]string{'A': "add", 'D': "remove", 'R': "rename", 'M': "update", 'T': "update", 'C': "copy"}
+
+// describe summarizes the changes in a few words
+func describe(changes []git.FileChange, commitType string) string {
+	if len(changes) == 1 {
+		c := changes[0]
+		if c.Status == 'R' {
+			return fmt.Sprintf("rename %s to %s", path.Base(c.OldPath), path.Base(c.Path))
+		}
+		return verbOf(c) + " " + path.Base(c.Path)
+	}
+
+	// Tests alongside code: name what they test
+	if commitType != "test" {
+		tests := 0
+		for _, c := range changes {
+			if kind(c.Path) == "test" {
+				tests++
+			}
+		}
+		if tests > 0 && tests < len(changes) {
+			code := withoutTests(changes)
+			return describe(code, commitType) + " with tests"
+		}
+	}
+
+	// One verb for all of them, or a count per verb
+	counts := map[string][]string{}
+	var order []string
+	for _, c := range changes {
+		verb := verbOf(c)
+		if counts[verb] == nil {
+			order = append(order, verb)
+		}
+		counts[verb] = append(counts[verb], path.Base(c.Path))
+	}
+	if len(order) == 1 {
+		names := counts[order[0]]
+		if len(names) <= 3 {
+			return order[0] + " " + joinNames(names)
+		}
+		return fmt.Sprintf("%s %d files", order[0], len(names))
+	}
+	var parts []string
+	for _, verb := range order {
+		if n := len(counts[verb]); n == 1 {
+			parts = append(parts, verb+" "+counts[verb][0])
+		} else {
+			parts = append(parts, fmt.Sprintf("%s %d files", verb, n))
+		}
+	}
+	return joinNames(parts)
+}
+
+func verbOf(c git.FileChange) string {
+	if verb, ok := verbs[c.Status]; ok {
+		return verb
+	}
+	return "update"
+}
+
+func withoutTests(changes []git.FileChange) []git.FileChange {
+	var code []git.FileChange
+	for _, c := range changes {
+		if kind(c.Path) != "test" {
+			code = append(code, c)
+		}
+	}
+	return code
+}
+
+// joinNames joins names as "a", "a and b", or "a, b and c"
+func joinNames(names []string) string {
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
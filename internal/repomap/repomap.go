@@ -0,0 +1,210 @@
+// Package repomap describes the layout of a repository in a few lines: its
+// languages, frameworks, top-level directories, and key files, so the model
+// names components the way the project does.
+package repomap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxSize caps the map, so a huge monorepo doesn't crowd out the diff
+const maxSize = 2000
+
+// maxSubdirs is how many subdirectories of a top-level directory are named
+const maxSubdirs = 8
+
+// languages maps file extensions to language names
+var languages = map[string]string{
+	".go": "Go", ".rs": "Rust", ".py": "Python", ".rb": "Ruby", ".java": "Java",
+	".kt": "Kotlin", ".swift": "Swift", ".c": "C", ".h": "C", ".cc": "C++",
+	".cpp": "C++", ".hpp": "C++", ".cs": "C#", ".php": "PHP", ".js": "JavaScript",
+	".jsx": "JavaScript", ".mjs": "JavaScript", ".ts": "TypeScript", ".tsx": "TypeScript",
+	".vue": "Vue", ".svelte": "Svelte", ".scala": "Scala", ".ex": "Elixir",
+	".exs": "Elixir", ".dart": "Dart", ".sh": "Shell", ".tf": "Terraform",
+	".sql": "SQL", ".lua": "Lua", ".zig": "Zig",
+}
+
+// keyFiles are the root files worth naming: manifests, build files, and docs
+var keyFiles = map[string]bool{
+	"go.mod": true, "go.work": true, "package.json": true, "pnpm-workspace.yaml": true,
+	"Cargo.toml": true, "pyproject.toml": true, "setup.py": true, "requirements.txt": true,
+	"Gemfile": true, "pom.xml": true, "build.gradle": true, "build.gradle.kts": true,
+	"composer.json": true, "mix.exs": true, "pubspec.yaml": true, "Makefile": true,
+	"Dockerfile": true, "docker-compose.yml": true, "compose.yaml": true,
+	"README.md": true, "nx.json": true, "turbo.json": true, "lerna.json": true,
+}
+
+// npmFrameworks are the package.json dependencies that name a framework
+var npmFrameworks = map[string]string{
+	"react": "React", "next": "Next.js", "vue": "Vue", "nuxt": "Nuxt",
+	"@angular/core": "Angular", "svelte": "Svelte", "@sveltejs/kit": "SvelteKit",
+	"express": "Express", "fastify": "Fastify", "@nestjs/core": "NestJS",
+	"react-native": "React Native", "electron": "Electron", "vite": "Vite",
+}
+
+// textFrameworks are names that mark a framework when they appear in a
+// manifest, e.g. "django" in requirements.txt
+var textFrameworks = map[string][][2]string{
+	"requirements.txt": {{"django", "Django"}, {"flask", "Flask"}, {"fastapi", "FastAPI"}},
+	"pyproject.toml":   {{"django", "Django"}, {"flask", "Flask"}, {"fastapi", "FastAPI"}},
+	"Gemfile":          {{"rails", "Rails"}, {"sinatra", "Sinatra"}},
+	"pom.xml":          {{"spring-boot", "Spring Boot"}},
+	"build.gradle":     {{"spring-boot", "Spring Boot"}, {"com.android", "Android"}},
+	"Cargo.toml":       {{"actix-web", "Actix Web"}, {"axum", "Axum"}, {"tokio", "Tokio"}},
+	"go.mod":           {{"gin-gonic/gin", "Gin"}, {"labstack/echo", "Echo"}, {"gofiber/fiber", "Fiber"}},
+	"mix.exs":          {{":phoenix", "Phoenix"}},
+	"composer.json":    {{"laravel/framework", "Laravel"}, {"symfony/", "Symfony"}},
+}
+
+// Build describes the repository at root from its tracked files (paths
+// relative to root, with forward slashes). It returns "" for an empty list.
+func Build(root string, files []string) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	langCount := make(map[string]int)
+	dirCount := make(map[string]int)
+	subdirs := make(map[string]map[string]bool)
+	var rootFiles []string
+	for _, file := range files {
+		if lang, ok := languages[strings.ToLower(path.Ext(file))]; ok {
+			langCount[lang]++
+		}
+		top, rest, nested := strings.Cut(file, "/")
+		if !nested {
+			if keyFiles[file] {
+				rootFiles = append(rootFiles, file)
+			}
+			continue
+		}
+		dirCount[top]++
+		if sub, _, ok := strings.Cut(rest, "/"); ok {
+			if subdirs[top] == nil {
+				subdirs[top] = make(map[string]bool)
+			}
+			subdirs[top][sub] = true
+		}
+	}
+
+	var b strings.Builder
+	if langs := ranked(langCount); len(langs) > 0 {
+		b.WriteString("Languages: ")
+		for i, lang := range langs[:min(len(langs), 5)] {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "%s (%d files)", lang, langCount[lang])
+		}
+		b.WriteString("\n")
+	}
+	if frameworks := detectFrameworks(root, rootFiles); len(frameworks) > 0 {
+		fmt.Fprintf(&b, "Frameworks: %s\n", strings.Join(frameworks, ", "))
+	}
+	if module := goModule(root); module != "" {
+		fmt.Fprintf(&b, "Go module: %s\n", module)
+	}
+	if len(rootFiles) > 0 {
+		sort.Strings(rootFiles)
+		fmt.Fprintf(&b, "Key files: %s\n", strings.Join(rootFiles, ", "))
+	}
+	if len(dirCount) > 0 {
+		b.WriteString("Directories:\n")
+		for _, dir := range ranked(dirCount) {
+			line := fmt.Sprintf("- %s/ (%d files)", dir, dirCount[dir])
+			if subs := sortedKeys(subdirs[dir]); len(subs) > 0 {
+				more := ""
+				if len(subs) > maxSubdirs {
+					more = fmt.Sprintf(", and %d more", len(subs)-maxSubdirs)
+					subs = subs[:maxSubdirs]
+				}
+				line += ": " + strings.Join(subs, ", ") + more
+			}
+			if b.Len()+len(line) > maxSize {
+				b.WriteString("- ...\n")
+				break
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// detectFrameworks names the frameworks the root manifests depend on
+func detectFrameworks(root string, rootFiles []string) []string {
+	found := make(map[string]bool)
+	for _, file := range rootFiles {
+		data, err := os.ReadFile(filepath.Join(root, file))
+		if err != nil {
+			continue
+		}
+		if file == "package.json" {
+			var manifest struct {
+				Dependencies    map[string]string `json:"dependencies"`
+				DevDependencies map[string]string `json:"devDependencies"`
+			}
+			if json.Unmarshal(data, &manifest) == nil {
+				for dep, name := range npmFrameworks {
+					if _, ok := manifest.Dependencies[dep]; ok {
+						found[name] = true
+					} else if _, ok := manifest.DevDependencies[dep]; ok {
+						found[name] = true
+					}
+				}
+			}
+			continue
+		}
+		text := strings.ToLower(string(data))
+		for _, marker := range textFrameworks[file] {
+			if strings.Contains(text, marker[0]) {
+				found[marker[1]] = true
+			}
+		}
+	}
+	return sortedKeys(found)
+}
+
+// goModule returns the module path in go.mod at root, if any
+func goModule(root string) string {
+	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(module), `"`)
+		}
+	}
+	return ""
+}
+
+// ranked returns the keys of counts, largest count first
+func ranked(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
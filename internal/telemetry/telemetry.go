@@ -0,0 +1,104 @@
+// Package telemetry sends the opt-in, anonymous usage events of cc. An event
+// says which command ran, for how long, with which model, and whether it
+// succeeded; never file names, diffs, messages, or repository details.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/quaywin/claude-commit/internal/network"
+)
+
+// IDFileName holds the random install ID in the config dir
+const IDFileName = "telemetry-id"
+
+// Event is everything one run reports
+type Event struct {
+	InstallID  string `json:"install_id"` // Random, only groups runs of one install
+	Version    string `json:"version"`
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+	Command    string `json:"command"`
+	Model      string `json:"model,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+	Success    bool   `json:"success"`
+	ExitCode   int    `json:"exit_code"`
+}
+
+// StoredInstallID returns the ID stored in dir, or "" if there is none yet
+func StoredInstallID(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, IDFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	return string(bytes.TrimSpace(data)), err
+}
+
+// InstallID returns the random ID stored in dir, creating it on first use
+func InstallID(dir string) (string, error) {
+	if id, err := StoredInstallID(dir); err != nil || id != "" {
+		return id, err
+	}
+	path := filepath.Join(dir, IDFileName)
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	encoded := hex.EncodeToString(id)
+	return encoded, os.WriteFile(path, []byte(encoded+"\n"), 0600)
+}
+
+// DisabledByEnv reports whether the environment turns telemetry off
+// (DO_NOT_TRACK=1 or CC_TELEMETRY=off), whatever the config says
+func DisabledByEnv() bool {
+	if value := os.Getenv("DO_NOT_TRACK"); value != "" && value != "0" {
+		return true
+	}
+	switch strings.ToLower(os.Getenv("CC_TELEMETRY")) {
+	case "0", "off", "false", "no":
+		return true
+	}
+	return false
+}
+
+// Send posts event to endpoint, giving up after timeout so a slow or
+// unreachable endpoint never holds up cc
+func Send(endpoint string, event Event, timeout time.Duration) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cc-cli/"+event.Version)
+
+	resp, err := network.Client().Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry endpoint returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// runCtx is the context of the whole run, passed to every git and claude
+// call. The first Ctrl+C (or SIGTERM) cancels it: the running claude call is
+// killed, git is interrupted, prompts give up, and cc stops before the next
+// step. A second Ctrl+C quits at once.
+var runCtx = context.Background()
+
+// errInterrupted stops a run after Ctrl+C
+var errInterrupted = withExitCode(exitInterrupted, errors.New("interrupted"))
+
+// watchInterrupts turns Ctrl+C into a cancellation of runCtx
+func watchInterrupts() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop() // Let a second Ctrl+C terminate the process
+	}()
+	runCtx = ctx
+}
+
+// checkInterrupted returns errInterrupted once Ctrl+C was pressed
+func checkInterrupted() error {
+	if runCtx.Err() != nil {
+		return errInterrupted
+	}
+	return nil
+}
+
+// readLine reads a line from reader, giving up with errInterrupted on Ctrl+C
+func readLine(reader *bufio.Reader) (string, error) {
+	type result struct {
+		line string
+		err  error
+	}
+	read := make(chan result, 1)
+	go func() {
+		line, err := reader.ReadString('\n')
+		read <- result{line, err}
+	}()
+	select {
+	case r := <-read:
+		return r.line, r.err
+	case <-runCtx.Done():
+		printf("\n")
+		return "", errInterrupted
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/debuglog"
+	"github.com/quaywin/claude-commit/internal/git"
+	"github.com/quaywin/claude-commit/internal/github"
+	"github.com/quaywin/claude-commit/internal/linear"
+)
+
+// Issue providers
+const (
+	issueGitHub = "github"
+	issueLinear = "linear"
+)
+
+// issueFlag is the issue given with --issue, e.g. "#123", "ENG-42", or a URL
+var issueFlag string
+
+// maxIssueContext caps the issue text quoted in the prompt
+const maxIssueContext = 1500
+
+// workIssue is the issue a change works on
+type workIssue struct {
+	Provider string
+	Ref      string // "#123" or "ENG-42"
+	Title    string // Empty when it couldn't be fetched
+	Criteria string // The acceptance criteria, or else the start of the description
+}
+
+var (
+	// linearRef matches a Linear identifier, e.g. "ENG-42" (or "eng-42" in a branch name)
+	linearRef = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])([a-z][a-z0-9]{0,9}-\d+)(?:$|[^0-9])`)
+	// linearURL matches https://linear.app/<team>/issue/ENG-42/<slug>
+	linearURL = regexp.MustCompile(`linear\.app/[^/]+/issue/([A-Za-z][A-Za-z0-9]*-\d+)`)
+	// githubURL matches https://github.com/<owner>/<repo>/issues/123
+	githubURL = regexp.MustCompile(`github\.com/[^/]+/[^/]+/issues/(\d+)`)
+	// githubBranch matches the issue number of branch names like
+	// "123-fix-login", "fix/123-login", "issue-123", or "gh-123"
+	githubBranch = regexp.MustCompile(`(?i)(?:^|/)(?:issue-|issues/|gh-)?(\d+)(?:-|$)`)
+)
+
+// parseIssueRef works out the provider and reference of an issue given by
+// hand: "#123" or "123" on GitHub, "ENG-42" on Linear, or their URLs
+func parseIssueRef(ref string) (provider, id string, err error) {
+	ref = strings.TrimSpace(ref)
+	if m := linearURL.FindStringSubmatch(ref); m != nil {
+		return issueLinear, strings.ToUpper(m[1]), nil
+	}
+	if m := githubURL.FindStringSubmatch(ref); m != nil {
+		return issueGitHub, "#" + m[1], nil
+	}
+	if n := strings.TrimPrefix(ref, "#"); n != "" && strings.Trim(n, "0123456789") == "" {
+		return issueGitHub, "#" + n, nil
+	}
+	if m := linearRef.FindStringSubmatch(ref); m != nil && m[1] == ref {
+		return issueLinear, strings.ToUpper(ref), nil
+	}
+	return "", "", fmt.Errorf("--issue: can't tell which issue %q is (use #123, ENG-42, or the issue's URL)", ref)
+}
+
+// branchIssueRef finds an issue reference in the current branch name: a
+// Linear identifier or a GitHub issue number, depending on issues.provider
+// or, without one, on whether a Linear API key is set
+func branchIssueRef(cfg *config.Config) (provider, id string) {
+	branch, err := git.GetCurrentBranch(runCtx)
+	if err != nil || branch == "" {
+		return "", ""
+	}
+	provider = cfg.Issues.Provider
+	if provider == "" {
+		provider = issueGitHub
+		if _, err := linear.ResolveToken(cfg.Issues.LinearToken); err == nil {
+			provider = issueLinear
+		}
+	}
+	if provider == issueLinear {
+		if m := linearRef.FindStringSubmatch(branch); m != nil {
+			return issueLinear, strings.ToUpper(m[1])
+		}
+		return "", ""
+	}
+	if m := githubBranch.FindStringSubmatch(branch); m != nil {
+		return issueGitHub, "#" + m[1]
+	}
+	return "", ""
+}
+
+// resolvedIssue caches the issue for the run; issueLooked tells whether
+// it has been looked for
+var (
+	resolvedIssue *workIssue
+	issueLooked   bool
+)
+
+// currentIssue returns the issue the change works on, from --issue or,
+// with issues.enabled, the branch name. It is fetched once per run; when
+// that fails the reference is still used for the closing line.
+func currentIssue(cfg *config.Config) *workIssue {
+	if issueLooked {
+		return resolvedIssue
+	}
+	issueLooked = true
+
+	var provider, ref string
+	if issueFlag != "" {
+		var err error
+		if provider, ref, err = parseIssueRef(issueFlag); err != nil {
+			logf("⚠️  %v\n", err)
+			return nil
+		}
+	} else if cfg.Issues.Enabled {
+		provider, ref = branchIssueRef(cfg)
+	}
+	if ref == "" {
+		return nil
+	}
+
+	issue := &workIssue{Provider: provider, Ref: ref}
+	if err := fetchIssue(cfg, issue); err != nil {
+		logf("⚠️  Could not fetch issue %s: %v\n", ref, err)
+	} else {
+		logf("🎫 Working on %s: %s\n", ref, issue.Title)
+	}
+	resolvedIssue = issue
+	return issue
+}
+
+// fetchIssue fills in the title and acceptance criteria of issue
+func fetchIssue(cfg *config.Config, issue *workIssue) error {
+	var body string
+	switch issue.Provider {
+	case issueLinear:
+		token, err := linear.ResolveToken(cfg.Issues.LinearToken)
+		if err != nil {
+			return err
+		}
+		found, err := linear.NewClient(token, "cc-cli/"+VERSION).GetIssue(issue.Ref)
+		if err != nil {
+			return err
+		}
+		issue.Title, body = found.Title, found.Description
+
+	default:
+		remoteURL, err := git.GetRemoteURL(runCtx, "origin")
+		if err != nil {
+			return fmt.Errorf("getting origin remote: %w", err)
+		}
+		owner, repo, err := github.ParseRemote(remoteURL)
+		if err != nil {
+			return err
+		}
+		number, _ := strconv.Atoi(strings.TrimPrefix(issue.Ref, "#"))
+		// Public repositories work without a token
+		token, _ := github.ResolveToken(cfg.PullRequest.GitHubToken)
+		found, err := github.NewClient(token, "cc-cli/"+VERSION).GetIssue(owner, repo, number)
+		if err != nil {
+			return err
+		}
+		issue.Title, body = found.Title, found.Body
+	}
+	issue.Criteria = acceptanceCriteria(body)
+	debuglog.Log("fetched issue", "ref", issue.Ref, "title", issue.Title, "criteria_bytes", len(issue.Criteria))
+	return nil
+}
+
+// criteriaHeading matches the heading of an acceptance criteria section,
+// in Markdown ("## Acceptance criteria") or as a label line ("**Definition of done:**")
+var criteriaHeading = regexp.MustCompile(`(?i)^\s*(?:#+\s*)?\**\s*(?:acceptance criteria|definition of done)\s*:?\s*\**\s*:?\s*$`)
+
+// acceptanceCriteria returns the acceptance criteria section of an issue
+// description, up to the next heading, or else the start of the description
+func acceptanceCriteria(body string) string {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if !criteriaHeading.MatchString(line) {
+			continue
+		}
+		var section []string
+		for _, next := range lines[i+1:] {
+			if strings.HasPrefix(strings.TrimSpace(next), "#") {
+				break
+			}
+			section = append(section, next)
+		}
+		if text := strings.TrimSpace(strings.Join(section, "\n")); text != "" {
+			return truncateText(text, maxIssueContext)
+		}
+	}
+	return truncateText(strings.TrimSpace(body), maxIssueContext)
+}
+
+// truncateText cuts text to at most n bytes at a line break where possible
+func truncateText(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	cut := text[:n]
+	if i := strings.LastIndex(cut, "\n"); i > n/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "\n..."
+}
+
+// promptContext is the issue as quoted in the prompt
+func (i *workIssue) promptContext() string {
+	if i.Title == "" {
+		return ""
+	}
+	text := fmt.Sprintf("%s: %s", i.Ref, i.Title)
+	if i.Criteria != "" {
+		text += "\n" + i.Criteria
+	}
+	return text
+}
+
+// withIssueReference adds the closing reference of the current issue (e.g.
+// "Closes #123") as the last paragraph of a generated message
+func withIssueReference(cfg *config.Config, message string) string {
+	issue := currentIssue(cfg)
+	keyword := cfg.Issues.Keyword
+	if keyword == "" {
+		keyword = "Closes"
+	}
+	if issue == nil || strings.EqualFold(keyword, "none") || strings.Contains(message, issue.Ref) {
+		return message
+	}
+	return message + "\n\n" + keyword + " " + issue.Ref
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+	"github.com/quaywin/claude-commit/pkg/gitops"
+)
+
+// junkPatterns match file names that are almost never meant to be committed
+var junkPatterns = []string{
+	"core", "core.[0-9]*", "*.o", "*.obj", "*.a", "*.so", "*.dylib", "*.dll", "*.exe",
+	"*.class", "*.pyc", "*.swp", ".DS_Store", "Thumbs.db",
+}
+
+// junkDirs are build output and dependency directories; files in them are
+// only flagged while untracked
+var junkDirs = []string{"node_modules", "__pycache__", ".venv", "target", "dist", "build", ".gradle"}
+
+// suspectFile is a file that `git add .` would stage and probably shouldn't
+type suspectFile struct {
+	Path   string
+	Reason string
+}
+
+// findSuspectFiles returns the changed files above large_file_limit and
+// the untracked files that look like core dumps or build artifacts
+func findSuspectFiles(cfg *config.Config, files []string) ([]suspectFile, error) {
+	limit, err := cfg.LargeFileBytes()
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := git.GetUntrackedFiles(runCtx)
+	if err != nil {
+		return nil, err
+	}
+	root, err := git.GetRepoRoot(runCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	var suspects []suspectFile
+	for _, file := range files {
+		if limit > 0 {
+			if info, err := os.Stat(filepath.Join(root, file)); err == nil && info.Size() > limit {
+				suspects = append(suspects, suspectFile{file, fmt.Sprintf("%s, over the %s limit", gitops.FormatSize(info.Size()), gitops.FormatSize(limit))})
+				continue
+			}
+		}
+		if slices.Contains(untracked, file) {
+			if reason := junkReason(file); reason != "" {
+				suspects = append(suspects, suspectFile{file, reason})
+			}
+		}
+	}
+	return suspects, nil
+}
+
+// junkReason explains why a path looks like junk, or returns ""
+func junkReason(file string) string {
+	name := path.Base(file)
+	for _, pattern := range junkPatterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return "looks like a core dump or build artifact"
+		}
+	}
+	for _, dir := range strings.Split(path.Dir(file), "/") {
+		if slices.Contains(junkDirs, dir) {
+			return fmt.Sprintf("inside %s/", dir)
+		}
+	}
+	return ""
+}
+
+// confirmSuspectFiles lists the suspect files and asks before they get
+// staged. --yes confirms; without a terminal, the commit is refused. It
+// reports whether to go ahead.
+func confirmSuspectFiles(suspects []suspectFile) (bool, error) {
+	eprintf("\n⚠️  %d file(s) probably shouldn't be committed:\n", len(suspects))
+	for _, s := range suspects {
+		eprintf("   - %s (%s)\n", s.Path, s.Reason)
+	}
+
+	if output.Yes {
+		logln("Staging them anyway (--yes).")
+		return true, nil
+	}
+	if !interactive() {
+		return false, withExitCode(exitAborted, fmt.Errorf("refusing to stage these files without confirmation (add them to .gitignore, raise large_file_limit, or rerun with --yes)"))
+	}
+	return askYesNo(stdin, "❓ Stage and commit them anyway?", false)
+}
+
+func suspectPaths(suspects []suspectFile) string {
+	paths := make([]string, len(suspects))
+	for i, s := range suspects {
+		paths[i] = s.Path
+	}
+	return strings.Join(paths, ", ")
+}
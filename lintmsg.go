@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+)
+
+// scissorsLine marks the start of the diff shown by `git commit --verbose`;
+// everything below it is dropped from the message
+const scissorsLine = "# ------------------------ >8 ------------------------"
+
+// handleLintMsg checks the commit message in path against the configured
+// style. With fix set, Claude rewrites a failing message in place.
+func handleLintMsg(cfg *config.Config, path string, fix bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	message := stripComments(string(data))
+
+	format := messageFormat(cfg, "")
+	problems := claude.LintMessage(message, format)
+	if len(problems) == 0 {
+		logln("✅ Commit message looks good.")
+		return nil
+	}
+
+	eprintf("⚠️  Commit message problems (%d):\n", len(problems))
+	for _, problem := range problems {
+		eprintf("   - %s\n", problem)
+	}
+
+	if !fix {
+		return fmt.Errorf("commit message doesn't match the configured style (run 'cc lint-msg --fix %s' to let Claude rewrite it)", path)
+	}
+
+	stopSpinner := startSpinner("🤖 Claude is rewriting the message", "")
+	rewritten, err := claude.RewriteMessage(runCtx, message, problems, modelFor(cfg, taskMessage), format)
+	stopSpinner()
+	if err != nil {
+		return withExitCode(exitModelErr, fmt.Errorf("rewriting message: %w", err))
+	}
+
+	if err := os.WriteFile(path, []byte(rewritten+"\n"), 0644); err != nil {
+		return err
+	}
+	summaryf("\n📝 Rewrote the commit message:\n%s\n", rewritten)
+	return nil
+}
+
+// stripComments removes git's comment lines and the verbose diff from a message file
+func stripComments(content string) string {
+	if i := strings.Index(content, scissorsLine); i >= 0 {
+		content = content[:i]
+	}
+
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/debuglog"
+)
+
+func handleModels(cfg *config.Config) {
+	models := []string{
+		"haiku",
+		"sonnet",
+		"opus",
+	}
+
+	printf("Current model: %s\n", cfg.Model)
+	printf("\nSelect a model:\n")
+
+	// Check if current model is in the list
+	found := false
+	for i, m := range models {
+		prefix := "  "
+		if m == cfg.Model {
+			prefix = "* "
+			found = true
+		}
+		printf("%s%d. %s\n", prefix, i+1, m)
+	}
+
+	// Add custom option
+	customIdx := len(models) + 1
+	prefix := "  "
+	if !found {
+		prefix = "* "
+	}
+	printf("%s%d. Custom...\n", prefix, customIdx)
+
+	printf("\nEnter number to select (or press Enter to keep current): ")
+	reader := stdin
+	input, err := readLine(reader)
+	if errors.Is(err, errInterrupted) {
+		exit(exitInterrupted)
+	}
+	input = strings.TrimSpace(input)
+
+	if input == "" {
+		return
+	}
+
+	idx, err := strconv.Atoi(input)
+	if err != nil || idx < 1 || idx > customIdx {
+		printf("❌ Invalid selection\n")
+		return
+	}
+
+	if idx == customIdx {
+		printf("Enter custom model name: ")
+		customInput, err := readLine(reader)
+		if errors.Is(err, errInterrupted) {
+			exit(exitInterrupted)
+		}
+		customInput = strings.TrimSpace(customInput)
+		if customInput == "" {
+			printf("❌ Model name cannot be empty\n")
+			return
+		}
+		cfg.Model = customInput
+	} else {
+		cfg.Model = models[idx-1]
+	}
+
+	// Save only the model, not settings merged in from a profile
+	saved, err := config.Load()
+	if err != nil {
+		printf("❌ Error loading config: %v\n", err)
+		exit(1)
+	}
+	saved.Model = cfg.Model
+	if err := config.Save(saved); err != nil {
+		printf("❌ Error saving config: %v\n", err)
+		exit(1)
+	}
+
+	printf("✅ Model set to: %s\n", cfg.Model)
+}
+
+// Tasks with their own model in the models config
+const (
+	taskMessage  = "message"
+	taskReview   = "review"
+	taskSecurity = "security"
+)
+
+// modelFor returns the model configured for task, falling back to model
+func modelFor(cfg *config.Config, task string) string {
+	model := ""
+	switch task {
+	case taskMessage:
+		model = cfg.Models.Message
+	case taskReview:
+		model = cfg.Models.Review
+	case taskSecurity:
+		model = cmp.Or(cfg.Models.Security, cfg.Models.Review)
+	}
+	return cmp.Or(model, cfg.Model)
+}
+
+// reviewTask is the task of a review: a security review with --security
+func reviewTask() string {
+	if claude.SecurityReview {
+		return taskSecurity
+	}
+	return taskReview
+}
+
+// commitTask is the task of reviewing a commit of changed lines: commits
+// up to models.small_lines only need the message model
+func commitTask(cfg *config.Config, changed int) string {
+	if claude.SecurityReview {
+		return taskSecurity
+	}
+	if small, err := cfg.Models.SmallLineCount(); err == nil && small >= 0 && changed <= small {
+		return taskMessage
+	}
+	return taskReview
+}
+
+// withModel returns cfg, or a copy of it using model for this run
+func withModel(cfg *config.Config, model string) *config.Config {
+	if model == cfg.Model {
+		return cfg
+	}
+	debuglog.Log("model for this run", "model", model)
+	run := *cfg
+	run.Model = model
+	return &run
+}
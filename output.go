@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/quaywin/claude-commit/internal/i18n"
+)
+
+// output controls how progress and results are reported
+var output struct {
+	JSON   bool // Print a single JSON document at the end instead of logs (--json)
+	Quiet  bool // Print only final summary lines and errors (--quiet)
+	Yes    bool // Answer confirmation prompts with yes (--yes)
+	NoANSI bool // Never emit terminal control sequences such as the spinner (--ci)
+	Plain  bool // Drop emoji and control sequences (--no-emoji, NO_COLOR, or stdout not a terminal)
+}
+
+// stdin is shared by every prompt, so answers piped in together aren't lost
+// to the buffer of an earlier reader
+var stdin = bufio.NewReader(os.Stdin)
+
+// logf prints progress output unless --json or --quiet is set
+func logf(format string, a ...interface{}) {
+	if output.JSON || output.Quiet {
+		return
+	}
+	fmt.Printf(localize(format), a...)
+}
+
+// logln prints a line of progress output unless --json or --quiet is set
+func logln(a ...interface{}) {
+	if output.JSON || output.Quiet {
+		return
+	}
+	if len(a) == 1 {
+		if s, ok := a[0].(string); ok {
+			a[0] = localize(s)
+		}
+	}
+	fmt.Println(a...)
+}
+
+// summaryf prints a result line that is kept in --quiet mode
+func summaryf(format string, a ...interface{}) {
+	if output.JSON {
+		return
+	}
+	fmt.Printf(localize(format), a...)
+}
+
+// printf prints to stdout in every output mode, like fmt.Printf but
+// translated and without emoji in plain mode
+func printf(format string, a ...interface{}) {
+	fmt.Printf(localize(format), a...)
+}
+
+// printLine prints a line like fmt.Println, translated and without emoji in
+// plain mode
+func printLine(message string) {
+	fmt.Println(localize(message))
+}
+
+// eprintf is printf for stderr
+func eprintf(format string, a ...interface{}) {
+	fmt.Fprintf(os.Stderr, localize(format), a...)
+}
+
+// localize returns the translation of a message written in the source, without
+// its emoji in plain mode. Arguments aren't touched, so a commit message
+// keeps its gitmoji.
+func localize(message string) string {
+	message = i18n.T(message)
+	if output.Plain {
+		message = stripEmoji(message)
+	}
+	return message
+}
+
+// stripEmoji removes emoji and the spaces that follow them
+func stripEmoji(s string) string {
+	var b strings.Builder
+	skipSpace := false
+	for _, r := range s {
+		switch {
+		case isEmoji(r):
+			skipSpace = true
+			continue
+		case r == ' ' && skipSpace:
+			continue
+		}
+		skipSpace = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isEmoji reports whether r is one of the pictographs or symbols cc
+// decorates its output with, or a modifier of one
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // Pictographs, emoticons, transport, ...
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // Miscellaneous symbols and dingbats
+		return true
+	case r >= 0x2300 && r <= 0x23FF: // Technical symbols such as ⏪
+		return true
+	case r == 0x2139 || r == 0x21A9 || r == 0x21AA: // ℹ ↩ ↪
+		return true
+	case r == 0xFE0F || r == 0x200D: // Variation selector, zero-width joiner
+		return true
+	}
+	return false
+}
+
+// confirm asks a yes/no question on stdin, answering yes automatically with --yes
+func confirm(question string) (bool, error) {
+	question = localize(question)
+	if output.Yes {
+		logf("\n❓ %s (y/n): y (--yes)\n", question)
+		return true, nil
+	}
+
+	fmt.Printf(localize("\n❓ %s (y/n): "), question)
+	reader := stdin
+	response, err := readLine(reader)
+	if err != nil {
+		return false, fmt.Errorf("reading input: %w", err)
+	}
+
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes", nil
+}
+
+// interactive reports whether cc may ask questions: stdin is a terminal and
+// no output mode rules out prompting
+func interactive() bool {
+	if output.JSON || output.Quiet || output.Yes {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		eprintf("❌ Error encoding JSON output: %v\n", err)
+		exit(1)
+	}
+}
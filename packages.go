@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// workspacePackage is a package of a monorepo
+type workspacePackage struct {
+	Name string
+	Dir  string // Relative to the repository root, with slashes; "" for the root
+}
+
+// packageGroup is the changed files of one package. Files outside every
+// package form a group without a name.
+type packageGroup struct {
+	workspacePackage
+	Files []string
+}
+
+// handlePerPackage makes one reviewed commit per workspace package, each
+// with a message scoped to the package, and pushes them together
+func handlePerPackage(cfg *config.Config, opts commitOptions) {
+	reports, code, err := runPerPackage(cfg, opts)
+	if err != nil {
+		code = exitCodeOf(err)
+		if !output.JSON {
+			printf("❌ Error %v\n", err)
+		}
+	}
+	if output.JSON {
+		if reports == nil {
+			reports = []*commitReport{}
+		}
+		printJSON(reports)
+	}
+	exit(code)
+}
+
+// runPerPackage runs the commit pipeline for each package group in turn,
+// stopping at the first that doesn't end in a commit (or a dry run)
+func runPerPackage(cfg *config.Config, opts commitOptions) ([]*commitReport, int, error) {
+	if opts.Message != "" {
+		return nil, exitError, fmt.Errorf("--per-package writes a message for each package and can't be combined with --message")
+	}
+	if cfg.GitBackend == "go-git" {
+		return nil, exitError, fmt.Errorf("--per-package is not supported with git_backend go-git")
+	}
+	root, err := git.GetRepoRoot(runCtx)
+	if err != nil {
+		return nil, exitGitError, err
+	}
+	files, err := git.GetChangedFiles(runCtx)
+	if err != nil {
+		return nil, exitGitError, fmt.Errorf("getting changed files: %w", err)
+	}
+	packages, err := findPackages(cfg, root)
+	if err != nil {
+		return nil, exitError, err
+	}
+	if len(packages) == 0 {
+		return nil, exitError, fmt.Errorf("no packages found (use go.work, package.json workspaces, or \"packages\" in the config)")
+	}
+	groups := groupByPackage(packages, files)
+	if len(groups) == 0 {
+		summaryf("✅ No changes to commit.\n")
+		return nil, exitNoChanges, nil
+	}
+
+	names := make([]string, len(groups))
+	for i, g := range groups {
+		names[i] = g.label()
+	}
+	logf("📦 Committing %d package(s) separately: %s\n", len(groups), strings.Join(names, ", "))
+
+	// Catch a push that would be rejected once, before the first commit
+	push := !opts.NoPush
+	if push && !opts.Demo && !opts.DryRun {
+		aborted, err := checkBehind(cfg, &opts)
+		if err != nil {
+			return nil, exitCodeOf(err), err
+		}
+		if aborted {
+			summaryf("❌ Aborted. No changes were committed.\n")
+			return nil, exitAborted, nil
+		}
+		push = !opts.NoPush
+	}
+
+	var reports []*commitReport
+	userPaths := git.Paths
+	defer func() { git.Paths = userPaths }()
+	for _, g := range groups {
+		logf("\n📦 %s (%d file(s))\n", g.label(), len(g.Files))
+		git.Paths = g.pathspecs()
+		groupOpts := opts
+		groupOpts.NoPush = true
+		groupOpts.OpenPR = false
+		groupOpts.Paths = []string{g.label()}
+		if g.Name != "" {
+			groupOpts.Hint = fmt.Sprintf("All of these changes are in the %s package (%s); use %q as the scope.", g.Name, g.Dir, g.Name)
+		}
+
+		clock := newStepClock()
+		report, err := runCommit(cfg, groupOpts, clock)
+		report.Package = g.Name
+		code := finishRun(cfg, groupOpts, report, err, clock)
+		reports = append(reports, report)
+		if code != exitOK || (report.CommitSHA == "" && !report.DryRun) {
+			if code == exitOK {
+				code = exitNoChanges
+			}
+			if len(reports) > 1 {
+				summaryf("\n⚠️  Stopped at %s; the commits before it were kept and not pushed.\n", g.label())
+			}
+			return reports, code, nil
+		}
+	}
+
+	if !push || opts.DryRun || opts.Demo {
+		return reports, exitOK, nil
+	}
+	git.Paths = userPaths
+	logln("\n📤 Pushing...")
+	pushed, err := pushBranch(cfg, false, opts.ForcePush)
+	if err != nil && runCtx.Err() != nil {
+		return reports, exitInterrupted, errInterrupted
+	}
+	if err != nil {
+		return reports, exitPushError, withExitCode(exitPushError, fmt.Errorf("pushing: %w", err))
+	}
+	last := reports[len(reports)-1]
+	for _, report := range reports {
+		report.Pushed = pushed
+	}
+	if pushed {
+		runAfterPush(cfg, last)
+	}
+	if pushed && opts.OpenPR {
+		url, err := openPullRequest(cfg, opts.DraftPR)
+		if err != nil {
+			return reports, exitError, fmt.Errorf("creating pull request: %w", err)
+		}
+		last.PullRequestURL = url
+	}
+	summaryf("\n✨ Done! %d commit(s), %s\n", len(reports), doneMessage(last))
+	return reports, exitOK, nil
+}
+
+// label names the group in the output
+func (g packageGroup) label() string {
+	if g.Name == "" {
+		return "files outside any package"
+	}
+	return g.Name
+}
+
+// pathspecs limits git to exactly the group's files
+func (g packageGroup) pathspecs() []string {
+	specs := make([]string, len(g.Files))
+	for i, file := range g.Files {
+		specs[i] = ":(top,literal)" + file
+	}
+	return specs
+}
+
+// groupByPackage assigns each file to the package with the deepest
+// directory containing it. Groups are sorted by name, with the files outside
+// every package last.
+func groupByPackage(packages []workspacePackage, files []string) []packageGroup {
+	byDir := map[string]*packageGroup{}
+	var outside *packageGroup
+	for _, file := range files {
+		var owner *workspacePackage
+		for i, p := range packages {
+			if (p.Dir == "" || file == p.Dir || strings.HasPrefix(file, p.Dir+"/")) &&
+				(owner == nil || len(p.Dir) > len(owner.Dir)) {
+				owner = &packages[i]
+			}
+		}
+		if owner == nil {
+			if outside == nil {
+				outside = &packageGroup{}
+			}
+			outside.Files = append(outside.Files, file)
+			continue
+		}
+		g := byDir[owner.Dir]
+		if g == nil {
+			g = &packageGroup{workspacePackage: *owner}
+			byDir[owner.Dir] = g
+		}
+		g.Files = append(g.Files, file)
+	}
+
+	groups := make([]packageGroup, 0, len(byDir)+1)
+	for _, g := range byDir {
+		sort.Strings(g.Files)
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	if outside != nil {
+		sort.Strings(outside.Files)
+		groups = append(groups, *outside)
+	}
+	return groups
+}
+
+// findPackages returns the configured packages, or else the modules of
+// go.work and the workspaces of package.json
+func findPackages(cfg *config.Config, root string) ([]workspacePackage, error) {
+	if len(cfg.Packages) > 0 {
+		var packages []workspacePackage
+		for name, dir := range cfg.Packages {
+			packages = append(packages, workspacePackage{Name: name, Dir: cleanPackageDir(dir)})
+		}
+		return packages, nil
+	}
+
+	packages, err := goWorkModules(root)
+	if err != nil {
+		return nil, err
+	}
+	workspaces, err := npmWorkspaces(root)
+	if err != nil {
+		return nil, err
+	}
+	return append(packages, workspaces...), nil
+}
+
+// goWorkModules reads the use directives of go.work
+func goWorkModules(root string) ([]workspacePackage, error) {
+	data, err := os.ReadFile(filepath.Join(root, "go.work"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []workspacePackage
+	inBlock := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inBlock && fields[0] == ")":
+			inBlock = false
+			continue
+		case inBlock:
+		case fields[0] == "use" && len(fields) > 1 && fields[1] == "(":
+			inBlock = true
+			continue
+		case fields[0] == "use" && len(fields) > 1:
+			fields = fields[1:]
+		default:
+			continue
+		}
+		dir := cleanPackageDir(strings.Trim(fields[0], `"`))
+		if dir == ".." || strings.HasPrefix(dir, "../") {
+			continue // Outside the repository
+		}
+		name := path.Base(dir)
+		if dir == "" {
+			name = filepath.Base(root)
+		}
+		packages = append(packages, workspacePackage{Name: name, Dir: dir})
+	}
+	return packages, nil
+}
+
+// npmWorkspaces expands the workspaces globs of package.json (e.g.
+// "packages/*") to the directories with a package.json
+func npmWorkspaces(root string) ([]workspacePackage, error) {
+	data, err := os.ReadFile(filepath.Join(root, "package.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil || len(manifest.Workspaces) == 0 {
+		return nil, nil
+	}
+	// Either a list of globs or {"packages": [...]} (Yarn)
+	var patterns []string
+	if err := json.Unmarshal(manifest.Workspaces, &patterns); err != nil {
+		var yarn struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(manifest.Workspaces, &yarn); err != nil {
+			return nil, fmt.Errorf("package.json: workspaces: %w", err)
+		}
+		patterns = yarn.Packages
+	}
+
+	var packages []workspacePackage
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("package.json: workspace %q: %w", pattern, err)
+		}
+		for _, dir := range matches {
+			data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+			if err != nil {
+				continue
+			}
+			rel, err := filepath.Rel(root, dir)
+			if err != nil {
+				continue
+			}
+			var pkg struct {
+				Name string `json:"name"`
+			}
+			_ = json.Unmarshal(data, &pkg)
+			// "@acme/web" is scoped as "web"
+			name := pkg.Name[strings.LastIndex(pkg.Name, "/")+1:]
+			if name == "" {
+				name = filepath.Base(dir)
+			}
+			packages = append(packages, workspacePackage{Name: name, Dir: cleanPackageDir(rel)})
+		}
+	}
+	return packages, nil
+}
+
+// cleanPackageDir normalizes a package directory to the form of git's paths
+func cleanPackageDir(dir string) string {
+	dir = path.Clean(filepath.ToSlash(dir))
+	if dir == "." {
+		return ""
+	}
+	return strings.TrimPrefix(dir, "./")
+}
@@ -0,0 +1,250 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/debuglog"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// Changes is the state of a repository that gets sent to the model
+type Changes struct {
+	Files          []string
+	Diff           string
+	UseSummaryMode bool     // Diff is a stat summary, because the full diff was too large (see git.ChooseDiff)
+	Hybrid         bool     // Diff has the full diffs of the smaller files and line counts of the others
+	LFSFiles       []string // Listed by name only; their content is left out of Diff
+	BinaryFiles    []string // Likewise, for other binary files
+	CollapsedFiles []string // Lockfiles and generated files, summarized by line count
+
+	collapsed map[string]git.LineCount
+	repo      *Repo
+}
+
+// Changes gathers the changed files of the working tree (staged, unstaged,
+// and untracked) and their diff, switching to a stat summary for large
+// changesets. Files is empty when there is nothing to commit.
+func (r *Repo) Changes(ctx context.Context) (*Changes, error) {
+	ctx = r.in(ctx)
+	files, err := git.GetChangedFiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting changed files: %w", err)
+	}
+
+	changes := &Changes{Files: files, repo: r}
+	if len(files) == 0 {
+		return changes, nil
+	}
+
+	changes.findLFSFiles(ctx)
+	changes.findBinaryFiles(ctx)
+	changes.findCollapsedFiles(ctx, false)
+	var mode git.DiffMode
+	changes.Diff, mode, err = git.ChooseDiff(len(files), func(summary bool) (string, error) {
+		if summary {
+			diff, err := git.GetDiffSummary(ctx, changes.omitted()...)
+			if err != nil {
+				return "", fmt.Errorf("getting git diff summary: %w", err)
+			}
+			return diff, nil
+		}
+		diff, err := git.GetDiff(ctx, changes.omitted()...)
+		if err != nil {
+			return "", fmt.Errorf("getting git diff: %w", err)
+		}
+		return diff, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	changes.setMode(mode)
+	changes.Diff += changes.fileContext(ctx, mode, false) + changes.fileSections(ctx)
+
+	return changes, nil
+}
+
+// StagedChanges is like Changes but only looks at the index, e.g. for hooks
+// that run inside `git commit`
+func (r *Repo) StagedChanges(ctx context.Context) (*Changes, error) {
+	ctx = r.in(ctx)
+	files, err := git.GetStagedFiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting staged files: %w", err)
+	}
+
+	changes := &Changes{Files: files, repo: r}
+	if len(files) == 0 {
+		return changes, nil
+	}
+
+	changes.findLFSFiles(ctx)
+	changes.findBinaryFiles(ctx)
+	changes.findCollapsedFiles(ctx, true)
+	var mode git.DiffMode
+	changes.Diff, mode, err = git.ChooseDiff(len(files), func(summary bool) (string, error) {
+		return git.GetStagedDiff(ctx, summary, changes.omitted()...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting staged diff: %w", err)
+	}
+	changes.setMode(mode)
+	changes.Diff += changes.fileContext(ctx, mode, true) + changes.fileSections(ctx)
+	return changes, nil
+}
+
+// Summarize replaces a full working-tree diff with the stat summary, e.g.
+// when the full diff would cost too much to review
+func (c *Changes) Summarize(ctx context.Context) error {
+	ctx = c.repo.in(ctx)
+	summary, err := git.GetDiffSummary(ctx, c.omitted()...)
+	if err != nil {
+		return fmt.Errorf("getting git diff summary: %w", err)
+	}
+	c.Diff = summary + c.fileSections(ctx)
+	c.setMode(git.SummaryDiff)
+	return nil
+}
+
+// setMode records how much of the diff Diff has
+func (c *Changes) setMode(mode git.DiffMode) {
+	c.UseSummaryMode = mode == git.SummaryDiff
+	c.Hybrid = mode == git.HybridDiff
+}
+
+// findLFSFiles picks out the files managed by Git LFS, whose media content
+// would only bloat the prompt
+func (c *Changes) findLFSFiles(ctx context.Context) {
+	lfs, err := git.GetLFSFiles(ctx, c.Files)
+	if err != nil {
+		debuglog.Log("checking for Git LFS files", "error", err)
+		return
+	}
+	c.LFSFiles = lfs
+}
+
+// findBinaryFiles picks out the other changed binary files among Files,
+// whose diff would only say "Binary files differ"
+func (c *Changes) findBinaryFiles(ctx context.Context) {
+	binary, err := git.GetBinaryFiles(ctx)
+	if err != nil {
+		debuglog.Log("checking for binary files", "error", err)
+		return
+	}
+	for _, path := range binary {
+		if slices.Contains(c.Files, path) && !slices.Contains(c.LFSFiles, path) {
+			c.BinaryFiles = append(c.BinaryFiles, path)
+		}
+	}
+}
+
+// findCollapsedFiles picks out the lockfiles and generated files among the
+// remaining Files (see git.Collapse) and counts their changed lines
+func (c *Changes) findCollapsedFiles(ctx context.Context, staged bool) {
+	for _, path := range c.Files {
+		if git.IsCollapsed(path) && !slices.Contains(c.LFSFiles, path) && !slices.Contains(c.BinaryFiles, path) {
+			c.CollapsedFiles = append(c.CollapsedFiles, path)
+		}
+	}
+	if len(c.CollapsedFiles) == 0 {
+		return
+	}
+	counts, err := git.GetLineCounts(ctx, c.CollapsedFiles, staged)
+	if err != nil {
+		debuglog.Log("counting lines of collapsed files", "error", err)
+		return
+	}
+	c.collapsed = counts
+}
+
+// omitted returns the files whose content is left out of the diff
+func (c *Changes) omitted() []string {
+	omitted := append(slices.Clone(c.LFSFiles), c.BinaryFiles...)
+	return append(omitted, c.CollapsedFiles...)
+}
+
+// fileContext returns the content of the small modified files (see
+// git.FileContext) when the model gets the full diff; otherwise the diff is
+// already too large for more
+func (c *Changes) fileContext(ctx context.Context, mode git.DiffMode, staged bool) string {
+	if mode != git.FullDiff {
+		return ""
+	}
+	omitted := c.omitted()
+	files := slices.DeleteFunc(slices.Clone(c.Files), func(path string) bool {
+		return slices.Contains(omitted, path)
+	})
+	return git.FileContext(ctx, files, staged)
+}
+
+// fileSections lists the Git LFS, other binary, and collapsed files for the model
+func (c *Changes) fileSections(ctx context.Context) string {
+	lfs := fileListSection(ctx, "--- GIT LFS FILES (binary content not shown; judge them by name only) ---", c.LFSFiles)
+	header := fmt.Sprintf("--- BINARY FILES (%d changed; content not shown) ---", len(c.BinaryFiles))
+	return lfs + fileListSection(ctx, header, c.BinaryFiles) + c.collapsedSection()
+}
+
+// collapsedSection summarizes each collapsed file in one line, e.g.
+// "go.sum: 120 additions, 3 deletions (dependency update)"
+func (c *Changes) collapsedSection() string {
+	if len(c.CollapsedFiles) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n--- COLLAPSED FILES (lockfiles and generated code; content not shown) ---\n")
+	for _, path := range c.CollapsedFiles {
+		kind := "generated code"
+		if isLockfile(path) {
+			kind = "dependency update"
+		}
+		if count, ok := c.collapsed[path]; ok {
+			fmt.Fprintf(&b, "%s: %d additions, %d deletions (%s)\n", path, count.Added, count.Deleted, kind)
+		} else {
+			fmt.Fprintf(&b, "%s: changed (%s)\n", path, kind)
+		}
+	}
+	return b.String()
+}
+
+// isLockfile reports whether path looks like a dependency lockfile rather
+// than generated code
+func isLockfile(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	return strings.Contains(name, "lock") || name == "go.sum" || name == "npm-shrinkwrap.json"
+}
+
+// fileListSection lists files below a header, with their current sizes
+func fileListSection(ctx context.Context, header string, files []string) string {
+	if len(files) == 0 {
+		return ""
+	}
+	root, _ := git.GetRepoRoot(ctx)
+	var b strings.Builder
+	b.WriteString("\n" + header + "\n")
+	for _, path := range files {
+		if info, err := os.Stat(filepath.Join(root, path)); err == nil {
+			fmt.Fprintf(&b, "%s (%s)\n", path, FormatSize(info.Size()))
+		} else {
+			fmt.Fprintf(&b, "%s (deleted)\n", path)
+		}
+	}
+	return b.String()
+}
+
+// FormatSize formats a byte count for people, e.g. "1.5 MB"
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
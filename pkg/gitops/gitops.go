@@ -0,0 +1,68 @@
+// Package gitops is the git side of cc for other Go programs: it collects
+// the changes of a repository in the form a model reviews them, and stages,
+// commits, and pushes them. It runs the git binary.
+//
+// The API of the packages under pkg/ is kept stable across cc releases;
+// everything under internal/ may change at any time.
+package gitops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// Repo is a git repository, or any directory inside one
+type Repo struct {
+	// Dir is the directory git runs in; "" means the current directory
+	Dir string
+}
+
+// Open returns the repository containing dir
+func Open(ctx context.Context, dir string) (*Repo, error) {
+	root, err := git.GetRepoRoot(git.WithDir(ctx, dir))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not inside a git repository: %w", dir, err)
+	}
+	return &Repo{Dir: root}, nil
+}
+
+// in returns ctx with the repository's directory for the git package
+func (r *Repo) in(ctx context.Context) context.Context {
+	if r == nil || r.Dir == "" {
+		return ctx
+	}
+	return git.WithDir(ctx, r.Dir)
+}
+
+// CurrentBranch returns the name of the checked out branch
+func (r *Repo) CurrentBranch(ctx context.Context) (string, error) {
+	return git.GetCurrentBranch(r.in(ctx))
+}
+
+// StageAll stages every change in the working tree
+func (r *Repo) StageAll(ctx context.Context) error {
+	if err := git.StageAll(r.in(ctx)); err != nil {
+		return fmt.Errorf("staging changes: %w", err)
+	}
+	return nil
+}
+
+// Commit commits the index with message and returns the new commit's SHA.
+// signOff adds a Signed-off-by trailer.
+func (r *Repo) Commit(ctx context.Context, message string, signOff bool) (string, error) {
+	ctx = r.in(ctx)
+	if err := git.Commit(ctx, message, signOff); err != nil {
+		return "", fmt.Errorf("committing: %w", err)
+	}
+	return git.GetHeadSHA(ctx)
+}
+
+// Push pushes the current branch to its upstream
+func (r *Repo) Push(ctx context.Context) error {
+	if err := git.Push(r.in(ctx), false); err != nil {
+		return fmt.Errorf("pushing: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Package providers reviews diffs and writes commit messages with a model.
+// ClaudeCLI, the provider cc uses, runs the claude CLI, which must be
+// installed and logged in (or have ANTHROPIC_API_KEY set).
+//
+// The API of the packages under pkg/ is kept stable across cc releases;
+// everything under internal/ may change at any time.
+package providers
+
+import (
+	"context"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+)
+
+// Severity ranks how serious a finding is
+type Severity = claude.Severity
+
+const (
+	SeverityInfo     = claude.SeverityInfo
+	SeverityWarning  = claude.SeverityWarning
+	SeverityCritical = claude.SeverityCritical
+)
+
+// ParseSeverity parses "info", "warning", or "critical" (case-insensitive)
+func ParseSeverity(text string) (Severity, error) {
+	return claude.ParseSeverity(text)
+}
+
+type (
+	// Finding is a single problem reported by a review
+	Finding = claude.Finding
+	// Result is the findings and commit message for a diff; see
+	// Result.Blocking for the findings that should stop a commit
+	Result = claude.Result
+	// Review is the result of a review without a commit message
+	Review = claude.Review
+	// MessageFormat selects the parts and languages of a commit message
+	MessageFormat = claude.MessageFormat
+)
+
+// ErrUnavailable matches (with errors.Is) the errors of a provider that
+// can't be reached at all, as opposed to one that gave an unusable answer
+var ErrUnavailable = claude.ErrUnavailable
+
+// Provider reviews diffs. summary tells that diff is a stat summary of a
+// large changeset rather than a full diff.
+type Provider interface {
+	// ReviewAndMessage reviews diff and writes a commit message for it
+	ReviewAndMessage(ctx context.Context, diff string, summary bool, format MessageFormat) (*Result, error)
+	// Review reviews diff in more depth, without a commit message
+	Review(ctx context.Context, diff string, summary bool) (*Review, error)
+}
+
+// ClaudeCLI is the Provider that runs the claude CLI
+type ClaudeCLI struct {
+	// Model is passed to --model, e.g. "haiku", "sonnet", or a full model
+	// name; "" means cc's default model
+	Model string
+}
+
+func (p ClaudeCLI) ReviewAndMessage(ctx context.Context, diff string, summary bool, format MessageFormat) (*Result, error) {
+	return claude.ReviewAndCommitMessage(ctx, diff, p.model(), summary, format, nil)
+}
+
+func (p ClaudeCLI) Review(ctx context.Context, diff string, summary bool) (*Review, error) {
+	return claude.ReviewChanges(ctx, diff, p.model(), summary)
+}
+
+func (p ClaudeCLI) model() string {
+	if p.Model == "" {
+		return config.DefaultModel
+	}
+	return p.Model
+}
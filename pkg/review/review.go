@@ -0,0 +1,91 @@
+// Package review runs cc's pipeline from Go: collect the changes of a
+// repository, have a provider review them and write a commit message, and
+// commit them unless a finding blocks it.
+//
+//	repo, err := gitops.Open(ctx, dir)
+//	...
+//	outcome, err := review.Run(ctx, repo, review.Options{Provider: providers.ClaudeCLI{Model: "sonnet"}})
+//	...
+//	if len(outcome.Blocking(providers.SeverityCritical)) == 0 {
+//		sha, err := review.Commit(ctx, repo, outcome)
+//	}
+//
+// The API of the packages under pkg/ is kept stable across cc releases;
+// everything under internal/ may change at any time.
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/quaywin/claude-commit/pkg/gitops"
+	"github.com/quaywin/claude-commit/pkg/providers"
+)
+
+// ErrNoChanges is returned by Run when there is nothing to review
+var ErrNoChanges = errors.New("no changes to review")
+
+// Options configure Run
+type Options struct {
+	// Provider reviews the diff; nil means providers.ClaudeCLI with cc's
+	// default model
+	Provider providers.Provider
+	// Format selects the parts and languages of the commit message
+	Format providers.MessageFormat
+	// Staged reviews only the staged changes instead of the whole working tree
+	Staged bool
+	// SignOff adds a Signed-off-by trailer when committing
+	SignOff bool
+}
+
+// Outcome is the result of Run: the reviewed changes, and the findings and
+// commit message (with Blocking for the findings that should stop a commit)
+type Outcome struct {
+	Changes *gitops.Changes
+	*providers.Result
+
+	staged  bool
+	signOff bool
+}
+
+// Run collects the changes of repo and has the provider review them and
+// write a commit message. Nothing in the repository is modified.
+func Run(ctx context.Context, repo *gitops.Repo, opts Options) (*Outcome, error) {
+	provider := opts.Provider
+	if provider == nil {
+		provider = providers.ClaudeCLI{}
+	}
+
+	var changes *gitops.Changes
+	var err error
+	if opts.Staged {
+		changes, err = repo.StagedChanges(ctx)
+	} else {
+		changes, err = repo.Changes(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(changes.Files) == 0 || changes.Diff == "" {
+		return nil, ErrNoChanges
+	}
+
+	result, err := provider.ReviewAndMessage(ctx, changes.Diff, changes.UseSummaryMode, opts.Format)
+	if err != nil {
+		return nil, fmt.Errorf("reviewing changes: %w", err)
+	}
+	return &Outcome{Changes: changes, Result: result, staged: opts.Staged, signOff: opts.SignOff}, nil
+}
+
+// Commit stages the reviewed changes (unless only staged changes were
+// reviewed) and commits them with the outcome's message, returning the new
+// commit's SHA. Checking the findings first is up to the caller.
+func Commit(ctx context.Context, repo *gitops.Repo, outcome *Outcome) (string, error) {
+	if !outcome.staged {
+		if err := repo.StageAll(ctx); err != nil {
+			return "", err
+		}
+	}
+	return repo.Commit(ctx, outcome.Message, outcome.signOff)
+}
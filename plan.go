@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+)
+
+// planAction is the choice made in the plan-mode menu
+type planAction int
+
+const (
+	planAccept planAction = iota
+	planRegenerate
+	planAbort
+)
+
+// planMenu asks what to do with the proposed message. Editing updates
+// *message and asks again; regenerating and changing the model set *hint
+// and cfg.Model for the next attempt. Asking starts (or continues) the
+// conversation about the changes.
+func planMenu(cfg *config.Config, conversation *claude.Conversation, message *string, hint *string) (planAction, error) {
+	if output.Yes {
+		logf("\n❓ Commit and push these changes? accept (--yes)\n")
+		return planAccept, nil
+	}
+
+	reader := stdin
+	for {
+		printf("\n❓ [a]ccept  [e]dit  [r]egenerate  [m]odel  a[s]k  [q]uit: ")
+		choice, err := readLine(reader)
+		if err != nil {
+			return planAbort, fmt.Errorf("reading input: %w", err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(choice)) {
+		case "a", "accept", "y", "yes":
+			return planAccept, nil
+
+		case "e", "edit":
+			edited, err := editMessage(*message)
+			if err != nil {
+				return planAbort, err
+			}
+			if edited == "" {
+				printLine("⚠️  Empty message, keeping the previous one.")
+				continue
+			}
+			*message = edited
+			printf("\n📝 Commit message:\n%s\n", *message)
+
+		case "r", "regenerate":
+			printf("💬 Hint for Claude (optional): ")
+			text, err := readLine(reader)
+			if err != nil {
+				return planAbort, fmt.Errorf("reading input: %w", err)
+			}
+			*hint = strings.TrimSpace(text)
+			if *hint == "" {
+				*hint = fmt.Sprintf("the previous suggestion %q was rejected; write a different message", *message)
+			}
+			return planRegenerate, nil
+
+		case "m", "model":
+			model, err := ask(reader, "🤖 Model", cfg.Model)
+			if err != nil {
+				return planAbort, err
+			}
+			cfg.Model = model
+			*hint = ""
+			return planRegenerate, nil
+
+		case "s", "ask", "?":
+			conversation.Message = *message
+			if err := askAboutChanges(cfg, conversation); err != nil {
+				return planAbort, err
+			}
+
+		case "q", "quit", "n", "no", "abort":
+			return planAbort, nil
+
+		default:
+			printLine("Please answer a, e, r, m, s, or q.")
+		}
+	}
+}
+
+// askAboutChanges lets the user ask Claude questions about the pending
+// changes (e.g. "is this migration backwards compatible?") until an empty line
+func askAboutChanges(cfg *config.Config, conversation *claude.Conversation) error {
+	printLine("💬 Ask Claude about these changes (empty line to go back).")
+	for {
+		printf("\n💬 Question: ")
+		question, err := readLine(stdin)
+		if err != nil {
+			return fmt.Errorf("reading input: %w", err)
+		}
+		if strings.TrimSpace(question) == "" {
+			return nil
+		}
+		stopSpinner := startSpinner("🤖 Claude is thinking", "")
+		answer, err := conversation.Ask(runCtx, question, cfg.Model)
+		stopSpinner()
+		if err := checkInterrupted(); err != nil {
+			return err
+		}
+		if err != nil {
+			printf("⚠️  Claude couldn't answer: %v\n", err)
+			continue
+		}
+		printf("\n🤖 %s\n", answer)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/clipboard"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// handlePRDesc generates a pull request description for the current branch
+// against base (the remote's default branch when empty)
+func handlePRDesc(cfg *config.Config, base string, copyToClipboard bool) {
+	if base == "" {
+		base = git.GetDefaultBranch(runCtx)
+	}
+
+	baseRef, err := git.ResolveBase(runCtx, base)
+	if err != nil {
+		printf("❌ Error: %v\n", err)
+		exit(1)
+	}
+
+	desc, err := generatePRDescription(baseRef, cfg)
+	if err != nil {
+		printf("❌ Error: %v\n", err)
+		exit(1)
+	}
+
+	markdown := desc.Markdown()
+	if copyToClipboard {
+		if err := clipboard.Copy(markdown); err != nil {
+			printf("❌ Error copying to clipboard: %v\n", err)
+			exit(1)
+		}
+		printf("📋 Pull request description copied to clipboard.\n")
+		return
+	}
+
+	printf("\n%s", markdown)
+}
+
+// generatePRDescription collects the branch diff against baseRef and asks Claude to describe it
+func generatePRDescription(baseRef string, cfg *config.Config) (claude.PRDescription, error) {
+	logf("🔍 Comparing current branch against %s...\n", baseRef)
+
+	files, err := git.GetBranchChangedFiles(runCtx, baseRef)
+	if err != nil {
+		return claude.PRDescription{}, fmt.Errorf("getting changed files: %w", err)
+	}
+	if len(files) == 0 {
+		return claude.PRDescription{}, fmt.Errorf("no changes between %s and HEAD", baseRef)
+	}
+
+	diff, mode, err := git.ChooseDiff(len(files), func(summary bool) (string, error) {
+		return git.GetBranchDiff(runCtx, baseRef, summary)
+	})
+	if err != nil {
+		return claude.PRDescription{}, fmt.Errorf("getting branch diff: %w", err)
+	}
+
+	log, err := git.GetBranchLog(runCtx, baseRef)
+	if err != nil {
+		return claude.PRDescription{}, fmt.Errorf("getting branch log: %w", err)
+	}
+
+	stopSpinner := startSpinner("🤖 Claude is writing the pull request description", fmt.Sprintf(" (%d files)", len(files)))
+	desc, err := claude.GeneratePRDescription(runCtx, diff, log, modelFor(cfg, taskMessage), mode == git.SummaryDiff)
+	stopSpinner()
+
+	if err != nil {
+		return claude.PRDescription{}, fmt.Errorf("calling Claude: %w", err)
+	}
+	return desc, nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+	"github.com/quaywin/claude-commit/internal/github"
+	"github.com/quaywin/claude-commit/internal/gitlab"
+)
+
+// openPullRequest opens a GitHub pull request or GitLab merge request for the
+// current branch with an AI-generated title and body. The integration is
+// picked from the host of the origin remote. It returns the URL of the new pull request.
+func openPullRequest(cfg *config.Config, draft bool) (string, error) {
+	// The pull request is opened from the branch that was just pushed
+	target, err := resolvePushTarget(cfg)
+	if err != nil {
+		return "", fmt.Errorf("getting current branch: %w", err)
+	}
+	branch := target.Branch
+
+	base := cfg.PullRequest.Base
+	if base == "" {
+		base = git.GetDefaultBranch(runCtx)
+	}
+	if branch == base {
+		logf("ℹ️  On base branch %s, skipping pull request.\n", base)
+		return "", nil
+	}
+
+	remoteURL, err := git.GetRemoteURL(runCtx, "origin")
+	if err != nil {
+		return "", fmt.Errorf("getting origin remote: %w", err)
+	}
+	host, path, err := git.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return "", err
+	}
+
+	gitlabURL := gitLabBaseURL(cfg, host)
+	if host != "github.com" && gitlabURL == "" {
+		return "", fmt.Errorf("don't know how to open a pull request on %s (set pull_request.gitlab_url for self-hosted GitLab)", host)
+	}
+
+	head := branch
+	if gitlabURL == "" && target.Remote != "origin" {
+		// Pushed to a fork: GitHub expects the head as "owner:branch"
+		if forkURL, err := git.GetRemoteURL(runCtx, target.Remote); err == nil {
+			if owner, _, err := github.ParseRemote(forkURL); err == nil {
+				head = owner + ":" + branch
+			}
+		}
+	}
+
+	// Later pushes to the branch update the pull request opened by the first
+	existing, err := findOpenPullRequest(cfg, gitlabURL, path, remoteURL, head, base)
+	if err != nil {
+		logf("⚠️  Warning: Could not look for an open pull request: %v\n", err)
+	} else if existing != "" {
+		return existing, nil
+	}
+
+	baseRef, err := git.ResolveBase(runCtx, base)
+	if err != nil {
+		return "", err
+	}
+	desc, err := generatePRDescription(baseRef, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	draft = draft || cfg.PullRequest.Draft
+	if gitlabURL != "" {
+		return openGitLabMergeRequest(cfg, gitlabURL, path, branch, base, desc, draft)
+	}
+	return openGitHubPullRequest(cfg, remoteURL, head, base, desc, draft)
+}
+
+// findOpenPullRequest returns the URL of the open pull request (or merge
+// request) from head into base, or "" when there is none
+func findOpenPullRequest(cfg *config.Config, gitlabURL, projectPath, remoteURL, head, base string) (string, error) {
+	if gitlabURL != "" {
+		token, err := gitlab.ResolveToken(cfg.PullRequest.GitLabToken)
+		if err != nil {
+			return "", err
+		}
+		found, err := gitlab.NewClient(gitlabURL, token, "cc-cli/"+VERSION).FindMergeRequest(projectPath, head, base)
+		if err != nil || found == nil {
+			return "", err
+		}
+		logf("✅ Merge request !%d is already open and now has the push: %s\n", found.IID, found.WebURL)
+		return found.WebURL, nil
+	}
+
+	owner, repo, err := github.ParseRemote(remoteURL)
+	if err != nil {
+		return "", err
+	}
+	token, err := github.ResolveToken(cfg.PullRequest.GitHubToken)
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains(head, ":") {
+		head = owner + ":" + head
+	}
+	found, err := github.NewClient(token, "cc-cli/"+VERSION).FindPullRequest(owner, repo, head, base)
+	if err != nil || found == nil {
+		return "", err
+	}
+	logf("✅ Pull request #%d is already open and now has the push: %s\n", found.Number, found.HTMLURL)
+	return found.HTMLURL, nil
+}
+
+// gitLabBaseURL returns the GitLab instance URL for host, or "" if host is not GitLab:
+// gitlab.com, or the host of the configured gitlab_url. Other hosts never get the
+// GitLab token, whatever their name.
+func gitLabBaseURL(cfg *config.Config, host string) string {
+	if cfg.PullRequest.GitLabURL != "" {
+		if u, err := url.Parse(cfg.PullRequest.GitLabURL); err == nil && strings.EqualFold(u.Hostname(), host) {
+			return cfg.PullRequest.GitLabURL
+		}
+	}
+	if strings.EqualFold(host, "gitlab.com") {
+		return "https://gitlab.com"
+	}
+	return ""
+}
+
+func openGitHubPullRequest(cfg *config.Config, remoteURL, branch, base string, desc claude.PRDescription, draft bool) (string, error) {
+	owner, repo, err := github.ParseRemote(remoteURL)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := github.ResolveToken(cfg.PullRequest.GitHubToken)
+	if err != nil {
+		return "", err
+	}
+
+	logln("🔀 Opening pull request...")
+	client := github.NewClient(token, "cc-cli/"+VERSION)
+	created, err := client.CreatePullRequest(owner, repo, github.PullRequest{
+		Title: desc.Title,
+		Body:  desc.Body(),
+		Head:  branch,
+		Base:  base,
+		Draft: draft,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(cfg.PullRequest.Reviewers) > 0 {
+		if err := client.RequestReviewers(owner, repo, created.Number, cfg.PullRequest.Reviewers); err != nil {
+			logf("⚠️  Warning: Could not request reviewers: %v\n", err)
+		}
+	}
+
+	logf("✅ Pull request #%d opened: %s\n", created.Number, created.HTMLURL)
+	return created.HTMLURL, nil
+}
+
+func openGitLabMergeRequest(cfg *config.Config, baseURL, projectPath, branch, base string, desc claude.PRDescription, draft bool) (string, error) {
+	token, err := gitlab.ResolveToken(cfg.PullRequest.GitLabToken)
+	if err != nil {
+		return "", err
+	}
+
+	client := gitlab.NewClient(baseURL, token, "cc-cli/"+VERSION)
+	mr := gitlab.MergeRequest{
+		SourceBranch: branch,
+		TargetBranch: base,
+		Title:        desc.Title,
+		Description:  desc.Body(),
+	}
+
+	if len(cfg.PullRequest.Reviewers) > 0 {
+		ids, err := client.UserIDs(cfg.PullRequest.Reviewers)
+		if err != nil {
+			logf("⚠️  Warning: Could not resolve reviewers: %v\n", err)
+		} else {
+			mr.ReviewerIDs = ids
+		}
+	}
+
+	logln("🔀 Opening merge request...")
+	created, err := client.CreateMergeRequest(projectPath, mr, draft)
+	if err != nil {
+		return "", err
+	}
+
+	logf("✅ Merge request !%d opened: %s\n", created.IID, created.WebURL)
+	return created.WebURL, nil
+}
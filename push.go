@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/debuglog"
+	"github.com/quaywin/claude-commit/internal/git"
+	"github.com/quaywin/claude-commit/internal/i18n"
+)
+
+// pushTarget is where the current branch gets pushed
+type pushTarget struct {
+	Local  string // Current branch
+	Remote string
+	Branch string // Branch on the remote
+	// Plain means a bare `git push` to the existing upstream
+	Plain bool
+	// SetUpstream means the branch has no upstream yet and is pushed with --set-upstream
+	SetUpstream bool
+}
+
+func (t pushTarget) String() string {
+	return t.Remote + "/" + t.Branch
+}
+
+// resolvePushTarget combines push_remote and push_to (or --remote and
+// --push-to) with the current branch's upstream. The remote defaults to the
+// branch's remote, then origin; the remote branch to the local name.
+func resolvePushTarget(cfg *config.Config) (pushTarget, error) {
+	branch, err := git.GetCurrentBranch(runCtx)
+	if err != nil {
+		return pushTarget{}, err
+	}
+	upstream := git.GetUpstream(runCtx)
+
+	t := pushTarget{Local: branch, Remote: cfg.PushRemote, Branch: cfg.PushTo}
+	if t.Remote == "" {
+		if t.Remote = git.GetConfigValue(runCtx, "branch."+branch+".remote"); t.Remote == "" {
+			t.Remote = "origin"
+		}
+	}
+	if t.Branch == "" {
+		t.Branch = branch
+		t.SetUpstream = upstream == ""
+	}
+	t.Plain = cfg.PushRemote == "" && cfg.PushTo == "" && upstream != ""
+	return t, nil
+}
+
+// pushBranch pushes the current branch and reports whether it did. A branch
+// without an upstream is pushed with --set-upstream right away when
+// confirmed, set_upstream, or --yes says so; otherwise the user is asked,
+// and without a terminal to ask on the push fails with a hint. With force,
+// the push uses --force-with-lease after a warning.
+func pushBranch(cfg *config.Config, confirmed bool, force bool) (bool, error) {
+	t, err := resolvePushTarget(cfg)
+	if err != nil {
+		return false, err
+	}
+
+	if force {
+		if err := checkForcePush(cfg, t); err != nil {
+			return false, err
+		}
+		eprintf("\n⚠️  Force-pushing %s to %s (--force-with-lease). Commits on the remote branch that aren't in yours will be lost.\n", t.Local, t)
+		if !confirmed && interactive() {
+			ok, err := askYesNo(stdin, "❓ Force-push anyway?", false)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				return false, nil
+			}
+		}
+	}
+
+	if cfg.PullBeforePush && !force {
+		if err := pullBeforePush(t); err != nil {
+			return false, err
+		}
+	}
+
+	switch {
+	case t.Plain:
+		return true, git.Push(runCtx, force)
+
+	case t.SetUpstream:
+		if !confirmed && !cfg.SetUpstream && !output.Yes {
+			if !interactive() {
+				return false, fmt.Errorf("branch %s has no upstream branch (run 'git push --set-upstream %s %s', or set \"set_upstream\": true to let cc do it)", t.Local, t.Remote, t.Local)
+			}
+			ok, err := askYesNo(stdin, fmt.Sprintf(i18n.T("\n❓ Branch %s has no upstream. Push it to %s and track it?"), t.Local, t.Remote), true)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				return false, nil
+			}
+		}
+		logf("🔗 Setting upstream to %s\n", t)
+		return true, git.PushSetUpstream(runCtx, t.Remote, t.Local, force)
+	}
+
+	logf("📤 Pushing %s to %s\n", t.Local, t)
+	return true, git.PushTo(runCtx, t.Remote, t.Branch, force)
+}
+
+// checkForcePush refuses to force-push to a branch matching protected_branches
+func checkForcePush(cfg *config.Config, t pushTarget) error {
+	protected := cfg.ProtectedBranches
+	if len(protected) == 0 {
+		protected = []string{git.GetDefaultBranch(runCtx), "main", "master"}
+	}
+	for _, pattern := range protected {
+		if matched, _ := path.Match(pattern, t.Branch); matched {
+			return fmt.Errorf("refusing to force-push to protected branch %s (matches %q in protected_branches)", t.Branch, pattern)
+		}
+	}
+	return nil
+}
+
+// pullBeforePush fetches the remote and rebases onto the branch being pushed
+// to, so the push is a fast-forward. A rebase that stops on conflicts is
+// aborted, leaving the local commits untouched.
+func pullBeforePush(t pushTarget) error {
+	logf("🔄 Fetching %s...\n", t.Remote)
+	if err := git.Fetch(runCtx, t.Remote); err != nil {
+		return fmt.Errorf("fetching %s: %w", t.Remote, err)
+	}
+	upstream := "refs/remotes/" + t.Remote + "/" + t.Branch
+	if !git.RefExists(runCtx, upstream) {
+		return nil // Nothing to rebase onto yet
+	}
+	logf("🔄 Rebasing onto %s...\n", t)
+	if err := git.Rebase(runCtx, upstream); err != nil {
+		return fmt.Errorf("rebasing onto %s failed, so the rebase was aborted and your commit is unchanged; run 'git pull --rebase' to resolve it by hand: %w", t, err)
+	}
+	return nil
+}
+
+// checkBehind runs before anything is committed and looks for commits on
+// the remote branch that the local branch lacks, since the push would be
+// rejected. The user picks whether to rebase before pushing, commit on a
+// new branch, or skip the push; --yes rebases. It reports whether the user
+// quit instead.
+func checkBehind(cfg *config.Config, opts *commitOptions) (bool, error) {
+	if opts.NoPush || opts.ForcePush || cfg.PullBeforePush {
+		return false, nil
+	}
+	t, err := resolvePushTarget(cfg)
+	if err != nil {
+		return false, nil // Reported when pushing
+	}
+	if err := git.FetchBranch(runCtx, t.Remote, t.Branch); err != nil {
+		debuglog.Log("skipping the behind check", "target", t.String(), "error", err)
+		return false, nil
+	}
+
+	remoteRef := "refs/remotes/" + t.Remote + "/" + t.Branch
+	behind, err := git.CountCommits(runCtx, "HEAD.."+remoteRef)
+	if err != nil || behind == 0 {
+		return false, nil
+	}
+	ahead, _ := git.CountCommits(runCtx, remoteRef+"..HEAD")
+	if ahead > 0 {
+		eprintf("\n⚠️  %s has diverged from %s (%d local and %d remote commits), so the push would be rejected.\n", t.Local, t, ahead, behind)
+	} else {
+		eprintf("\n⚠️  %s is %d commit(s) behind %s, so the push would be rejected.\n", t.Local, behind, t)
+	}
+
+	if output.Yes {
+		logf("🔄 Will rebase onto %s before pushing (--yes)\n", t)
+		cfg.PullBeforePush = true
+		return false, nil
+	}
+	if !interactive() {
+		return false, withExitCode(exitPushError, fmt.Errorf("%s is behind %s (rerun with --pull to rebase before pushing, or --no-push)", t.Local, t))
+	}
+
+	for {
+		printf("❓ [r]ebase before pushing  commit on a new [b]ranch  [s]kip the push  [q]uit: ")
+		choice, err := readLine(stdin)
+		if err != nil {
+			return false, fmt.Errorf("reading input: %w", err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(choice)) {
+		case "r", "rebase":
+			cfg.PullBeforePush = true
+			return false, nil
+
+		case "b", "branch":
+			name, err := ask(stdin, "🌿 New branch name", t.Local+"-2")
+			if err != nil {
+				return false, err
+			}
+			if err := git.CreateBranch(runCtx, name); err != nil {
+				printf("❌ Error creating branch: %v\n", err)
+				continue
+			}
+			logf("🌿 Switched to new branch %s\n", name)
+			// Push the new branch under its own name and track it
+			cfg.PushTo = ""
+			cfg.SetUpstream = true
+			return false, nil
+
+		case "s", "skip":
+			opts.NoPush = true
+			return false, nil
+
+		case "q", "quit":
+			return true, nil
+
+		default:
+			printf("Please answer r, b, s, or q.\n")
+		}
+	}
+}
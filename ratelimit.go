@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// spinnerNote, when set, is shown after the running spinner's detail
+var spinnerNote atomic.Pointer[string]
+
+// spinnerActive tells whether a spinner is showing progress
+var spinnerActive atomic.Bool
+
+// waitRateLimit waits out a rate limit for the claude package, counting
+// down in the running spinner, or saying once how long it waits otherwise
+func waitRateLimit(ctx context.Context, wait time.Duration) error {
+	end := time.Now().Add(wait)
+	if !spinnerActive.Load() {
+		logf("⏳ Rate limited by the API; retrying in %s\n", wait.Round(time.Second))
+	}
+	defer spinnerNote.Store(nil)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		left := time.Until(end)
+		if left <= 0 {
+			return nil
+		}
+		note := fmt.Sprintf(" ⏳ rate limited, retrying in %s", max(left.Round(time.Second), time.Second))
+		spinnerNote.Store(&note)
+		select {
+		case <-ticker.C:
+		case <-time.After(left):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
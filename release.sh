@@ -16,6 +16,28 @@ if ! command -v gh &> /dev/null; then
     exit 1
 fi
 
+# Check for minisign, which signs checksums.txt for cc update
+if ! command -v minisign &> /dev/null; then
+    echo "❌ Error: minisign is not installed."
+    echo "Please install it: brew install minisign"
+    exit 1
+fi
+
+# The public key is built into the binaries; the secret key signs the release
+MINISIGN_PUB="${MINISIGN_PUB:-minisign.pub}"
+MINISIGN_KEY="${MINISIGN_KEY:-$HOME/.minisign/minisign.key}"
+if [ ! -f "$MINISIGN_PUB" ] || [ ! -f "$MINISIGN_KEY" ]; then
+    echo "❌ Error: Signing key not found ($MINISIGN_PUB, $MINISIGN_KEY)."
+    echo "Create a key pair with 'minisign -G' and commit minisign.pub, or set MINISIGN_PUB and MINISIGN_KEY."
+    exit 1
+fi
+PUBLIC_KEY=$(tail -n 1 "$MINISIGN_PUB")
+LDFLAGS="-X main.releasePublicKey=$PUBLIC_KEY"
+# Where opted-in users send anonymous usage events (none if unset)
+if [ -n "$TELEMETRY_ENDPOINT" ]; then
+    LDFLAGS="$LDFLAGS -X main.telemetryEndpoint=$TELEMETRY_ENDPOINT"
+fi
+
 # Determine version
 if [ -z "$1" ]; then
     echo "🔍 No version provided. Detecting latest tag..."
@@ -72,13 +94,17 @@ for PLATFORM in "${PLATFORMS[@]}"; do
     fi
 
     echo "🔨 Building for $OS/$ARCH..."
-    GOOS=$OS GOARCH=$ARCH go build -o "$OUTPUT_NAME" main.go
+    GOOS=$OS GOARCH=$ARCH go build -ldflags "$LDFLAGS" -o "$OUTPUT_NAME" .
 done
 
 # Generate checksums
 echo "🔐 Generating checksums..."
 cd dist
 shasum -a 256 cc-* > checksums.txt
+
+# Sign checksums (writes checksums.txt.minisig)
+echo "🔏 Signing checksums..."
+minisign -S -s "$MINISIGN_KEY" -m checksums.txt -t "cc $VERSION checksums"
 cd ..
 
 # 2. Generate Changelog
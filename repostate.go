@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// operationHints tells how to finish or back out of each in-progress operation
+var operationHints = map[string]string{
+	"rebase":      "git rebase --continue' or 'git rebase --abort",
+	"merge":       "git commit' or 'git merge --abort",
+	"cherry-pick": "git cherry-pick --continue' or 'git cherry-pick --abort",
+	"revert":      "git revert --continue' or 'git revert --abort",
+	"bisect":      "git bisect reset",
+}
+
+// ensureRepo checks that cc runs inside a git working tree before any git
+// command fails with its raw stderr, and offers to run `git init` when
+// someone can answer
+func ensureRepo(cfg *config.Config) error {
+	inside, err := git.IsInsideWorkTree(runCtx)
+	if errors.Is(err, exec.ErrNotFound) {
+		if cfg.GitBackend == "go-git" {
+			return nil // The go-git backend doesn't need the binary
+		}
+		return withExitCode(exitGitError, fmt.Errorf("git isn't installed or isn't on your PATH; install it from https://git-scm.com and try again"))
+	}
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("running git: %w", err))
+	}
+	if inside {
+		return nil
+	}
+
+	dir, _ := os.Getwd()
+	if !interactive() || git.ReadOnly {
+		return withExitCode(exitGitError, fmt.Errorf("%s is not inside a git repository; cd into one or run 'git init' first", dir))
+	}
+	printf("\n⚠️  %s is not inside a git repository.\n", dir)
+	ok, err := confirm("Run 'git init' here?")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return withExitCode(exitAborted, fmt.Errorf("not a git repository"))
+	}
+	if err := git.Init(runCtx); err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("git init: %w", err))
+	}
+	logf("✅ Initialized an empty git repository in %s\n", dir)
+	return nil
+}
+
+// checkRepoState refuses to commit in the middle of a rebase, merge,
+// cherry-pick, revert, or bisect, where a new commit would land somewhere
+// unexpected. On a detached HEAD, it offers to create a branch when
+// offerBranch is set and someone can answer; otherwise it refuses too.
+// It reports whether the user quit instead.
+func checkRepoState(offerBranch bool) (bool, error) {
+	if op := git.GetInProgressOperation(runCtx); op != "" {
+		return false, withExitCode(exitGitError, fmt.Errorf("a %s is in progress; finish it with '%s' before running cc", op, operationHints[op]))
+	}
+
+	if !git.IsDetached(runCtx) {
+		return false, nil
+	}
+	if !offerBranch || !interactive() {
+		return false, withExitCode(exitGitError, fmt.Errorf("HEAD is detached, so the commit wouldn't be on any branch; create one with 'git switch -c <name>' (or 'cc branch') first"))
+	}
+
+	printf("\n⚠️  HEAD is detached, so the commit wouldn't be on any branch.\n")
+	for {
+		name, err := ask(stdin, "🌿 Create a branch for it (empty to quit)", "")
+		if err != nil {
+			return false, err
+		}
+		if name == "" {
+			return true, nil
+		}
+		if err := git.CreateBranch(runCtx, name); err != nil {
+			printf("❌ Error creating branch: %v\n", err)
+			continue
+		}
+		logf("🌿 Switched to new branch %s\n", name)
+		return false, nil
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+)
+
+// handleReview reviews the uncommitted changes and prints the findings
+// without staging, committing, or pushing anything
+func handleReview(cfg *config.Config) {
+	printLine("🔍 Checking for changes...")
+	changes, err := collectChanges()
+	if err != nil {
+		printf("❌ Error: %v\n", err)
+		exit(exitGitError)
+	}
+
+	if len(changes.Files) == 0 || changes.Diff == "" {
+		printLine("✅ No changes to review.")
+		exit(exitNoChanges)
+	}
+
+	stopSpinner := startSpinner("🤖 Claude is reviewing your changes", changes.spinnerDetail())
+	review, err := claude.ReviewChanges(runCtx, changes.Diff, modelFor(cfg, reviewTask()), changes.UseSummaryMode)
+	stopSpinner()
+
+	if err != nil {
+		printf("❌ Error calling Claude: %v\n", err)
+		exit(exitModelErr)
+	}
+
+	review.Findings = append(review.Findings, testPolicyFindings(cfg, changes.Files)...)
+	printReview(review)
+
+	// A security review gates CI like a commit would
+	if claude.SecurityReview {
+		threshold, err := claude.ParseSeverity(cfg.BlockSeverity)
+		if err != nil {
+			printf("❌ Error invalid block_severity in config: %v\n", err)
+			exit(exitError)
+		}
+		if blocking := claude.Blocking(review.Findings, threshold); len(blocking) > 0 {
+			printf("\n🛑 %d finding(s) at or above %s severity.\n", len(blocking), threshold)
+			exit(exitBlocked)
+		}
+	}
+}
+
+// printReview prints a review's risk assessment, issues, and suggestions
+func printReview(review *claude.Review) {
+	riskIcon := map[string]string{"low": "🟢", "medium": "🟡", "high": "🔴"}[review.Risk]
+	if riskIcon == "" {
+		riskIcon = "⚪"
+	}
+	line := fmt.Sprintf(localize("\n%s Risk: %s\n"), riskIcon, review.Risk)
+	if output.Plain {
+		line = stripEmoji(line)
+	}
+	fmt.Print(line)
+
+	if review.Summary != "" {
+		printf("\n📋 Summary: %s\n", review.Summary)
+	}
+
+	if len(review.Findings) == 0 {
+		printLine("\n✅ No issues found.")
+	} else {
+		printf("\n⚠️  Findings (%d):\n", len(review.Findings))
+		printFindings(review.Findings)
+	}
+
+	if len(review.Suggestions) > 0 {
+		printf("\n💡 Suggestions (%d):\n", len(review.Suggestions))
+		for _, suggestion := range review.Suggestions {
+			printf("   - %s\n", suggestion)
+		}
+	}
+
+	printLine("\nℹ️  Review only: nothing was staged, committed, or pushed.")
+}
+
+// printFindings prints findings with an icon per severity
+func printFindings(findings []claude.Finding) {
+	for _, f := range findings {
+		icon := "🔵"
+		switch f.Severity {
+		case claude.SeverityCritical:
+			icon = "🔴"
+		case claude.SeverityWarning:
+			icon = "🟡"
+		}
+		if output.Plain {
+			icon = "-"
+		}
+		summaryf("   %s [%s] %s\n", icon, f.Severity, f.Description)
+		if f.Checklist != "" {
+			summaryf("      📋 Checklist: %s\n", f.Checklist)
+		}
+	}
+}
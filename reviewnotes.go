@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/debuglog"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// reviewNotesRef is where the review of each commit is kept as a git note
+const reviewNotesRef = "refs/notes/cc"
+
+// reviewNote is the text of the note for a commit's review: who reviewed
+// it, every finding, and whether blocking findings were overridden
+func reviewNote(report *commitReport, threshold claude.Severity, forced bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Reviewed by claude-commit %s (model %s", VERSION, report.Model)
+	if report.Mode != "" && report.Mode != "full" {
+		fmt.Fprintf(&b, ", %s mode", report.Mode)
+	}
+	fmt.Fprintf(&b, ") on %s\n\n", time.Now().UTC().Format(time.RFC3339))
+
+	if len(report.Findings) == 0 {
+		b.WriteString("No findings.\n")
+	} else {
+		fmt.Fprintf(&b, "Findings (%d):\n", len(report.Findings))
+		for _, f := range report.Findings {
+			fmt.Fprintf(&b, "- [%s] %s\n", f.Severity, f.Description)
+			if f.Checklist != "" {
+				fmt.Fprintf(&b, "  Checklist: %s\n", f.Checklist)
+			}
+		}
+	}
+	if blocking := claude.Blocking(report.Findings, threshold); forced && len(blocking) > 0 {
+		fmt.Fprintf(&b, "\nCommitted with --force despite %d finding(s) at or above %s severity.\n", len(blocking), threshold)
+	}
+	return b.String()
+}
+
+// saveReviewNote attaches the review to the new commit unless
+// no_review_notes is set. A failure only costs the note.
+func saveReviewNote(cfg *config.Config, report *commitReport, threshold claude.Severity, forced bool) {
+	if cfg.NoReviewNotes || report.CommitSHA == "" {
+		return
+	}
+	if err := git.AddNote(runCtx, reviewNotesRef, report.CommitSHA, reviewNote(report, threshold, forced)); err != nil {
+		logf("⚠️  Could not save the review as a git note: %v\n", err)
+		return
+	}
+	debuglog.Log("saved the review note", "commit", report.CommitSHA, "ref", reviewNotesRef)
+}
+
+// pushReviewNotes pushes refs/notes/cc to the push remote when
+// push_review_notes is set
+func pushReviewNotes(cfg *config.Config) {
+	if !cfg.PushReviewNotes || cfg.NoReviewNotes {
+		return
+	}
+	t, err := resolvePushTarget(cfg)
+	if err != nil {
+		return
+	}
+	logf("📝 Pushing review notes to %s\n", t.Remote)
+	if err := git.PushRef(runCtx, t.Remote, reviewNotesRef); err != nil {
+		logf("⚠️  Could not push the review notes (%v); a note ref changed on both sides can be merged with 'git notes --ref cc merge'.\n", err)
+	}
+}
+
+// handleShowReview prints the review note of a commit
+func handleShowReview(rev string) error {
+	note, err := git.GetNote(runCtx, reviewNotesRef, rev)
+	if err != nil {
+		return err
+	}
+	if note == "" {
+		return withExitCode(exitNoChanges, fmt.Errorf("%s has no review note (notes from others arrive with 'git fetch origin %s:%s')", rev, reviewNotesRef, reviewNotesRef))
+	}
+	printf("🔎 Review of %s:\n\n", shortSHA(git.ResolveCommit(runCtx, rev)))
+	fmt.Println(note)
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+)
+
+// compileRewrites turns the rewrite rules of the config into the rules of
+// the message format
+func compileRewrites(rules []config.RewriteRule) ([]claude.Rewrite, error) {
+	var rewrites []claude.Rewrite
+	for i, rule := range rules {
+		if rule.Find == "" {
+			return nil, fmt.Errorf("rewrites[%d]: find is empty", i)
+		}
+		find, err := regexp.Compile(rule.Find)
+		if err != nil {
+			return nil, fmt.Errorf("rewrites[%d]: %w", i, err)
+		}
+		switch rule.Case {
+		case "", "lower", "upper":
+		default:
+			return nil, fmt.Errorf("rewrites[%d]: unknown case %q (use lower or upper)", i, rule.Case)
+		}
+		rewrites = append(rewrites, claude.Rewrite{Find: find, Replace: rule.Replace, Case: rule.Case})
+	}
+	return rewrites, nil
+}
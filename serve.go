@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/cli"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcFailed         = -32000 // The method ran and failed; data.exitCode classifies it
+	rpcCanceled       = -32800 // Canceled with $/cancelRequest
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// rpcMethod handles one request; params is null when none were given
+type rpcMethod func(cfg *config.Config, params json.RawMessage) (any, error)
+
+// rpcMethods are the requests `cc serve` answers
+var rpcMethods = map[string]rpcMethod{
+	"getStatus":       rpcGetStatus,
+	"generateMessage": rpcGenerateMessage,
+	"commit":          rpcCommit,
+	"push":            rpcPush,
+}
+
+// errInvalidParams marks params that don't fit the method
+var errInvalidParams = errors.New("invalid params")
+
+func serveCommand(cfg *config.Config) *cli.Command {
+	stdio := false
+
+	return &cli.Command{
+		Name:  "serve",
+		Usage: "--stdio",
+		Short: "Answer JSON-RPC requests from an editor plugin",
+		Long: `Reads JSON-RPC 2.0 requests from stdin, one per line, and writes one response
+per line to stdout until stdin is closed. Methods: getStatus, generateMessage,
+commit, and push; $/cancelRequest cancels a running request. Progress and
+errors of the run go to stderr. The config is loaded once, at start.`,
+		NeedsRepo: true,
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&stdio, "stdio", false, "talk JSON-RPC over stdin and stdout (the only transport)")
+		},
+		Run: func(args []string) error {
+			if err := noArgs(args); err != nil {
+				return err
+			}
+			if !stdio {
+				return fmt.Errorf("cc serve needs --stdio")
+			}
+			return handleServe(cfg, os.Stdin, os.Stdout)
+		},
+	}
+}
+
+// handleServe answers requests from in on out, one at a time, until in is
+// closed or Ctrl+C is pressed
+func handleServe(cfg *config.Config, in io.Reader, out *os.File) error {
+	// Stdout belongs to the protocol; everything else cc prints goes to stderr,
+	// and nothing may wait for an answer on stdin
+	os.Stdout = os.Stderr
+	output.JSON = true
+	output.NoANSI = true
+
+	enc := json.NewEncoder(out)
+	var writeMu sync.Mutex
+	reply := func(resp rpcResponse) {
+		resp.JSONRPC = "2.0"
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if err := enc.Encode(resp); err != nil {
+			eprintf("⚠️  Writing a response: %v\n", err)
+		}
+	}
+
+	// Requests run one at a time; the reader keeps going so that a
+	// $/cancelRequest can reach the running one
+	var current struct {
+		sync.Mutex
+		id     string
+		cancel context.CancelFunc
+	}
+	requests := make(chan rpcRequest, 16)
+	go func() {
+		defer close(requests)
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				continue
+			}
+			var req rpcRequest
+			if err := json.Unmarshal(line, &req); err != nil {
+				reply(rpcResponse{ID: json.RawMessage("null"), Error: &rpcError{Code: rpcParseError, Message: err.Error()}})
+				continue
+			}
+			if req.Method == "$/cancelRequest" {
+				var params struct {
+					ID json.RawMessage `json:"id"`
+				}
+				_ = json.Unmarshal(req.Params, &params)
+				current.Lock()
+				if current.cancel != nil && current.id == string(params.ID) {
+					current.cancel()
+				}
+				current.Unlock()
+				continue
+			}
+			requests <- req
+		}
+	}()
+
+	baseCtx := runCtx
+	defer func() { runCtx = baseCtx }()
+	for {
+		var req rpcRequest
+		var ok bool
+		select {
+		case req, ok = <-requests:
+		case <-baseCtx.Done():
+			return nil
+		}
+		if !ok {
+			return nil
+		}
+
+		ctx, cancel := context.WithCancel(baseCtx)
+		current.Lock()
+		current.id, current.cancel = string(req.ID), cancel
+		current.Unlock()
+
+		// The commands of cc read runCtx, so each request gets its own
+		runCtx = ctx
+		resp := serveRequest(cfg, req)
+		runCtx = baseCtx
+
+		current.Lock()
+		current.id, current.cancel = "", nil
+		current.Unlock()
+		canceled := ctx.Err() != nil
+		cancel()
+
+		if len(req.ID) == 0 {
+			continue // A notification gets no response
+		}
+		if canceled && resp.Error != nil {
+			resp.Error = &rpcError{Code: rpcCanceled, Message: "request canceled"}
+		}
+		resp.ID = req.ID
+		reply(resp)
+		if baseCtx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// serveRequest runs one request and builds its response
+func serveRequest(cfg *config.Config, req rpcRequest) rpcResponse {
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return rpcResponse{Error: &rpcError{Code: rpcInvalidRequest, Message: "not a JSON-RPC 2.0 request"}}
+	}
+	method, ok := rpcMethods[req.Method]
+	if !ok {
+		return rpcResponse{Error: &rpcError{Code: rpcMethodNotFound, Message: "unknown method " + req.Method}}
+	}
+	result, err := method(cfg, req.Params)
+	if errors.Is(err, errInvalidParams) {
+		return rpcResponse{Error: &rpcError{Code: rpcInvalidParams, Message: err.Error()}}
+	}
+	if err != nil {
+		data := map[string]int{"exitCode": exitCodeOf(err)}
+		return rpcResponse{Error: &rpcError{Code: rpcFailed, Message: err.Error(), Data: data}}
+	}
+	return rpcResponse{Result: result}
+}
+
+// decodeParams unmarshals params into v; missing params leave v as it is
+func decodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidParams, err)
+	}
+	return nil
+}
+
+// rpcStatus is the result of getStatus
+type rpcStatus struct {
+	Version string   `json:"version"`
+	Model   string   `json:"model"`
+	Branch  string   `json:"branch"`
+	Changed []string `json:"changed"` // Every changed file, staged or not
+	Staged  []string `json:"staged"`
+}
+
+func rpcGetStatus(cfg *config.Config, params json.RawMessage) (any, error) {
+	status := rpcStatus{Version: VERSION, Model: cfg.Model}
+	var err error
+	if status.Branch, err = git.GetCurrentBranch(runCtx); err != nil {
+		return nil, withExitCode(exitGitError, err)
+	}
+	if status.Changed, err = git.GetChangedFiles(runCtx); err != nil {
+		return nil, withExitCode(exitGitError, err)
+	}
+	if status.Staged, err = git.GetStagedFiles(runCtx); err != nil {
+		return nil, withExitCode(exitGitError, err)
+	}
+	if status.Changed == nil {
+		status.Changed = []string{}
+	}
+	if status.Staged == nil {
+		status.Staged = []string{}
+	}
+	return status, nil
+}
+
+// rpcMessage is the result of generateMessage
+type rpcMessage struct {
+	Message  string           `json:"message"`
+	Findings []claude.Finding `json:"findings"`
+	Blocked  bool             `json:"blocked"` // A finding is at or above block_severity
+	Mode     string           `json:"mode"`    // "full", "hybrid", or "summary"
+}
+
+func rpcGenerateMessage(cfg *config.Config, params json.RawMessage) (any, error) {
+	var p struct {
+		Staged bool   `json:"staged"` // Only the staged changes
+		Hint   string `json:"hint"`   // Extra guidance for the message
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	threshold, err := claude.ParseSeverity(cfg.BlockSeverity)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block_severity in config: %w", err)
+	}
+
+	var changes *changeSet
+	if p.Staged {
+		changes, err = collectStagedChanges()
+	} else {
+		changes, err = collectChanges()
+	}
+	if err != nil {
+		return nil, withExitCode(exitGitError, err)
+	}
+	if len(changes.Files) == 0 || changes.Diff == "" {
+		return nil, withExitCode(exitNoChanges, errors.New("no changes to commit"))
+	}
+
+	result, err := reviewAndMessage(withModel(cfg, modelFor(cfg, taskReview)), commitOptions{}, changes, p.Hint)
+	if err != nil {
+		return nil, withExitCode(exitModelErr, fmt.Errorf("calling Claude: %w", err))
+	}
+	msg := rpcMessage{
+		Message:  finishMessage(cfg, result.Message, claude.LastModel()),
+		Findings: append([]claude.Finding{}, result.Findings...),
+		Blocked:  len(result.Blocking(threshold)) > 0,
+		Mode:     changes.mode(),
+	}
+	return msg, nil
+}
+
+func rpcCommit(cfg *config.Config, params json.RawMessage) (any, error) {
+	var p struct {
+		Message string `json:"message"`
+		Staged  bool   `json:"staged"` // Commit the index as is instead of staging everything
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Message == "" {
+		return nil, fmt.Errorf("%w: message is required", errInvalidParams)
+	}
+	if !p.Staged {
+		if err := git.StageAll(runCtx); err != nil {
+			return nil, withExitCode(exitGitError, fmt.Errorf("staging changes: %w", err))
+		}
+	}
+	if err := git.Commit(runCtx, p.Message, cfg.SignOff); err != nil {
+		return nil, withExitCode(exitGitError, fmt.Errorf("committing: %w", err))
+	}
+	sha, err := git.GetHeadSHA(runCtx)
+	if err != nil {
+		return nil, withExitCode(exitGitError, err)
+	}
+	return map[string]string{"sha": sha}, nil
+}
+
+func rpcPush(cfg *config.Config, params json.RawMessage) (any, error) {
+	var p struct {
+		SetUpstream bool `json:"setUpstream"` // Push a branch without upstream and track it
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	pushCfg := *cfg
+	pushCfg.SetUpstream = cfg.SetUpstream || p.SetUpstream
+	pushed, err := pushBranch(&pushCfg, false, false)
+	if err != nil {
+		return nil, withExitCode(exitPushError, fmt.Errorf("pushing: %w", err))
+	}
+	return map[string]bool{"pushed": pushed}, nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/config"
+)
+
+// needsSetup reports whether this is the first run: there is no config file
+// yet and someone is at the terminal to answer the questions
+func needsSetup() bool {
+	return !config.Exists() && interactive()
+}
+
+// handleSetup walks through the main settings and saves them to the global
+// config. Existing values are offered as the defaults.
+func handleSetup(cfg *config.Config) error {
+	saved := config.Default()
+	if config.Exists() {
+		var err error
+		if saved, err = config.Load(); err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+	}
+
+	reader := stdin
+	printf("👋 Welcome to claude-commit! Let's set up a few defaults (press Enter to keep the value in brackets).\n")
+
+	// Provider
+	printf("\n🔌 Provider: Claude Code CLI (claude)\n")
+	if _, err := exec.LookPath("claude"); err != nil {
+		printf("⚠️  claude was not found in your PATH. Install it from https://github.com/anthropics/claude-code before committing.\n")
+	} else {
+		printf("✅ Found claude in your PATH\n")
+	}
+
+	// Model
+	models := []string{"haiku", "sonnet", "opus"}
+	printf("\n🤖 Which model should review your changes?\n")
+	for i, m := range models {
+		printf("  %d. %s\n", i+1, m)
+	}
+	printf("  %d. Custom...\n", len(models)+1)
+	choice, err := ask(reader, "Model", saved.Model)
+	if err != nil {
+		return err
+	}
+	if idx, err := strconv.Atoi(choice); err == nil && idx >= 1 && idx <= len(models) {
+		saved.Model = models[idx-1]
+	} else if idx == len(models)+1 {
+		if saved.Model, err = ask(reader, "Custom model name", saved.Model); err != nil {
+			return err
+		}
+	} else {
+		saved.Model = choice
+	}
+
+	// Push behavior
+	push, err := askYesNo(reader, "\n📤 Push automatically after each commit?", !saved.NoPush)
+	if err != nil {
+		return err
+	}
+	saved.NoPush = !push
+
+	// Commit style
+	if saved.MessageBody, err = askYesNo(reader, "\n📝 Add a body below the subject line?", saved.MessageBody || saved.BodyLanguage != ""); err != nil {
+		return err
+	}
+	if saved.SubjectLanguage, err = ask(reader, "🌐 Subject language (empty for English)", saved.SubjectLanguage); err != nil {
+		return err
+	}
+	saved.BodyLanguage = ""
+	if saved.MessageBody {
+		if saved.BodyLanguage, err = ask(reader, "🌐 Body language (empty to match the subject)", saved.SubjectLanguage); err != nil {
+			return err
+		}
+	}
+
+	if err := config.Save(saved); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+
+	configDir, _ := config.GetConfigDir()
+	printf("\n✅ Saved to %s. Change it any time with 'cc setup' or 'cc config set'.\n\n", filepath.Join(configDir, config.ConfigFileName))
+
+	// Use the answers for the rest of this run
+	cfg.Model = saved.Model
+	cfg.NoPush = saved.NoPush
+	cfg.MessageBody = saved.MessageBody
+	cfg.SubjectLanguage = saved.SubjectLanguage
+	cfg.BodyLanguage = saved.BodyLanguage
+	return nil
+}
+
+// ask prints a prompt with a default value and returns the answer, or the
+// default if the answer is empty
+func ask(reader *bufio.Reader, prompt string, def string) (string, error) {
+	printf("%s [%s]: ", localize(prompt), def)
+	answer, err := readLine(reader)
+	if err != nil {
+		return "", fmt.Errorf("reading input: %w", err)
+	}
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// askYesNo asks a yes/no question with a default answer
+func askYesNo(reader *bufio.Reader, question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	printf("%s (%s): ", localize(question), hint)
+	answer, err := readLine(reader)
+	if err != nil {
+		return false, fmt.Errorf("reading input: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "":
+		return def, nil
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
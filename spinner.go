@@ -0,0 +1,61 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// startSpinner prints an animated spinner and the elapsed time after text
+// until the returned stop function is called, which finishes the line with
+// "... ✅" and the time taken
+func startSpinner(text string, detail string) func() {
+	if output.JSON || output.Quiet {
+		return func() {}
+	}
+	text = localize(text)
+	if output.NoANSI {
+		printf("%s%s...\n", text, detail)
+		return func() {}
+	}
+
+	var wg sync.WaitGroup
+	stopSpinner := make(chan struct{})
+	wg.Add(1)
+	start := time.Now()
+	spinnerActive.Store(true)
+	go func() {
+		defer wg.Done()
+		defer spinnerActive.Store(false)
+		spinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+		i := 0
+
+		for {
+			select {
+			case <-stopSpinner:
+				printf("\r%s... ✅ %s\n", text, formatElapsed(time.Since(start)))
+				return
+			case <-runCtx.Done():
+				// Finish the line so the shell prompt doesn't land on it
+				printf("\r%s... ⏹️  interrupted\033[K\n", text)
+				return
+			default:
+				note := ""
+				if p := spinnerNote.Load(); p != nil {
+					note = *p
+				}
+				printf("\r%s%s %s %s%s ", text, detail, spinner[i%len(spinner)], formatElapsed(time.Since(start)), note)
+
+				// Clear to end of line
+				printf("\033[K")
+
+				i++
+				time.Sleep(100 * time.Millisecond)
+			}
+		}
+	}()
+
+	return func() {
+		close(stopSpinner)
+		wg.Wait()
+	}
+}
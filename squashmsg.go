@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/clipboard"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// squashMessage asks Claude for the message of a squash merge of the
+// commits in base..head, from their messages and combined diff
+func squashMessage(cfg *config.Config, base, head string) (string, error) {
+	files, err := git.GetRangeChangedFiles(runCtx, base, head)
+	if err != nil {
+		return "", withExitCode(exitGitError, fmt.Errorf("getting changed files: %w", err))
+	}
+	if len(files) == 0 {
+		return "", withExitCode(exitNoChanges, fmt.Errorf("the commits since %s change no files", shortSHA(base)))
+	}
+	diff, mode, err := git.ChooseDiff(len(files), func(summary bool) (string, error) {
+		return git.GetRangeDiff(runCtx, base, head, summary)
+	})
+	if err != nil {
+		return "", withExitCode(exitGitError, fmt.Errorf("getting diff: %w", err))
+	}
+	log, err := git.GetRangeMessages(runCtx, base, head)
+	if err != nil {
+		return "", withExitCode(exitGitError, fmt.Errorf("reading commits: %w", err))
+	}
+
+	format := messageFormat(cfg, "")
+	// One commit stands in for several, so it always explains itself
+	format.Body = true
+	stopSpinner := startSpinner("🤖 Claude is writing the squash message", fmt.Sprintf(" (%d files)", len(files)))
+	message, err := claude.SquashMessage(runCtx, log, diff, modelFor(cfg, taskMessage), mode == git.SummaryDiff, format)
+	stopSpinner()
+	if err != nil {
+		return "", withExitCode(exitModelErr, fmt.Errorf("calling Claude: %w", err))
+	}
+	return finishMessage(cfg, message, claude.LastModel()), nil
+}
+
+// handleSquashMsg prints (or copies) the squash merge message for the
+// commits of the current branch since it forked from base
+func handleSquashMsg(cfg *config.Config, base string, copyToClipboard bool) error {
+	if base == "" {
+		base = git.GetDefaultBranch(runCtx)
+	}
+	baseRef, err := git.ResolveBase(runCtx, base)
+	if err != nil {
+		return withExitCode(exitGitError, err)
+	}
+	forkPoint, err := git.MergeBase(runCtx, baseRef, "HEAD")
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("finding the merge base of %s and HEAD: %w", baseRef, err))
+	}
+	if head, _ := git.GetHeadSHA(runCtx); head == forkPoint {
+		summaryf("✅ No commits on this branch since %s.\n", baseRef)
+		exit(exitNoChanges)
+	}
+
+	logf("🔍 Reading the commits since %s...\n", baseRef)
+	message, err := squashMessage(cfg, forkPoint, "HEAD")
+	if err != nil {
+		return err
+	}
+	if copyToClipboard {
+		if err := clipboard.Copy(message); err != nil {
+			return fmt.Errorf("copying to clipboard: %w", err)
+		}
+		printf("📋 Squash message copied to clipboard.\n")
+		return nil
+	}
+	logln()
+	fmt.Println(message)
+	return nil
+}
+
+// handleMergeSquash squash merges branch into the current branch with a
+// message Claude writes from the branch's commits
+func handleMergeSquash(cfg *config.Config, branch string) error {
+	if _, err := checkRepoState(false); err != nil {
+		return err
+	}
+	tip := git.ResolveCommit(runCtx, branch)
+	if tip == "" {
+		return withExitCode(exitGitError, fmt.Errorf("unknown branch %q", branch))
+	}
+	// The squash commit takes the whole index, so staged changes would sneak in
+	staged, err := git.GetStagedFiles(runCtx)
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("getting staged files: %w", err))
+	}
+	if len(staged) > 0 {
+		return fmt.Errorf("%d file(s) are staged; commit or unstage them before merging", len(staged))
+	}
+	forkPoint, err := git.MergeBase(runCtx, "HEAD", tip)
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("finding the merge base of HEAD and %s: %w", branch, err))
+	}
+	if forkPoint == tip {
+		summaryf("✅ %s is already merged.\n", branch)
+		exit(exitNoChanges)
+	}
+	if !interactive() && !output.Yes {
+		return withExitCode(exitAborted, fmt.Errorf("merging adds a commit to the current branch; rerun with --yes to confirm without a terminal"))
+	}
+
+	logf("🔍 Reading the commits of %s...\n", branch)
+	message, err := squashMessage(cfg, forkPoint, tip)
+	if err != nil {
+		return err
+	}
+	summaryf("\n📝 Commit message:\n%s\n", message)
+
+	ok, err := confirm(fmt.Sprintf("Squash merge %s with this message?", branch))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		summaryf("❌ Aborted. Nothing was merged.\n")
+		exit(exitAborted)
+	}
+
+	indexTree := indexBeforeStaging()
+	if err := git.MergeSquash(runCtx, branch); err != nil {
+		conflicts, _ := git.GetUnmergedFiles(runCtx)
+		if len(conflicts) == 0 {
+			return withExitCode(exitGitError, fmt.Errorf("merging %s: %w", branch, err))
+		}
+		// git commit picks the message up from SQUASH_MSG once the conflicts are resolved
+		path, err := git.GetGitPath(runCtx, "SQUASH_MSG")
+		if err == nil {
+			err = os.WriteFile(path, []byte(message+"\n"), 0o644)
+		}
+		if err != nil {
+			eprintf("⚠️  Warning: Could not save the message for git commit: %v\n", err)
+		}
+		return withExitCode(exitGitError, fmt.Errorf("merging %s left %d conflicted file(s) (%s); resolve them, stage the files, and run 'git commit' to use the generated message", branch, len(conflicts), strings.Join(conflicts, ", ")))
+	}
+	if err := git.Commit(runCtx, message, cfg.SignOff); err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("committing: %w", err))
+	}
+	recordCommit(indexTree)
+	sha, _ := git.GetHeadSHA(runCtx)
+	summaryf("\n✨ Squash merged %s as %s.\n", branch, shortSHA(sha))
+	return nil
+}
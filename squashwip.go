@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// maxWIPRun bounds how far back cc squash-wip looks for WIP commits
+const maxWIPRun = 100
+
+// handleSquashWIP squashes the WIP commits at the tip of the current branch
+// into one commit with a message Claude writes from their combined diff.
+// Commits already pushed are left alone, so no force push is needed.
+func handleSquashWIP(cfg *config.Config) error {
+	if _, err := checkRepoState(false); err != nil {
+		return err
+	}
+
+	commits, err := git.GetCommits(runCtx, maxWIPRun)
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("reading commits: %w", err))
+	}
+	unpushed, err := git.GetUnpushedCommits(runCtx)
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("checking for pushed commits: %w", err))
+	}
+	isUnpushed := make(map[string]bool, len(unpushed))
+	for _, sha := range unpushed {
+		isUnpushed[sha] = true
+	}
+
+	var run []git.CommitInfo
+	stoppedAtPushed := false
+	for _, c := range commits {
+		if !isWIP(c.Message) || len(c.Parents) != 1 {
+			break
+		}
+		if !isUnpushed[c.SHA] {
+			stoppedAtPushed = true
+			break
+		}
+		run = append(run, c)
+	}
+	if len(run) == 0 {
+		if stoppedAtPushed {
+			summaryf("✅ The WIP commits at the tip of this branch are already pushed; squashing them would need a force push.\n")
+		} else {
+			summaryf("✅ No WIP commits at the tip of this branch.\n")
+		}
+		exit(exitNoChanges)
+	}
+
+	// The squashed commit gets HEAD's tree, so staged changes would sneak in
+	staged, err := git.GetStagedFiles(runCtx)
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("getting staged files: %w", err))
+	}
+	if len(staged) > 0 {
+		return fmt.Errorf("%d file(s) are staged; commit or unstage them before squashing", len(staged))
+	}
+
+	if !interactive() && !output.Yes {
+		return withExitCode(exitAborted, fmt.Errorf("squashing rewrites commits; rerun with --yes to confirm without a terminal"))
+	}
+
+	head, base := run[0].SHA, run[len(run)-1].Parents[0]
+	logf("🧹 Squashing %d WIP commit(s):\n", len(run))
+	for _, c := range run {
+		subject, _ := claude.SplitMessage(c.Message)
+		logf("   %s %s\n", shortSHA(c.SHA), subject)
+	}
+	if stoppedAtPushed {
+		logf("   (older WIP commits are already pushed and stay as they are)\n")
+	}
+
+	files, err := git.GetRangeChangedFiles(runCtx, base, head)
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("getting changed files: %w", err))
+	}
+	diff, mode, err := git.ChooseDiff(len(files), func(summary bool) (string, error) {
+		return git.GetRangeDiff(runCtx, base, head, summary)
+	})
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("getting diff: %w", err))
+	}
+
+	format := messageFormat(cfg, "")
+	stopSpinner := startSpinner("🤖 Claude is reviewing the combined changes", fmt.Sprintf(" (%d files)", len(files)))
+	result, err := claude.ReviewAndCommitMessage(runCtx, diff, modelFor(cfg, taskReview), mode == git.SummaryDiff, format, nil)
+	stopSpinner()
+	if err != nil {
+		return withExitCode(exitModelErr, fmt.Errorf("calling Claude: %w", err))
+	}
+	if len(result.Findings) > 0 {
+		summaryf("\n🔎 Claude's findings (%d):\n", len(result.Findings))
+		printFindings(result.Findings)
+	}
+	if result.Message == "" {
+		return withExitCode(exitModelErr, fmt.Errorf("Claude reported a critical finding instead of a commit message; nothing was squashed"))
+	}
+
+	message := finishMessage(cfg, result.Message, claude.LastModel())
+	summaryf("\n📝 Commit message:\n%s\n", message)
+
+	ok, err := confirm(fmt.Sprintf("Squash %d commit(s) into one with this message?", len(run)))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		summaryf("❌ Aborted. No commits were changed.\n")
+		exit(exitAborted)
+	}
+
+	if err := git.ResetSoft(runCtx, base); err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("resetting to %s: %w", shortSHA(base), err))
+	}
+	if err := git.Commit(runCtx, message, cfg.SignOff); err != nil {
+		// Put the WIP commits back rather than leave their changes staged
+		if resetErr := git.ResetSoft(context.WithoutCancel(runCtx), head); resetErr != nil {
+			eprintf("⚠️  Warning: Could not restore %s: %v\n", shortSHA(head), resetErr)
+		}
+		return withExitCode(exitGitError, fmt.Errorf("committing: %w", err))
+	}
+	sha, _ := git.GetHeadSHA(runCtx)
+	summaryf("\n✨ Squashed %d WIP commit(s) into %s.\n", len(run), shortSHA(sha))
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// handleStash stashes every change, untracked files included, with a
+// description from the cheap model instead of git's "WIP on <branch>"
+func handleStash(cfg *config.Config) error {
+	files, err := git.GetChangedFiles(runCtx)
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("getting changed files: %w", err))
+	}
+	if len(files) == 0 {
+		summaryf("✅ No changes to stash.\n")
+		exit(exitNoChanges)
+	}
+
+	summary, err := git.GetDiffSummary(runCtx)
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("getting git diff summary: %w", err))
+	}
+
+	model := cfg.WIPModel
+	if model == "" {
+		model = config.DefaultModel
+	}
+	stopSpinner := startSpinner("🤖 Describing your changes", fmt.Sprintf(" (%d files)", len(files)))
+	description, err := claude.WIPDescription(runCtx, summary, model)
+	stopSpinner()
+	if err != nil {
+		// The stash is worth more than its description
+		eprintf("⚠️  Warning: Could not describe the changes: %v\n", err)
+		description = fmt.Sprintf("%d changed files", len(files))
+	}
+
+	if err := git.StashPush(runCtx, description); err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("stashing changes: %w", err))
+	}
+	summaryf("📦 Stashed: %s (restore with 'git stash pop')\n", description)
+	return nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/debuglog"
+	"github.com/quaywin/claude-commit/internal/history"
+)
+
+// modelPrices are list prices in USD per million input and output tokens,
+// used to estimate the cost of calls the CLI reported no cost for
+var modelPrices = map[string][2]float64{
+	"haiku":  {1, 5},
+	"sonnet": {3, 15},
+	"opus":   {5, 25},
+}
+
+// estimateCost returns the reported cost of usage, or an estimate from
+// modelPrices. Cache reads cost a tenth of input tokens, cache writes 1.25x.
+func estimateCost(model string, usage claude.Usage) (cost float64, estimated bool) {
+	if usage.CostUSD > 0 {
+		return usage.CostUSD, false
+	}
+	for name, price := range modelPrices {
+		if strings.Contains(strings.ToLower(model), name) {
+			input := float64(usage.InputTokens) + 0.1*float64(usage.CacheReadTokens) + 1.25*float64(usage.CacheCreationTokens)
+			return (input*price[0] + float64(usage.OutputTokens)*price[1]) / 1e6, true
+		}
+	}
+	return 0, true
+}
+
+// logUsage records the usage of every claude call for `cc stats`, except
+// in demo mode, which writes nothing
+func logUsage(cfg *config.Config, command string) {
+	if cfg.NoHistory || config.ReadOnly {
+		return
+	}
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return
+	}
+	path := filepath.Join(dir, history.UsageFileName)
+	claude.OnUsage = func(model string, usage claude.Usage) {
+		entry := history.UsageEntry{Time: time.Now(), Command: command, Model: model, Usage: usage}
+		if err := history.AppendUsage(path, entry); err != nil {
+			debuglog.Log("writing usage log", "error", err)
+		}
+	}
+}
+
+// usageRow is the usage of one model on one day
+type usageRow struct {
+	Day   string `json:"day,omitempty"`
+	Model string `json:"model"`
+	claude.Usage
+	Cost      float64 `json:"estimated_cost_usd"`
+	Estimated bool    `json:"estimated"` // Some of the cost came from modelPrices
+}
+
+// handleStats prints token usage and spend per day and model for the last days
+func handleStats(days int) error {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return err
+	}
+	entries, err := history.LoadUsage(filepath.Join(dir, history.UsageFileName))
+	if err != nil {
+		return err
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	daily := map[[2]string]*usageRow{}
+	perModel := map[string]*usageRow{}
+	for _, entry := range entries {
+		if days > 0 && entry.Time.Before(since) {
+			continue
+		}
+		cost, estimated := estimateCost(entry.Model, entry.Usage)
+		day := entry.Time.Local().Format("2006-01-02")
+		for _, row := range []*usageRow{
+			rowFor(daily, [2]string{day, entry.Model}),
+			rowFor(perModel, entry.Model),
+		} {
+			row.Usage.Add(entry.Usage)
+			row.Cost += cost
+			row.Model = entry.Model
+			row.Estimated = row.Estimated || estimated
+		}
+		daily[[2]string{day, entry.Model}].Day = day
+	}
+
+	rows := sortedRows(daily)
+	totals := sortedRows(perModel)
+	if output.JSON {
+		printJSON(map[string][]usageRow{"daily": rows, "models": totals})
+		return nil
+	}
+
+	period := fmt.Sprintf("last %d days", days)
+	if days <= 0 {
+		period = "all time"
+	}
+	if len(rows) == 0 {
+		printf("📊 No Claude usage recorded (%s).\n", period)
+		return nil
+	}
+
+	printf("📊 Claude usage, %s\n\n", period)
+	printf("%-10s  %-10s  %5s  %9s  %9s  %9s\n", "Day", "Model", "Calls", "Input", "Output", "Cost")
+	for _, row := range rows {
+		printf("%-10s  %-10s  %5d  %9s  %9s  %9s\n", row.Day, row.Model, row.Calls, formatTokens(inputTokens(row.Usage)), formatTokens(row.OutputTokens), formatCost(row.Cost, row.Estimated))
+	}
+
+	printf("\nBy model:\n")
+	total, estimated := 0.0, false
+	for _, row := range totals {
+		printf("   %-10s %5d calls  %9s in  %9s out  %9s\n", row.Model, row.Calls, formatTokens(inputTokens(row.Usage)), formatTokens(row.OutputTokens), formatCost(row.Cost, row.Estimated))
+		total += row.Cost
+		estimated = estimated || row.Estimated
+	}
+	printf("\n💰 Total: %s\n", formatCost(total, estimated))
+	if estimated {
+		printf("   ~ marks estimates from list prices, for calls the claude CLI reported no cost for.\n")
+	}
+	return nil
+}
+
+// rowFor returns the row for key, creating it
+func rowFor[K comparable](rows map[K]*usageRow, key K) *usageRow {
+	if rows[key] == nil {
+		rows[key] = &usageRow{}
+	}
+	return rows[key]
+}
+
+// sortedRows orders rows by day, then model
+func sortedRows[K comparable](rows map[K]*usageRow) []usageRow {
+	sorted := []usageRow{}
+	for _, row := range rows {
+		sorted = append(sorted, *row)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Day != sorted[j].Day {
+			return sorted[i].Day < sorted[j].Day
+		}
+		return sorted[i].Model < sorted[j].Model
+	})
+	return sorted
+}
+
+// inputTokens counts every input token, cached or not
+func inputTokens(usage claude.Usage) int {
+	return usage.InputTokens + usage.CacheReadTokens + usage.CacheCreationTokens
+}
+
+// formatTokens abbreviates a token count, e.g. "12.3k"
+func formatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// formatCost formats dollars, marking estimates with ~
+func formatCost(cost float64, estimated bool) string {
+	prefix := ""
+	if estimated {
+		prefix = "~"
+	}
+	return fmt.Sprintf("%s$%.4f", prefix, cost)
+}
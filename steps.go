@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// stepTiming is how long one stage of a run took
+type stepTiming struct {
+	Step         string `json:"step"`
+	Milliseconds int64  `json:"ms"`
+}
+
+// stepClock times the stages of a run (diff → review → stage → commit →
+// push), so a slow run shows whether git or the model took the time
+type stepClock struct {
+	start   time.Time
+	current string
+	begun   time.Time
+	steps   []stepTiming
+}
+
+func newStepClock() *stepClock {
+	return &stepClock{start: time.Now()}
+}
+
+// begin ends the current step and starts step. A step that runs again, such
+// as a regenerated review, adds to its earlier time.
+func (c *stepClock) begin(step string) {
+	c.end()
+	c.current = step
+	c.begun = time.Now()
+}
+
+// end stops the current step
+func (c *stepClock) end() {
+	if c.current == "" {
+		return
+	}
+	step, elapsed := c.current, time.Since(c.begun).Milliseconds()
+	c.current = ""
+	for i := range c.steps {
+		if c.steps[i].Step == step {
+			c.steps[i].Milliseconds += elapsed
+			return
+		}
+	}
+	c.steps = append(c.steps, stepTiming{Step: step, Milliseconds: elapsed})
+}
+
+// summary lists the time of each step and the total, e.g.
+// "diff 0.1s → review 4.2s → commit 0.1s (total 4.5s)"
+func (c *stepClock) summary() string {
+	c.end()
+	parts := make([]string, len(c.steps))
+	for i, s := range c.steps {
+		parts[i] = fmt.Sprintf("%s %s", s.Step, formatElapsed(time.Duration(s.Milliseconds)*time.Millisecond))
+	}
+	return fmt.Sprintf("%s (total %s)", strings.Join(parts, " → "), formatElapsed(time.Since(c.start)))
+}
+
+// formatElapsed shows a duration to a tenth of a second, or in minutes and
+// seconds from a minute on
+func formatElapsed(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return d.Round(time.Second).String()
+}
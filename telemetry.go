@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/debuglog"
+	"github.com/quaywin/claude-commit/internal/telemetry"
+)
+
+// telemetryEndpoint receives the usage events of users who opted in.
+// release.sh sets it with -ldflags; CC_TELEMETRY_ENDPOINT overrides it.
+// Without an endpoint nothing is sent.
+var telemetryEndpoint = ""
+
+// telemetryTimeout bounds how long sending an event may delay the exit
+const telemetryTimeout = 2 * time.Second
+
+// telemetryRun is the run being measured; nil unless telemetry is on
+var telemetryRun *struct {
+	cfg     *config.Config
+	command string
+	start   time.Time
+}
+
+// endpoint returns where events go, or "" if nowhere
+func endpoint() string {
+	if url := os.Getenv("CC_TELEMETRY_ENDPOINT"); url != "" {
+		return url
+	}
+	return telemetryEndpoint
+}
+
+// optedIn reports whether the user turned telemetry on. Only the global
+// config counts, which is what `cc telemetry on` writes: a profile or a
+// repository can't opt anyone in.
+func optedIn() bool {
+	saved, err := config.Load()
+	return err == nil && saved.Telemetry
+}
+
+// startTelemetry starts measuring command if the user opted in
+func startTelemetry(cfg *config.Config, command string) {
+	if telemetry.DisabledByEnv() || endpoint() == "" || !optedIn() {
+		return
+	}
+	telemetryRun = &struct {
+		cfg     *config.Config
+		command string
+		start   time.Time
+	}{cfg, command, time.Now()}
+}
+
+// exit reports the run, if telemetry is on, and exits with code
+func exit(code int) {
+	reportTelemetry(code)
+	os.Exit(code)
+}
+
+// reportTelemetry sends the event of the measured run. Failures are only
+// logged: telemetry never affects the outcome of a command.
+func reportTelemetry(code int) {
+	run := telemetryRun
+	if run == nil {
+		return
+	}
+	telemetryRun = nil
+
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return
+	}
+	getID := telemetry.InstallID
+	if config.ReadOnly {
+		// Demo mode writes nothing, not even a first install ID
+		getID = telemetry.StoredInstallID
+	}
+	id, err := getID(dir)
+	if err != nil || id == "" {
+		debuglog.Log("telemetry install id", "error", err)
+		return
+	}
+	event := telemetry.Event{
+		InstallID:  id,
+		Version:    VERSION,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Command:    run.command,
+		Model:      run.cfg.Model,
+		DurationMS: time.Since(run.start).Milliseconds(),
+		Success:    code == exitOK || code == exitNoChanges,
+		ExitCode:   code,
+	}
+	if err := telemetry.Send(endpoint(), event, telemetryTimeout); err != nil {
+		debuglog.Log("sending telemetry", "error", err)
+	}
+}
+
+// handleTelemetry turns telemetry on or off, or shows its status
+func handleTelemetry(cfg *config.Config, args []string) error {
+	action := "status"
+	if len(args) > 0 {
+		action = args[0]
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("usage: cc telemetry [on | off | status]")
+	}
+
+	switch action {
+	case "on", "off":
+		saved, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		saved.Telemetry = action == "on"
+		if err := config.Save(saved); err != nil {
+			return fmt.Errorf("saving config: %w", err)
+		}
+		cfg.Telemetry = saved.Telemetry
+		if saved.Telemetry {
+			printf("✅ Telemetry is on. Thank you! This helps decide which features matter.\n")
+			printTelemetryDetails()
+		} else {
+			telemetryRun = nil // Not even this run
+			printf("✅ Telemetry is off. Nothing will be sent.\n")
+		}
+		return nil
+	case "status":
+		switch {
+		case telemetry.DisabledByEnv():
+			printf("📴 Telemetry is off (DO_NOT_TRACK or CC_TELEMETRY in the environment).\n")
+		case !optedIn():
+			printf("📴 Telemetry is off. Turn it on with 'cc telemetry on'.\n")
+		case endpoint() == "":
+			printf("📴 Telemetry is on, but this build has no endpoint, so nothing is sent.\n")
+		default:
+			printf("📡 Telemetry is on, sending to %s.\n", endpoint())
+		}
+		printTelemetryDetails()
+		return nil
+	}
+	return fmt.Errorf("unknown telemetry action %q (use on, off, or status)", action)
+}
+
+func printTelemetryDetails() {
+	printf("\nEach run sends: the command, its duration, the model, whether it succeeded and\n")
+	printf("its exit code, the cc version, your OS and architecture, and a random install ID.\n")
+	printf("Never file names, diffs, commit messages, findings, or repository details.\n")
+}
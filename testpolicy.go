@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// defaultTestRules are the tests_required rules unless the config has its own
+var defaultTestRules = []config.TestRule{
+	{Source: "**/*.go", Tests: []string{"{dir}/*_test.go"}},
+	{Source: "**/*.py", Tests: []string{"**/test_{name}.py", "**/{name}_test.py"}},
+	{Source: "**/*.js", Tests: jsTests},
+	{Source: "**/*.jsx", Tests: jsTests},
+	{Source: "**/*.ts", Tests: jsTests},
+	{Source: "**/*.tsx", Tests: jsTests},
+}
+
+var jsTests = []string{"**/{name}.test.*", "**/{name}.spec.*", "**/__tests__/{name}.*"}
+
+// testPolicyFindings checks the tests_required policy against the changed
+// files, returning a finding for the source files that changed without any
+// of their tests
+func testPolicyFindings(cfg *config.Config, files []string) []claude.Finding {
+	policy := cfg.TestsRequired
+	if policy.Action == "" {
+		return nil
+	}
+	rules := policy.Rules
+	if len(rules) == 0 {
+		rules = defaultTestRules
+	}
+	root, err := git.GetRepoRoot(runCtx)
+	if err != nil {
+		return nil
+	}
+
+	var untested []string
+	for _, file := range files {
+		rule, ok := testRuleFor(rules, file)
+		if !ok {
+			continue
+		}
+		// Deleting code needs no tests
+		if _, err := os.Lstat(filepath.Join(root, filepath.FromSlash(file))); err != nil {
+			continue
+		}
+		if !hasTestChange(rule, file, files) {
+			untested = append(untested, file)
+		}
+	}
+	if len(untested) == 0 {
+		return nil
+	}
+
+	severity := claude.SeverityWarning
+	if policy.Action == "block" {
+		severity = claude.SeverityCritical
+	}
+	return []claude.Finding{{
+		Severity:    severity,
+		Description: fmt.Sprintf("%d source file(s) changed without test changes (tests_required): %s", len(untested), strings.Join(untested, ", ")),
+	}}
+}
+
+// validateTestPolicy checks the action and globs of tests_required
+func validateTestPolicy(policy config.TestsRequiredConfig) error {
+	switch policy.Action {
+	case "", "warn", "block":
+	default:
+		return fmt.Errorf("tests_required.action: unknown action %q (use warn or block)", policy.Action)
+	}
+	sample := map[string]string{"dir": "dir", "name": "name", "ext": "ext"}
+	for i, rule := range policy.Rules {
+		if rule.Source == "" || len(rule.Tests) == 0 {
+			return fmt.Errorf("tests_required.rules[%d]: needs a source and at least one test glob", i)
+		}
+		for _, test := range rule.Tests {
+			if _, err := globRegexp(test, sample); err != nil {
+				return fmt.Errorf("tests_required.rules[%d]: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// testRuleFor returns the first rule whose source glob matches file, unless
+// file is itself a test of that rule
+func testRuleFor(rules []config.TestRule, file string) (config.TestRule, bool) {
+	for _, rule := range rules {
+		if !matchGlob(rule.Source, file, nil) {
+			continue
+		}
+		for _, test := range rule.Tests {
+			if matchGlob(test, file, map[string]string{}) {
+				return config.TestRule{}, false
+			}
+		}
+		return rule, true
+	}
+	return config.TestRule{}, false
+}
+
+// hasTestChange reports whether a test of source is among the changed files
+func hasTestChange(rule config.TestRule, source string, files []string) bool {
+	base := path.Base(source)
+	ext := path.Ext(base)
+	vars := map[string]string{
+		"dir":  path.Dir(source),
+		"name": strings.TrimSuffix(base, ext),
+		"ext":  strings.TrimPrefix(ext, "."),
+	}
+	for _, test := range rule.Tests {
+		for _, file := range files {
+			if file != source && matchGlob(test, file, vars) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash-separated path against a glob with *, ?, and **
+// (any number of directories). Placeholders such as {name} are replaced by
+// vars; with empty vars they match anything, and with nil vars they are
+// taken literally.
+func matchGlob(glob, name string, vars map[string]string) bool {
+	re, err := globRegexp(glob, vars)
+	return err == nil && re.MatchString(name)
+}
+
+// globRegexp translates a glob into an anchored regular expression
+func globRegexp(glob string, vars map[string]string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		rest := glob[i:]
+		switch {
+		case strings.HasPrefix(rest, "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(rest, "**"):
+			b.WriteString(".*")
+			i++
+		case rest[0] == '*':
+			b.WriteString("[^/]*")
+		case rest[0] == '?':
+			b.WriteString("[^/]")
+		case rest[0] == '{' && vars != nil && strings.Contains(rest, "}"):
+			key := rest[1:strings.Index(rest, "}")]
+			value, known := vars[key]
+			switch {
+			case !known && len(vars) > 0:
+				return nil, fmt.Errorf("unknown placeholder {%s} in %q", key, glob)
+			case !known && key == "dir" && strings.HasPrefix(rest[len(key)+2:], "/"):
+				b.WriteString("(?:.*/)?")
+				i++
+			case !known:
+				b.WriteString(".*")
+			case key == "dir" && value == "." && strings.HasPrefix(rest[len(key)+2:], "/"):
+				i++ // A file at the root has no directory to prefix
+			default:
+				b.WriteString(regexp.QuoteMeta(value))
+			}
+			i += len(key) + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(rest[:1]))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/config"
+)
+
+// todoMarker matches the comments that mark unfinished work
+var todoMarker = regexp.MustCompile(`\b(TODO|FIXME|HACK|XXX)\b`)
+
+// todoDelta is what a diff does to the TODO/FIXME/HACK comments
+type todoDelta struct {
+	Added    []diffLine
+	Resolved []diffLine
+}
+
+// findTodoDelta compares the markers in the added and removed lines. A
+// comment that was only moved counts as neither.
+func findTodoDelta(lines []diffLine) todoDelta {
+	var added, removed []diffLine
+	for _, line := range lines {
+		if !todoMarker.MatchString(line.Text) {
+			continue
+		}
+		if line.Added {
+			added = append(added, line)
+		} else {
+			removed = append(removed, line)
+		}
+	}
+
+	// Pair up identical comments that were removed in one place and added
+	// in another
+	moved := map[string]int{}
+	for _, line := range removed {
+		moved[strings.TrimSpace(line.Text)]++
+	}
+	var delta todoDelta
+	for _, line := range added {
+		text := strings.TrimSpace(line.Text)
+		if moved[text] > 0 {
+			moved[text]--
+			continue
+		}
+		delta.Added = append(delta.Added, line)
+	}
+	for _, line := range removed {
+		text := strings.TrimSpace(line.Text)
+		if moved[text] > 0 {
+			moved[text]--
+			delta.Resolved = append(delta.Resolved, line)
+		}
+	}
+	return delta
+}
+
+// reportTodoDelta prints the new markers and the number of resolved ones
+func reportTodoDelta(delta todoDelta) {
+	if len(delta.Added) > 0 {
+		summaryf("\n📌 %d new TODO/FIXME/HACK comment(s):\n", len(delta.Added))
+		for _, line := range delta.Added {
+			summaryf("   %s\n", line)
+		}
+	}
+	if len(delta.Resolved) > 0 {
+		logf("\n✅ %d TODO/FIXME/HACK comment(s) removed.\n", len(delta.Resolved))
+	}
+}
+
+// todoHint tells Claude about the new markers, for todos.context
+func todoHint(delta todoDelta) string {
+	var lines []string
+	for _, line := range delta.Added {
+		lines = append(lines, line.String())
+	}
+	return fmt.Sprintf("The changes add these TODO/FIXME/HACK comments; consider them in the review and mention unfinished work in the message: %s", strings.Join(lines, "; "))
+}
+
+// todoPolicyError returns the error that stops a commit adding markers
+// with todos.action "block", or nil
+func todoPolicyError(cfg *config.Config, delta todoDelta) error {
+	if cfg.Todos.Action != "block" || len(delta.Added) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d new TODO/FIXME/HACK comment(s) (todos.action is block)", len(delta.Added))
+}
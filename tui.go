@@ -0,0 +1,507 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/term"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// ANSI sequences used to color the full-screen interface
+const (
+	ansiReset   = "\x1b[0m"
+	ansiReverse = "\x1b[7m"
+	ansiBold    = "\x1b[1m"
+	ansiDim     = "\x1b[2m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiCyan    = "\x1b[36m"
+)
+
+// Height of the message editor
+const tuiEditorHeight = 6
+
+// tui is the bubbletea model of `cc tui`: the changed files with their
+// staging state, the selected file's diff, and the findings and message for
+// the staged changes
+type tui struct {
+	cfg           *config.Config
+	width, height int
+
+	files    []git.FileStatus
+	cursor   int
+	diff     []string
+	scroll   int
+	diffPane bool // Arrow keys scroll the diff instead of moving the selection
+
+	findings []claude.Finding
+	message  string
+	status   string
+	// forceArmed is set after a commit was refused because of blocking
+	// findings; pressing the same key again commits anyway
+	forceArmed string
+	// busy is set while Claude or git runs in the background
+	busy bool
+
+	editing bool
+	editor  textarea.Model
+}
+
+// Results of the background work started from the interface
+type (
+	generatedMsg struct {
+		findings []claude.Finding
+		message  string
+		err      error
+	}
+	committedMsg struct {
+		sha     string
+		pushed  bool
+		err     error
+		pushErr error
+	}
+	editedMsg struct {
+		message string
+		err     error
+	}
+)
+
+// handleTUI runs the full-screen interface until the user quits
+func handleTUI(cfg *config.Config) error {
+	if !interactive() || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("cc tui needs an interactive terminal")
+	}
+	if _, err := checkRepoState(false); err != nil {
+		return err
+	}
+	// The status line replaces the spinner and progress output
+	output.Quiet = true
+
+	editor := textarea.New()
+	editor.ShowLineNumbers = false
+	editor.CharLimit = 0
+	editor.Placeholder = "Commit message"
+
+	t := &tui{
+		cfg:    cfg,
+		status: "Press space to stage files, then g to let Claude review them and write the message.",
+		editor: editor,
+		width:  80,
+		height: 24,
+	}
+	t.reload()
+
+	_, err := tea.NewProgram(t, tea.WithAltScreen(), tea.WithContext(runCtx)).Run()
+	return err
+}
+
+func (t *tui) Init() tea.Cmd { return nil }
+
+func (t *tui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		t.width, t.height = max(msg.Width, 40), max(msg.Height, 12)
+		t.editor.SetWidth(t.width)
+		t.editor.SetHeight(tuiEditorHeight)
+		t.scroll = min(t.scroll, max(len(t.diff)-1, 0))
+		return t, nil
+
+	case generatedMsg:
+		t.busy = false
+		if msg.err != nil {
+			t.status = "❌ " + msg.err.Error()
+			return t, nil
+		}
+		t.findings = msg.findings
+		t.message = msg.message
+		t.status = fmt.Sprintf("📝 Message generated (%d findings). Press e to edit, c to commit, p to commit and push.", len(t.findings))
+		return t, nil
+
+	case committedMsg:
+		t.busy = false
+		if msg.err != nil {
+			t.status = "❌ " + msg.err.Error()
+			return t, nil
+		}
+		t.status = fmt.Sprintf("✅ Committed %s", shortSHA(msg.sha))
+		if msg.pushErr != nil {
+			t.status = fmt.Sprintf("❌ Committed %s, but push failed: %v", shortSHA(msg.sha), msg.pushErr)
+		} else if msg.pushed {
+			t.status += " and pushed"
+		}
+		t.message = ""
+		t.findings = nil
+		t.reload()
+		return t, nil
+
+	case editedMsg:
+		if msg.err != nil {
+			t.status = "❌ " + msg.err.Error()
+			return t, nil
+		}
+		t.message = msg.message
+		t.status = "📝 Message updated."
+		return t, nil
+
+	case tea.KeyMsg:
+		if t.editing {
+			return t.handleEditorKey(msg)
+		}
+		return t, t.handleKey(msg.String())
+	}
+	return t, nil
+}
+
+// handleEditorKey passes keys to the message editor; esc keeps the edit and
+// ctrl+c discards it
+func (t *tui) handleEditorKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		t.message = strings.TrimSpace(t.editor.Value())
+		t.editing = false
+		t.editor.Blur()
+		t.status = "📝 Message updated."
+		return t, nil
+	case "ctrl+c":
+		t.editing = false
+		t.editor.Blur()
+		t.status = "Edit discarded."
+		return t, nil
+	}
+	var cmd tea.Cmd
+	t.editor, cmd = t.editor.Update(msg)
+	return t, cmd
+}
+
+// handleKey applies a key press outside the editor
+func (t *tui) handleKey(key string) tea.Cmd {
+	if key == "q" || key == "ctrl+c" {
+		return tea.Quit
+	}
+	if t.busy {
+		return nil
+	}
+	armed := t.forceArmed
+	t.forceArmed = ""
+
+	switch key {
+	case "tab":
+		t.diffPane = !t.diffPane
+
+	case "up", "k":
+		if t.diffPane {
+			t.scroll = max(t.scroll-1, 0)
+		} else if t.cursor > 0 {
+			t.cursor--
+			t.loadDiff()
+		}
+
+	case "down", "j":
+		if t.diffPane {
+			t.scroll = min(t.scroll+1, max(len(t.diff)-1, 0))
+		} else if t.cursor < len(t.files)-1 {
+			t.cursor++
+			t.loadDiff()
+		}
+
+	case "pgup":
+		t.scroll = max(t.scroll-t.diffHeight(), 0)
+
+	case "pgdown", "f":
+		t.scroll = min(t.scroll+t.diffHeight(), max(len(t.diff)-1, 0))
+
+	case " ":
+		t.toggleStaged()
+
+	case "r":
+		t.reload()
+		t.status = "Reloaded."
+
+	case "g":
+		return t.generate()
+
+	case "e":
+		t.editing = true
+		t.editor.SetValue(t.message)
+		t.status = "✏️  Editing the message. Press esc to keep it, ctrl+c to discard."
+		return t.editor.Focus()
+
+	case "E":
+		editor := &externalEditor{message: t.message}
+		return tea.Exec(editor, func(err error) tea.Msg {
+			return editedMsg{message: editor.edited, err: err}
+		})
+
+	case "c", "p":
+		return t.commit(key == "p", armed == key)
+	}
+	return nil
+}
+
+// reload refreshes the file list and the selected file's diff
+func (t *tui) reload() {
+	files, err := git.GetStatus(runCtx)
+	if err != nil {
+		t.status = "❌ " + err.Error()
+		return
+	}
+	t.files = files
+	if t.cursor >= len(files) {
+		t.cursor = max(len(files)-1, 0)
+	}
+	t.loadDiff()
+}
+
+func (t *tui) loadDiff() {
+	t.scroll = 0
+	if len(t.files) == 0 {
+		t.diff = []string{"No changes."}
+		return
+	}
+	diff, err := git.GetFileDiff(runCtx, t.files[t.cursor])
+	if err != nil {
+		t.diff = []string{"❌ " + err.Error()}
+		return
+	}
+	t.diff = strings.Split(strings.ReplaceAll(diff, "\t", "    "), "\n")
+}
+
+func (t *tui) toggleStaged() {
+	if len(t.files) == 0 {
+		return
+	}
+	file := t.files[t.cursor]
+	var err error
+	if file.Unstaged {
+		err = git.StageFile(runCtx, file.Path)
+	} else {
+		err = git.UnstageFile(runCtx, file.Path)
+	}
+	if err != nil {
+		t.status = "❌ " + err.Error()
+		return
+	}
+	t.reload()
+}
+
+// generate reviews the staged changes in the background and replaces the
+// message when done
+func (t *tui) generate() tea.Cmd {
+	changes, err := collectStagedChanges()
+	if err != nil {
+		t.status = "❌ " + err.Error()
+		return nil
+	}
+	if len(changes.Files) == 0 {
+		t.status = "⚠️  Nothing is staged. Press space to stage the selected file."
+		return nil
+	}
+
+	t.busy = true
+	t.status = fmt.Sprintf("🤖 Claude (%s) is reviewing %d staged files...", t.cfg.Model, len(changes.Files))
+	cfg := t.cfg
+	return func() tea.Msg {
+		result, err := reviewAndMessage(cfg, commitOptions{}, changes, "")
+		if err != nil {
+			return generatedMsg{err: err}
+		}
+		return generatedMsg{findings: result.Findings, message: finishMessage(cfg, result.Message, claude.LastModel())}
+	}
+}
+
+// commit commits the index with the message in the background, then pushes
+// if asked. Findings at or above block_severity make the first press only warn.
+func (t *tui) commit(push bool, force bool) tea.Cmd {
+	if strings.TrimSpace(t.message) == "" {
+		t.status = "⚠️  The commit message is empty. Press g to generate one or e to write it."
+		return nil
+	}
+	threshold, err := claude.ParseSeverity(t.cfg.BlockSeverity)
+	if err != nil {
+		t.status = "❌ invalid block_severity in config: " + err.Error()
+		return nil
+	}
+	key := map[bool]string{false: "c", true: "p"}[push]
+	if blocking := claude.Blocking(t.findings, threshold); len(blocking) > 0 && !force {
+		t.forceArmed = key
+		t.status = fmt.Sprintf("⚠️  %d finding(s) at or above %s severity. Press %s again to commit anyway.", len(blocking), threshold, key)
+		return nil
+	}
+
+	t.busy = true
+	t.status = "💾 Committing..."
+	if push {
+		t.status = "💾 Committing and pushing..."
+	}
+	cfg, message := t.cfg, t.message
+	return func() tea.Msg {
+		if err := git.Commit(runCtx, message, cfg.SignOff); err != nil {
+			return committedMsg{err: err}
+		}
+		recordCommit("")
+		sha, _ := git.GetHeadSHA(runCtx)
+		if !push {
+			return committedMsg{sha: sha}
+		}
+		// Pressing p is the confirmation for setting a new branch's upstream
+		_, err := pushBranch(cfg, true, false)
+		return committedMsg{sha: sha, pushed: err == nil, pushErr: err}
+	}
+}
+
+// externalEditor runs editMessage while bubbletea has released the terminal
+type externalEditor struct {
+	message string
+	edited  string
+}
+
+func (e *externalEditor) Run() error {
+	edited, err := editMessage(e.message)
+	e.edited = edited
+	return err
+}
+
+func (e *externalEditor) SetStdin(io.Reader)  {}
+func (e *externalEditor) SetStdout(io.Writer) {}
+func (e *externalEditor) SetStderr(io.Writer) {}
+
+// Layout: the file list and diff side by side, then findings, the message,
+// the status line, and the key help
+func (t *tui) findingsHeight() int { return min(len(t.findings), 5) }
+
+func (t *tui) messageHeight() int {
+	if t.editing {
+		return tuiEditorHeight
+	}
+	return min(max(len(strings.Split(t.message, "\n")), 1), tuiEditorHeight)
+}
+
+func (t *tui) diffHeight() int {
+	// Header, separators around findings and message, status, and help
+	return max(t.height-t.findingsHeight()-t.messageHeight()-5, 3)
+}
+
+func (t *tui) View() string {
+	width := t.width
+	listWidth := min(max(width/3, 20), 48)
+	diffWidth := width - listWidth - 3
+	rows := t.diffHeight()
+
+	var lines []string
+
+	// Header
+	header := fmt.Sprintf(" cc tui — %s", t.cfg.Model)
+	lines = append(lines, ansiBold+fit(header, width)+ansiReset)
+
+	// Files | Diff, keeping the selected file in view
+	first := max(t.cursor-rows+1, 0)
+	for row := 0; row < rows; row++ {
+		left := ""
+		if i := first + row; i < len(t.files) {
+			file := t.files[i]
+			mark := "[ ]"
+			switch {
+			case file.Staged && file.Unstaged:
+				mark = "[~]"
+			case file.Staged:
+				mark = "[x]"
+			}
+			left = fit(fmt.Sprintf("%s %s", mark, file.Path), listWidth)
+			if i == t.cursor {
+				left = ansiReverse + left + ansiReset
+			}
+		} else {
+			left = strings.Repeat(" ", listWidth)
+		}
+
+		right := ""
+		if i := t.scroll + row; i < len(t.diff) {
+			right = colorDiffLine(fit(t.diff[i], diffWidth))
+		}
+
+		separator := ansiDim + " │ " + ansiReset
+		if t.diffPane {
+			separator = ansiBold + " ┃ " + ansiReset
+			if !output.Plain {
+				separator = ansiCyan + " ┃ " + ansiReset
+			}
+		}
+		lines = append(lines, left+separator+right)
+	}
+
+	// Findings
+	lines = append(lines, ansiDim+strings.Repeat("─", width)+ansiReset)
+	for i := 0; i < t.findingsHeight(); i++ {
+		finding := t.findings[i]
+		icon := map[claude.Severity]string{claude.SeverityCritical: "🔴", claude.SeverityWarning: "🟡", claude.SeverityInfo: "🔵"}[finding.Severity]
+		lines = append(lines, icon+" "+fit(fmt.Sprintf("[%s] %s", finding.Severity, finding.Description), width-3))
+	}
+
+	// Message
+	lines = append(lines, ansiDim+strings.Repeat("─", width)+ansiReset)
+	if t.editing {
+		lines = append(lines, t.editor.View())
+	} else {
+		messageLines := strings.Split(t.message, "\n")
+		if t.message == "" {
+			messageLines = []string{ansiDim + "(no commit message yet)" + ansiReset}
+		}
+		for i := 0; i < t.messageHeight(); i++ {
+			lines = append(lines, fit(messageLines[i], width))
+		}
+	}
+
+	// Status and help
+	help := "↑/↓ select • tab scroll diff • space stage/unstage • g generate • e edit • E editor • c commit • p commit & push • r reload • q quit"
+	if t.editing {
+		help = "esc done • ctrl+c discard"
+	}
+	lines = append(lines, fit(t.status, width), ansiDim+fit(help, width)+ansiReset)
+	return strings.Join(lines, "\n")
+}
+
+// colorDiffLine colors added, removed, and hunk header lines, unless
+// colors are off (NO_COLOR or --no-emoji)
+func colorDiffLine(line string) string {
+	if output.Plain {
+		return line
+	}
+	switch {
+	case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		return ansiBold + line + ansiReset
+	case strings.HasPrefix(line, "+"):
+		return ansiGreen + line + ansiReset
+	case strings.HasPrefix(line, "-"):
+		return ansiRed + line + ansiReset
+	case strings.HasPrefix(line, "@@"):
+		return ansiCyan + line + ansiReset
+	}
+	return line
+}
+
+// fit truncates or pads text to exactly width runes
+func fit(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) > width {
+		if width <= 1 {
+			return string(runes[:max(width, 0)])
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return text + strings.Repeat(" ", width-len(runes))
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
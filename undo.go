@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/debuglog"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// lastCommitFile records the last commit cc made, inside the git directory
+const lastCommitFile = "cc-last-commit.json"
+
+// lastCommit is what `cc undo` needs to take a commit back
+type lastCommit struct {
+	SHA string `json:"sha"`
+	// IndexTree is the index before cc staged everything; empty when only
+	// staged changes went into the commit
+	IndexTree string `json:"index_tree,omitempty"`
+}
+
+// indexBeforeStaging snapshots the index so `cc undo` can restore it. A
+// failure only means undo leaves everything staged.
+func indexBeforeStaging() string {
+	tree, err := git.WriteTree(runCtx)
+	if err != nil {
+		debuglog.Log("saving the index for undo", "error", err)
+		return ""
+	}
+	return tree
+}
+
+// recordCommit remembers HEAD as the last commit cc made
+func recordCommit(indexTree string) {
+	sha, err := git.GetHeadSHA(runCtx)
+	if err != nil {
+		return
+	}
+	path, err := git.GetGitPath(runCtx, lastCommitFile)
+	if err != nil {
+		return
+	}
+	data, _ := json.Marshal(lastCommit{SHA: sha, IndexTree: indexTree})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		debuglog.Log("recording the commit for undo", "error", err)
+	}
+}
+
+// loadLastCommit returns the recorded commit (nil if there is none) and
+// the file it is kept in
+func loadLastCommit() (*lastCommit, string, error) {
+	path, err := git.GetGitPath(runCtx, lastCommitFile)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, path, nil
+	}
+	if err != nil {
+		return nil, path, err
+	}
+	var last lastCommit
+	if err := json.Unmarshal(data, &last); err != nil {
+		return nil, path, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return &last, path, nil
+}
+
+// handleUndo takes back the last commit cc made: a soft reset that restores
+// the index as it was before cc staged everything, or, once the commit has
+// been pushed, a revert commit after confirmation
+func handleUndo() error {
+	if _, err := checkRepoState(false); err != nil {
+		return err
+	}
+	last, path, err := loadLastCommit()
+	if err != nil {
+		return withExitCode(exitGitError, err)
+	}
+	if last == nil {
+		summaryf("✅ No commit made by cc to undo.\n")
+		exit(exitNoChanges)
+	}
+
+	head, err := git.GetHeadSHA(runCtx)
+	if err != nil {
+		return withExitCode(exitGitError, err)
+	}
+	if head != last.SHA {
+		return fmt.Errorf("HEAD has moved since cc committed %s; undo only takes back the latest commit, when cc made it", shortSHA(last.SHA))
+	}
+	commits, err := git.GetCommits(runCtx, 1)
+	if err != nil || len(commits) == 0 {
+		return withExitCode(exitGitError, fmt.Errorf("reading commit %s: %w", shortSHA(head), err))
+	}
+	commit := commits[0]
+	subject, _ := claude.SplitMessage(commit.Message)
+
+	if git.IsPushed(runCtx, head) {
+		summaryf("⚠️  %s %s has already been pushed; taking it back locally would need a force push.\n", shortSHA(head), subject)
+		if !interactive() && !output.Yes {
+			return withExitCode(exitAborted, fmt.Errorf("rerun with --yes to revert it in a new commit"))
+		}
+		ok, err := confirm("Create a commit that reverts it instead?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			summaryf("❌ Aborted. Nothing was changed.\n")
+			exit(exitAborted)
+		}
+		if err := git.Revert(runCtx, head); err != nil {
+			return withExitCode(exitGitError, fmt.Errorf("reverting: %w", err))
+		}
+		os.Remove(path)
+		summaryf("↩️  Reverted %s in a new commit (not pushed yet).\n", shortSHA(head))
+		return nil
+	}
+
+	if len(commit.Parents) != 1 {
+		return fmt.Errorf("%s has no single parent to go back to; undo it with git instead", shortSHA(head))
+	}
+	if err := git.ResetSoft(runCtx, commit.Parents[0]); err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("resetting: %w", err))
+	}
+	if last.IndexTree != "" {
+		if err := git.ReadTree(runCtx, last.IndexTree); err != nil {
+			return withExitCode(exitGitError, fmt.Errorf("restoring the staged changes: %w", err))
+		}
+	}
+	os.Remove(path)
+	summaryf("↩️  Undid %s %s; its changes are back in your working tree, staged as before.\n", shortSHA(head), subject)
+	return nil
+}
@@ -0,0 +1,574 @@
+package main
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/minisign"
+	"github.com/quaywin/claude-commit/internal/network"
+)
+
+// releasePublicKey is the minisign public key that signs checksums.txt of
+// every release. release.sh sets it from minisign.pub with -ldflags; builds
+// from source leave it empty and skip the signature check.
+var releasePublicKey = ""
+
+// releasesAPI lists the releases of cc on GitHub
+const releasesAPI = "https://api.github.com/repos/quaywin/claude-commit/releases"
+
+type GithubRelease struct {
+	TagName    string `json:"tag_name"`
+	Prerelease bool   `json:"prerelease"`
+	Draft      bool   `json:"draft"`
+	Assets     []struct {
+		Name               string `json:"name"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// handleUpdate installs the newest release of channel ("stable" or "beta",
+// which includes pre-releases), or exactly the release tagged to, which may
+// be older than the running version
+func handleUpdate(channel string, to string, allowUnsigned bool) {
+	if to != "" {
+		if !strings.HasPrefix(to, "v") {
+			to = "v" + to
+		}
+		printf("🔍 Looking up %s...\n", to)
+	} else {
+		printf("🔍 Checking for updates (%s channel)...\n", channel)
+	}
+
+	release, err := fetchRelease(channel, to)
+	if err != nil {
+		printf("❌ Error checking for updates: %v\n", err)
+		exit(1)
+	}
+
+	latestVersion := release.TagName
+	if latestVersion == VERSION {
+		if to != "" {
+			printf("✅ You're already on %s\n", VERSION)
+		} else {
+			printf("✅ You're already on the latest %s version (%s)\n", channel, VERSION)
+		}
+		return
+	}
+
+	switch {
+	case to != "" && compareVersions(latestVersion, VERSION) < 0:
+		printf("⏪ Rolling back to %s (you have %s)\n", latestVersion, VERSION)
+	case to != "":
+		printf("📦 Installing %s (you have %s)\n", latestVersion, VERSION)
+	case compareVersions(latestVersion, VERSION) < 0:
+		// e.g. a beta build checking the stable channel
+		printf("✅ You're on %s, newer than the latest %s version (%s). Use 'cc update --to %s' to switch to it.\n", VERSION, channel, latestVersion, latestVersion)
+		return
+	default:
+		printf("📦 New version available: %s (you have %s)\n", latestVersion, VERSION)
+	}
+	installRelease(release, allowUnsigned)
+}
+
+// installRelease downloads the binary of release for this platform, checks
+// it against the release checksums, and replaces the running binary with
+// it. The replaced binary is kept next to it with an .old suffix for
+// `cc update --rollback`.
+func installRelease(release *GithubRelease, allowUnsigned bool) {
+	latestVersion := release.TagName
+
+	// Determine OS and architecture
+	osName := runtime.GOOS
+	arch := runtime.GOARCH
+
+	// Find matching binary
+	binaryName := fmt.Sprintf("cc-%s-%s", osName, arch)
+	if osName == "windows" {
+		binaryName += ".exe"
+	}
+	var downloadURL string
+	var checksumURL string
+	var signatureURL string
+	for _, asset := range release.Assets {
+		if asset.Name == binaryName {
+			downloadURL = asset.BrowserDownloadURL
+		}
+		if asset.Name == "checksums.txt" {
+			checksumURL = asset.BrowserDownloadURL
+		}
+		if asset.Name == "checksums.txt.minisig" {
+			signatureURL = asset.BrowserDownloadURL
+		}
+	}
+
+	if downloadURL == "" {
+		printf("❌ No binary found for %s/%s\n", osName, arch)
+		exit(1)
+	}
+
+	if checksumURL == "" {
+		printf("⚠️  Warning: No checksums file found in release\n")
+		printf("❌ Cannot verify download integrity. Aborting for security.\n")
+		exit(1)
+	}
+
+	// Download and parse checksums
+	printf("🔐 Downloading checksums...\n")
+	checksumResp, err := network.Client().Get(checksumURL)
+	if err != nil {
+		printf("❌ Error downloading checksums: %v\n", network.Explain(err))
+		exit(1)
+	}
+	defer checksumResp.Body.Close()
+
+	checksumData, err := io.ReadAll(checksumResp.Body)
+	if err != nil {
+		printf("❌ Error reading checksums: %v\n", err)
+		exit(1)
+	}
+	verifyChecksumsSignature(release.TagName, checksumData, signatureURL, allowUnsigned)
+
+	// Parse expected checksum
+	var expectedChecksum string
+	for _, line := range strings.Split(string(checksumData), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) == 2 && parts[1] == binaryName {
+			expectedChecksum = parts[0]
+			break
+		}
+	}
+
+	if expectedChecksum == "" {
+		printf("❌ No checksum found for %s\n", binaryName)
+		exit(1)
+	}
+
+	// Download new binary
+	printf("📥 Downloading %s...\n", binaryName)
+	resp, err := network.Client().Get(downloadURL)
+	if err != nil {
+		printf("❌ Error downloading binary: %v\n", network.Explain(err))
+		exit(1)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		printf("❌ Error downloading binary: HTTP %d\n", resp.StatusCode)
+		exit(1)
+	}
+
+	// Create temporary file
+	tmpFile, err := os.CreateTemp("", "cc-update-*")
+	if err != nil {
+		printf("❌ Error creating temporary file: %v\n", err)
+		exit(1)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+
+	// Write downloaded binary to temp file
+	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
+		tmpFile.Close()
+		printf("❌ Error saving binary: %v\n", err)
+		exit(1)
+	}
+	tmpFile.Close()
+
+	// Verify checksum
+	printf("🔐 Verifying checksum...\n")
+	actualChecksum, err := calculateSHA256(tmpPath)
+	if err != nil {
+		printf("❌ Error calculating checksum: %v\n", err)
+		exit(1)
+	}
+
+	if actualChecksum != expectedChecksum {
+		printf("❌ Checksum mismatch!\n")
+		printf("   Expected: %s\n", expectedChecksum)
+		printf("   Got:      %s\n", actualChecksum)
+		printf("   The download may have been corrupted or tampered with.\n")
+		exit(1)
+	}
+	printf("✅ Checksum verified\n")
+
+	// Make it executable
+	if err := os.Chmod(tmpPath, 0755); err != nil {
+		printf("❌ Error setting permissions: %v\n", err)
+		exit(1)
+	}
+
+	// Get current executable path
+	exePath, err := os.Executable()
+	if err != nil {
+		printf("❌ Error finding current executable: %v\n", err)
+		exit(1)
+	}
+
+	// On Windows, we can't replace a running executable
+	// Instead, we rename the old one and place the new one
+	if runtime.GOOS == "windows" {
+		backupPath := exePath + ".old"
+		newPath := exePath + ".new"
+
+		// Copy new binary to .new file
+		if err := copyFile(tmpPath, newPath); err != nil {
+			printf("❌ Error copying new binary: %v\n", err)
+			exit(1)
+		}
+
+		// Create a batch script to complete the update after we exit
+		batchScript := filepath.Join(filepath.Dir(exePath), "update.bat")
+		batchContent := fmt.Sprintf(`@echo off
+timeout /t 1 /nobreak >nul
+move /y "%s" "%s" >nul 2>&1
+move /y "%s" "%s" >nul 2>&1
+del "%%~f0"
+`, exePath, backupPath, newPath, exePath)
+
+		if err := os.WriteFile(batchScript, []byte(batchContent), 0755); err != nil {
+			printf("❌ Error creating update script: %v\n", err)
+			exit(1)
+		}
+
+		printf("✅ Update to %s ready!\n", latestVersion)
+		printf("🔄 Completing update... (this will restart cc)\n")
+
+		// Execute the batch script and exit
+		cmd := exec.Command("cmd", "/c", "start", "/b", batchScript)
+		cmd.Start()
+		exit(0)
+	}
+
+	// Replace current binary (Unix-like systems)
+	printf("🚚 Installing update...\n")
+
+	// On Unix, we can't always rename/overwrite a running binary
+	// The safest way is to rename the OLD binary and then put the NEW one in its place
+	oldPath := exePath + ".old"
+	if err := os.Rename(exePath, oldPath); err != nil {
+		// If rename fails (might not have permission), try copy then rename
+		if err := copyFile(tmpPath, exePath); err != nil {
+			printf("❌ Error replacing binary: %v\n", err)
+			printf("💡 You may need to run with sudo: sudo cc update\n")
+			exit(1)
+		}
+	} else {
+		// Moved old binary to .old, now move new binary to original path
+		if err := os.Rename(tmpPath, exePath); err != nil {
+			// If rename fails (might be cross-device link), try copy
+			if err := copyFile(tmpPath, exePath); err != nil {
+				// If copying new binary fails, try to restore old one
+				os.Rename(oldPath, exePath)
+				printf("❌ Error installing new binary: %v\n", err)
+				exit(1)
+			}
+		}
+		// Keep the .old binary for --rollback
+	}
+
+	printf("✅ Updated to %s successfully!\n", latestVersion)
+	printf("💡 If this version gives you trouble, go back with: cc update --rollback\n")
+}
+
+// handleRollback goes back to the binary the last update replaced. Swapping
+// it with the running one means a second rollback undoes the first. Without
+// that binary, it installs the newest release older than this one instead.
+func handleRollback(allowUnsigned bool) {
+	exePath, err := os.Executable()
+	if err != nil {
+		printf("❌ Error finding current executable: %v\n", err)
+		exit(1)
+	}
+	oldPath := exePath + ".old"
+
+	if _, err := os.Stat(oldPath); err == nil {
+		previous := "the previous version"
+		if out, err := exec.Command(oldPath, "version").Output(); err == nil {
+			previous = strings.TrimPrefix(strings.TrimSpace(string(out)), "cc version ")
+		}
+		printf("⏪ Rolling back to %s (you have %s)\n", previous, VERSION)
+
+		// A running binary can be renamed (even on Windows), just not overwritten
+		swapPath := exePath + ".rollback"
+		if err := os.Rename(exePath, swapPath); err != nil {
+			printf("❌ Error moving the current binary aside: %v\n", err)
+			printf("💡 You may need to run with sudo: sudo cc update --rollback\n")
+			exit(1)
+		}
+		if err := os.Rename(oldPath, exePath); err != nil {
+			os.Rename(swapPath, exePath)
+			printf("❌ Error restoring the previous binary: %v\n", err)
+			exit(1)
+		}
+		if err := os.Rename(swapPath, oldPath); err != nil {
+			printf("⚠️  Warning: Could not keep %s for another rollback: %v\n", VERSION, err)
+		}
+		printf("✅ Rolled back to %s. Run 'cc update --rollback' again to return to %s.\n", previous, VERSION)
+		return
+	}
+
+	printf("🔍 No previous binary kept; looking up the release before this one...\n")
+	release, err := fetchPreviousRelease()
+	if err != nil {
+		printf("❌ Error finding the previous release: %v\n", err)
+		exit(1)
+	}
+	printf("⏪ Rolling back to %s (you have %s)\n", release.TagName, VERSION)
+	installRelease(release, allowUnsigned)
+}
+
+// fetchPreviousRelease returns the newest release older than the running
+// version. Pre-releases only count when running one.
+func fetchPreviousRelease() (*GithubRelease, error) {
+	req, err := http.NewRequest("GET", releasesAPI+"?per_page=100", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "cc-cli/"+VERSION)
+
+	resp, err := network.Client().Do(req)
+	if err != nil {
+		return nil, network.Explain(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("fetching release info: HTTP %d", resp.StatusCode)
+	}
+
+	var releases []GithubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("parsing release info: %w", err)
+	}
+	onPrerelease := strings.Contains(VERSION, "-")
+	var previous *GithubRelease
+	for i, release := range releases {
+		if release.Draft || (release.Prerelease && !onPrerelease) || compareVersions(release.TagName, VERSION) >= 0 {
+			continue
+		}
+		if previous == nil || compareVersions(release.TagName, previous.TagName) > 0 {
+			previous = &releases[i]
+		}
+	}
+	if previous == nil {
+		return nil, fmt.Errorf("no release older than %s", VERSION)
+	}
+	return previous, nil
+}
+
+// fetchRelease asks the GitHub API for the release tagged to, or else the
+// newest release of channel. GitHub's "latest" release never includes
+// pre-releases, so the beta channel takes the newest of the release list.
+func fetchRelease(channel string, to string) (*GithubRelease, error) {
+	url := releasesAPI + "/latest"
+	switch {
+	case to != "":
+		url = releasesAPI + "/tags/" + to
+	case channel == "beta":
+		url = releasesAPI + "?per_page=30"
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "cc-cli/"+VERSION)
+
+	resp, err := network.Client().Do(req)
+	if err != nil {
+		return nil, network.Explain(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && to != "" {
+		return nil, fmt.Errorf("no release named %s (see https://github.com/quaywin/claude-commit/releases)", to)
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("fetching release info: HTTP %d", resp.StatusCode)
+	}
+
+	if to != "" || channel != "beta" {
+		var release GithubRelease
+		if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+			return nil, fmt.Errorf("parsing release info: %w", err)
+		}
+		return &release, nil
+	}
+
+	var releases []GithubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("parsing release info: %w", err)
+	}
+	var newest *GithubRelease
+	for i, release := range releases {
+		if !release.Draft && (newest == nil || compareVersions(release.TagName, newest.TagName) > 0) {
+			newest = &releases[i]
+		}
+	}
+	if newest == nil {
+		return nil, fmt.Errorf("no releases found")
+	}
+	return newest, nil
+}
+
+// compareVersions orders release tags such as v1.0.10 and v1.1.0-beta.2
+// like semantic versions: numerically, with a pre-release before its release
+func compareVersions(a, b string) int {
+	splitVersion := func(v string) ([]int, string) {
+		v = strings.TrimPrefix(v, "v")
+		core, pre, _ := strings.Cut(v, "-")
+		var parts []int
+		for _, field := range strings.Split(core, ".") {
+			n, _ := strconv.Atoi(field)
+			parts = append(parts, n)
+		}
+		return parts, pre
+	}
+	aParts, aPre := splitVersion(a)
+	bParts, bPre := splitVersion(b)
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x = aParts[i]
+		}
+		if i < len(bParts) {
+			y = bParts[i]
+		}
+		if x != y {
+			return cmp.Compare(x, y)
+		}
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return comparePrerelease(aPre, bPre)
+}
+
+// comparePrerelease orders pre-release labels such as beta.2 and beta.10
+// field by field, numeric fields numerically
+func comparePrerelease(a, b string) int {
+	aFields, bFields := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < min(len(aFields), len(bFields)); i++ {
+		x, xErr := strconv.Atoi(aFields[i])
+		y, yErr := strconv.Atoi(bFields[i])
+		var c int
+		if xErr == nil && yErr == nil {
+			c = cmp.Compare(x, y)
+		} else {
+			c = strings.Compare(aFields[i], bFields[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(aFields), len(bFields))
+}
+
+// verifyChecksumsSignature checks the minisign signature of a release's
+// checksums.txt against releasePublicKey, so a replaced release asset can't
+// slip past the checksum check. It exits on a bad signature, and on a
+// missing one unless allowUnsigned is set (for releases made before
+// signing started).
+func verifyChecksumsSignature(version string, checksums []byte, signatureURL string, allowUnsigned bool) {
+	if releasePublicKey == "" {
+		printf("⚠️  Warning: This build of cc has no release signing key, so the signature can't be checked (the checksum still is).\n")
+		return
+	}
+	key, err := minisign.ParsePublicKey(releasePublicKey)
+	if err != nil {
+		printf("❌ Error reading the release signing key: %v\n", err)
+		exit(1)
+	}
+
+	if signatureURL == "" {
+		if allowUnsigned {
+			printf("⚠️  Warning: %s is not signed; installing it with only the checksum check (--allow-unsigned).\n", version)
+			return
+		}
+		printf("❌ %s has no signature (checksums.txt.minisig), so its checksums can't be trusted.\n", version)
+		printf("💡 Releases made before signing started are unsigned. To install one anyway, rerun with --allow-unsigned.\n")
+		exit(1)
+	}
+
+	printf("🔏 Verifying signature...\n")
+	resp, err := network.Client().Get(signatureURL)
+	if err != nil {
+		printf("❌ Error downloading signature: %v\n", network.Explain(err))
+		exit(1)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		printf("❌ Error downloading signature: HTTP %d\n", resp.StatusCode)
+		exit(1)
+	}
+	signature, err := io.ReadAll(resp.Body)
+	if err != nil {
+		printf("❌ Error reading signature: %v\n", err)
+		exit(1)
+	}
+
+	comment, err := minisign.Verify(key, checksums, signature)
+	if err != nil {
+		printf("❌ Signature verification failed: %v\n", err)
+		printf("   The release may have been tampered with. Nothing was installed.\n")
+		exit(1)
+	}
+	// release.sh signs the version into the trusted comment, so the signed
+	// checksums of an older release can't be replayed as this one
+	if want := "cc " + version + " checksums"; comment != want {
+		printf("❌ The signature is for %q, not %q.\n", comment, want)
+		printf("   The release may have been replaced with another one. Nothing was installed.\n")
+		exit(1)
+	}
+	printf("✅ Signature verified\n")
+}
+
+func copyFile(src, dst string) error {
+	sourceFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
+	destFile, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer destFile.Close()
+
+	if _, err := io.Copy(destFile, sourceFile); err != nil {
+		return err
+	}
+
+	return os.Chmod(dst, 0755)
+}
+
+func calculateSHA256(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
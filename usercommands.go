@@ -0,0 +1,267 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// trustedCommandsKey records, in .git/config, the hash of the repository's
+// commands the user agreed to run
+const trustedCommandsKey = "claude-commit.trustedcommands"
+
+// commandsFromRepo is set when .claude-commit.json configures commands.
+// That file comes with a clone or a pull, so its commands only run after the
+// user has agreed to them once.
+var commandsFromRepo bool
+
+// commandsTrusted remembers the answer for the rest of the run
+var commandsTrusted *bool
+
+// userCommands are the shell commands of a config
+type userCommands struct {
+	Commands config.CommandsConfig
+	Checks   []config.CheckConfig
+}
+
+func userCommandsOf(cfg *config.Config) userCommands {
+	return userCommands{Commands: cfg.Commands, Checks: cfg.Checks}
+}
+
+// noteRepoCommands records whether loading the repository config changed
+// the commands in cfg from before
+func noteRepoCommands(cfg *config.Config, before userCommands) {
+	commandsFromRepo = !slices.Equal(cfg.Commands.BeforeReview, before.Commands.BeforeReview) ||
+		!slices.Equal(cfg.Commands.AfterPush, before.Commands.AfterPush) ||
+		!slices.Equal(cfg.Checks, before.Checks)
+}
+
+// trustCommands reports whether the configured commands may run, asking
+// once for commands that come from .claude-commit.json. Only a terminal run
+// asks; otherwise (and with --yes) they stay skipped until `cc trust`.
+func trustCommands(cfg *config.Config) (bool, error) {
+	if !commandsFromRepo {
+		return true, nil
+	}
+	if commandsTrusted != nil {
+		return *commandsTrusted, nil
+	}
+	hash := commandsHash(cfg)
+	if git.GetConfigValue(runCtx, trustedCommandsKey) == hash {
+		return true, nil
+	}
+	if !interactive() {
+		eprintf("⚠️  %s configures commands that aren't trusted yet; run 'cc trust' to review and allow them.\n", config.RepoConfigFileName)
+		trusted := false
+		commandsTrusted = &trusted
+		return false, nil
+	}
+
+	printf("\n📜 %s configures commands to run:\n", config.RepoConfigFileName)
+	printRepoCommands(cfg)
+	ok, err := confirm("Run these commands now and in future runs (until they change)?")
+	if err != nil {
+		return false, err
+	}
+	commandsTrusted = &ok
+	if !ok {
+		return false, nil
+	}
+	if err := git.SetLocalConfigValue(runCtx, trustedCommandsKey, hash); err != nil {
+		logf("⚠️  Could not remember the answer: %v\n", err)
+	}
+	return true, nil
+}
+
+// commandsHash identifies the commands and checks of cfg. Checks are hashed
+// only when there are some, so that commands trusted before checks existed
+// stay trusted.
+func commandsHash(cfg *config.Config) string {
+	data, _ := json.Marshal(cfg.Commands)
+	if len(cfg.Checks) > 0 {
+		checks, _ := json.Marshal(cfg.Checks)
+		data = append(data, checks...)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func printRepoCommands(cfg *config.Config) {
+	for _, command := range cfg.Commands.BeforeReview {
+		printf("   before_review: %s\n", command)
+	}
+	for _, command := range cfg.Commands.AfterPush {
+		printf("   after_push:    %s\n", command)
+	}
+	for _, check := range cfg.Checks {
+		printf("   check %s: %s\n", check.Name, check.Run)
+	}
+}
+
+// trustedSettingsKey records, in .git/config, the hash of the restricted
+// repository settings the user agreed to apply
+const trustedSettingsKey = "claude-commit.trustedsettings"
+
+// settingsTrusted remembers the answer for the rest of the run
+var settingsTrusted *bool
+
+// trustingRepo is set for `cc trust`, which asks about the settings itself
+var trustingRepo bool
+
+// trustRepoSettings reports whether the settings of .claude-commit.json that
+// reach beyond the repository (tokens, the hosts they go to, telemetry,
+// profiles, ...) may apply, asking once. Without a terminal, and with --yes,
+// they only apply after they were trusted in an earlier run or with `cc trust`.
+func trustRepoSettings(repo *config.RepoConfig) bool {
+	if settingsTrusted != nil {
+		return *settingsTrusted
+	}
+	hash := settingsHash(repo)
+	trusted := git.GetConfigValue(runCtx, trustedSettingsKey) == hash
+	keys := strings.Join(repo.RestrictedKeys(), ", ")
+	switch {
+	case trusted, trustingRepo:
+	case interactive():
+		printf("\n📜 %s sets settings a repository can't set on its own: %s\n", config.RepoConfigFileName, keys)
+		ok, err := confirm("Apply these settings now and in future runs (until they change)?")
+		trusted = err == nil && ok
+		if trusted {
+			if err := git.SetLocalConfigValue(runCtx, trustedSettingsKey, hash); err != nil {
+				logf("⚠️  Could not remember the answer: %v\n", err)
+			}
+		}
+	default:
+		eprintf("⚠️  Ignoring %s from %s until they are trusted; run 'cc trust' to review and allow them.\n", keys, config.RepoConfigFileName)
+	}
+	settingsTrusted = &trusted
+	return trusted
+}
+
+func settingsHash(repo *config.RepoConfig) string {
+	data, _ := json.Marshal(repo.Restricted)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// handleTrust shows the commands and restricted settings of
+// .claude-commit.json that aren't trusted yet and records them as trusted.
+// In a terminal it asks first; elsewhere running `cc trust` is the agreement.
+func handleTrust(cfg *config.Config) error {
+	root, err := git.GetRepoRoot(runCtx)
+	if err != nil {
+		return err
+	}
+	repo, err := config.ReadRepo(root)
+	if err != nil {
+		return err
+	}
+	commands := commandsFromRepo && git.GetConfigValue(runCtx, trustedCommandsKey) != commandsHash(cfg)
+	settings := len(repo.Restricted) > 0 && git.GetConfigValue(runCtx, trustedSettingsKey) != settingsHash(repo)
+	if !commands && !settings {
+		summaryf("✅ Nothing in %s needs trusting.\n", config.RepoConfigFileName)
+		return nil
+	}
+
+	if commands {
+		printf("\n📜 %s configures commands to run:\n", config.RepoConfigFileName)
+		printRepoCommands(cfg)
+	}
+	if settings {
+		printf("\n📜 %s sets settings a repository can't set on its own: %s\n", config.RepoConfigFileName, strings.Join(repo.RestrictedKeys(), ", "))
+	}
+	if interactive() {
+		ok, err := confirm("Trust these now and in future runs (until they change)?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			summaryf("❌ Aborted. Nothing was trusted.\n")
+			exit(exitAborted)
+		}
+	}
+
+	if commands {
+		if err := git.SetLocalConfigValue(runCtx, trustedCommandsKey, commandsHash(cfg)); err != nil {
+			return err
+		}
+	}
+	if settings {
+		if err := git.SetLocalConfigValue(runCtx, trustedSettingsKey, settingsHash(repo)); err != nil {
+			return err
+		}
+	}
+	summaryf("✅ Trusted. cc will use them until %s changes them.\n", config.RepoConfigFileName)
+	return nil
+}
+
+// runCommands runs the commands configured for stage one by one from the
+// repository root, stopping at the first failure. env is added to the
+// environment of each command.
+func runCommands(stage string, commands []string, env ...string) error {
+	root, err := git.GetRepoRoot(runCtx)
+	if err != nil {
+		return err
+	}
+	for _, command := range commands {
+		if err := checkInterrupted(); err != nil {
+			return err
+		}
+		logf("\n🏃 Running %s: %s\n", stage, command)
+		cmd := shellCommand(command)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), env...)
+		cmd.Stdout = commandOutput()
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			if runCtx.Err() != nil {
+				return errInterrupted
+			}
+			return fmt.Errorf("%s command %q failed: %w", stage, command, err)
+		}
+	}
+	return nil
+}
+
+// shellCommand runs command through the platform's shell
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(runCtx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(runCtx, "sh", "-c", command)
+}
+
+// commandOutput is where the output of user commands goes: stdout, unless
+// that is reserved for the --json report or silenced by --quiet
+func commandOutput() io.Writer {
+	if output.JSON || output.Quiet {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// runAfterPush runs the after_push commands with the commit and branch in
+// CC_COMMIT and CC_BRANCH. The push already happened, so failures only warn.
+func runAfterPush(cfg *config.Config, report *commitReport) {
+	if len(cfg.Commands.AfterPush) == 0 {
+		return
+	}
+	if trusted, err := trustCommands(cfg); err != nil || !trusted {
+		logf("\n⚠️  Skipping the commands from %s.\n", config.RepoConfigFileName)
+		return
+	}
+	err := runCommands("after_push", cfg.Commands.AfterPush, "CC_COMMIT="+report.CommitSHA, "CC_BRANCH="+report.Branch)
+	if err != nil && !errors.Is(err, errInterrupted) {
+		logf("\n⚠️  %v\n", err)
+	}
+}
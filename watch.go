@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// watchInterval is how often cc watch snapshots the working tree
+var watchInterval = 2 * time.Second
+
+// handleWatch snapshots the working tree every few seconds and, once it has
+// stayed unchanged for the quiet period, commits it to a side branch with a
+// message from Claude. The current branch, index, and working tree are never
+// touched, so checkpoints can be taken while someone keeps editing.
+func handleWatch(cfg *config.Config) error {
+	quiet, err := cfg.Watch.QuietPeriodDuration()
+	if err != nil {
+		return err
+	}
+	current, err := git.GetCurrentBranch(runCtx)
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("getting current branch: %w", err))
+	}
+	pattern := cfg.Watch.Branch
+	if pattern == "" {
+		pattern = config.DefaultWatchBranch
+	}
+	branch := strings.ReplaceAll(pattern, "{branch}", current)
+
+	// Checkpoints continue the side branch, or start from HEAD (none yet
+	// in a new repository)
+	branchTip := git.ResolveCommit(runCtx, "refs/heads/"+branch)
+	parent := branchTip
+	if parent == "" {
+		parent = git.ResolveCommit(runCtx, "HEAD")
+	}
+	committed := git.EmptyTree
+	if parent != "" {
+		if committed, err = git.GetTree(runCtx, parent); err != nil {
+			return withExitCode(exitGitError, err)
+		}
+	}
+
+	printf("👀 Watching for changes; checkpoints go to %s after %s without edits (Ctrl+C to stop)\n", branch, quiet)
+	seen, changedAt, count := committed, time.Now(), 0
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-runCtx.Done():
+			printf("\n👋 Stopped watching; %d checkpoint(s) committed to %s\n", count, branch)
+			return nil
+		case <-ticker.C:
+		}
+
+		tree, err := git.SnapshotTree(runCtx)
+		if err != nil {
+			eprintf("⚠️  Warning: Could not snapshot the working tree: %v\n", err)
+			continue
+		}
+		if tree != seen {
+			if seen == committed {
+				logf("✏️  Changes detected\n")
+			}
+			seen, changedAt = tree, time.Now()
+			continue
+		}
+		if tree == committed || time.Since(changedAt) < quiet {
+			continue
+		}
+
+		sha, subject, err := commitCheckpoint(cfg, committed, tree, parent, branchTip, branch)
+		if err != nil {
+			return withExitCode(exitGitError, err)
+		}
+		count++
+		summaryf("📍 %s on %s: %s\n", sha[:7], branch, subject)
+		committed, parent, branchTip = tree, sha, sha
+	}
+}
+
+// commitCheckpoint commits tree on top of parent and moves branch from
+// branchTip to the new commit. Without an answer from Claude, the commit
+// still goes in with a generic message.
+func commitCheckpoint(cfg *config.Config, from, tree, parent, branchTip, branch string) (sha, subject string, err error) {
+	files, err := git.GetRangeChangedFiles(runCtx, from, tree)
+	if err != nil {
+		return "", "", fmt.Errorf("getting changed files: %w", err)
+	}
+	diff, mode, err := git.ChooseDiff(len(files), func(summary bool) (string, error) {
+		return git.GetRangeDiff(runCtx, from, tree, summary)
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("getting diff: %w", err)
+	}
+
+	subject, err = claude.CheckpointMessage(runCtx, diff, modelFor(cfg, taskMessage), mode == git.SummaryDiff)
+	if err != nil {
+		eprintf("⚠️  Warning: Could not generate a checkpoint message: %v\n", err)
+		subject = "chore: checkpoint at " + time.Now().Format("15:04:05")
+	}
+
+	sha, err = git.CommitTree(runCtx, tree, parent, subject)
+	if err != nil {
+		return "", "", fmt.Errorf("committing checkpoint: %w", err)
+	}
+	if err := git.UpdateBranch(runCtx, branch, sha, branchTip); err != nil {
+		return "", "", fmt.Errorf("updating %s: %w", branch, err)
+	}
+	return sha, subject, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// whitespaceOnlyFiles finds the files whose changes are all whitespace
+// (e.g. from an auto-formatter), which the -w diff doesn't show. With skip,
+// they are taken out of changes and returned, to be left out of the commit;
+// otherwise they are listed for Claude by name.
+func whitespaceOnlyFiles(changes *changeSet, skip bool) ([]string, error) {
+	files, err := git.GetWhitespaceOnlyFiles(runCtx)
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	if !skip {
+		changes.Diff += "\n--- FILES WITH ONLY WHITESPACE CHANGES (not shown) ---\n" + strings.Join(files, "\n") + "\n"
+		return nil, nil
+	}
+	changes.Files = slices.DeleteFunc(changes.Files, func(path string) bool {
+		return slices.Contains(files, path)
+	})
+	logf("🧹 Leaving %d file(s) with only whitespace changes out of the commit: %s\n", len(files), strings.Join(files, ", "))
+	return files, nil
+}
+
+// stageChanges stages the changes to commit, leaving out the skip files.
+// With paths, the commit only takes what they match, so the skip files are
+// excluded there; otherwise the index is committed and they are unstaged.
+func stageChanges(skip []string) error {
+	if len(skip) > 0 && len(git.Paths) > 0 {
+		for _, path := range skip {
+			git.Paths = append(git.Paths, ":(top,literal,exclude)"+path)
+		}
+	}
+	if err := git.StageAll(runCtx); err != nil {
+		return err
+	}
+	if len(skip) > 0 && len(git.Paths) == 0 {
+		return git.UnstagePaths(runCtx, skip)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quaywin/claude-commit/internal/claude"
+	"github.com/quaywin/claude-commit/internal/config"
+	"github.com/quaywin/claude-commit/internal/git"
+)
+
+// wipTrailer marks the commits of `cc wip`, so `cc squash-wip` can find them
+const wipTrailer = "Cc-Wip: true"
+
+// isWIP reports whether a commit message carries wipTrailer or, for
+// checkpoints made by hand, starts with "wip"
+func isWIP(message string) bool {
+	subject, _, _ := strings.Cut(message, "\n")
+	subject = strings.ToLower(subject)
+	if strings.HasPrefix(subject, "wip:") || strings.HasPrefix(subject, "wip ") || subject == "wip" {
+		return true
+	}
+	for _, line := range strings.Split(message, "\n") {
+		if strings.TrimSpace(line) == wipTrailer {
+			return true
+		}
+	}
+	return false
+}
+
+// handleWIP commits everything as a quick checkpoint: no review, a one-line
+// description from the cheap model based on the stat summary only, and no push
+func handleWIP(cfg *config.Config) error {
+	logln("🔍 Checking for changes...")
+	aborted, err := checkRepoState(true)
+	if err != nil {
+		return err
+	}
+	if aborted {
+		summaryf("❌ Aborted. No changes were committed.\n")
+		exit(exitAborted)
+	}
+
+	files, err := git.GetChangedFiles(runCtx)
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("getting changed files: %w", err))
+	}
+	if len(files) == 0 {
+		summaryf("✅ No changes to commit.\n")
+		exit(exitNoChanges)
+	}
+
+	suspects, err := findSuspectFiles(cfg, files)
+	if err != nil {
+		return err
+	}
+	if len(suspects) > 0 {
+		ok, err := confirmSuspectFiles(suspects)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			summaryf("❌ Aborted. No changes were committed.\n")
+			exit(exitAborted)
+		}
+	}
+
+	summary, err := git.GetDiffSummary(runCtx)
+	if err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("getting git diff summary: %w", err))
+	}
+
+	model := cfg.WIPModel
+	if model == "" {
+		model = config.DefaultModel
+	}
+	stopSpinner := startSpinner("🤖 Describing your work in progress", fmt.Sprintf(" (%d files)", len(files)))
+	description, err := claude.WIPDescription(runCtx, summary, model)
+	stopSpinner()
+	if err != nil {
+		// A checkpoint is worth more than its description
+		eprintf("⚠️  Warning: Could not describe the changes: %v\n", err)
+		description = fmt.Sprintf("%d changed files", len(files))
+	}
+	message := "wip: " + description + "\n\n" + wipTrailer
+
+	indexTree := indexBeforeStaging()
+	if err := git.StageAll(runCtx); err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("staging changes: %w", err))
+	}
+	if err := git.Commit(runCtx, message, cfg.SignOff); err != nil {
+		return withExitCode(exitGitError, fmt.Errorf("committing: %w", err))
+	}
+	recordCommit(indexTree)
+	sha, _ := git.GetHeadSHA(runCtx)
+	summaryf("📍 %s wip: %s (not pushed; combine with 'cc squash-wip')\n", shortSHA(sha), description)
+	return nil
+}